@@ -21,17 +21,156 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// BackendCertAPI is the name of the signer backend which signs certificates using the Cert API service.
+	BackendCertAPI = "certapi"
+
+	// BackendLocalCA is the name of the signer backend which signs certificates using a CA key pair
+	// stored in the AuthSecret, without any external service.
+	BackendLocalCA = "localca"
+)
+
+const (
+	// RevocationPolicyNever disables the revocation of certificates.
+	RevocationPolicyNever = "Never"
+
+	// RevocationPolicyAnnotated revokes the certificates of Certificates which have the
+	// "cert.dana.io/revoke" annotation set to "true".
+	RevocationPolicyAnnotated = "Annotated"
+
+	// RevocationPolicyAlways revokes the certificates of all Certificates of the Issuer.
+	RevocationPolicyAlways = "Always"
+)
+
+const (
+	// RequestEncodingMultipart uploads the CSR as a file in a multipart form.
+	RequestEncodingMultipart = "multipart"
+
+	// RequestEncodingJSON sends the CSR as a string field of a JSON object.
+	RequestEncodingJSON = "json"
+
+	// RequestEncodingPEM sends the PEM encoded CSR as a raw "application/pkcs10" body.
+	RequestEncodingPEM = "pem"
+)
+
+const (
+	// CertificateEncodingBase64PEM is a base64 encoded PEM certificate chain.
+	CertificateEncodingBase64PEM = "base64PEM"
+
+	// CertificateEncodingPEM is a PEM certificate chain.
+	CertificateEncodingPEM = "pem"
+
+	// CertificateEncodingBase64DER is a base64 encoded DER certificate, or a concatenation of
+	// DER certificates forming a chain.
+	CertificateEncodingBase64DER = "base64DER"
+)
+
+const (
+	// AuthTypeBearer authenticates with the "token" key of the AuthSecret as a bearer token.
+	AuthTypeBearer = "Bearer"
+
+	// AuthTypeBasic authenticates with the "username" and "password" keys of the AuthSecret,
+	// as found in "kubernetes.io/basic-auth" Secrets, using HTTP basic auth.
+	AuthTypeBasic = "Basic"
+
+	// AuthTypeAPIKey authenticates with the "apiKey" key of the AuthSecret in a custom header.
+	AuthTypeAPIKey = "APIKey"
+
+	// AuthTypeOAuth2 authenticates with bearer tokens obtained by the OAuth2 client credentials
+	// flow, using the "clientID" and "clientSecret" keys of the AuthSecret.
+	AuthTypeOAuth2 = "OAuth2"
+)
+
+const (
+	// TLSVersion12 is TLS version 1.2.
+	TLSVersion12 = "1.2"
+
+	// TLSVersion13 is TLS version 1.3.
+	TLSVersion13 = "1.3"
+)
+
+const (
+	// DomainMatchApexAndSubdomains matches an allowed domain and its subdomains.
+	DomainMatchApexAndSubdomains = "ApexAndSubdomains"
+
+	// DomainMatchSubdomainsOnly matches the subdomains of an allowed domain, but not the domain itself.
+	DomainMatchSubdomainsOnly = "SubdomainsOnly"
+
+	// DomainMatchApexOnly matches an allowed domain, but none of its subdomains.
+	DomainMatchApexOnly = "ApexOnly"
+)
+
+const (
+	// PatternSyntaxGlob is the syntax of patterns in which "*" matches any sequence of characters
+	// and "?" matches any single character.
+	PatternSyntaxGlob = "Glob"
+
+	// PatternSyntaxRegex is the syntax of RE2 regular expression patterns, which must match the whole value.
+	PatternSyntaxRegex = "Regex"
+)
+
 // IssuerSpec defines the desired state of Issuer.
 type IssuerSpec struct {
+	// Backend is the name of the signer backend which signs the certificates of the Issuer.
+	// The localca backend signs using the CA certificate and key in the "tls.crt" and "tls.key"
+	// keys of the AuthSecret.
+	// +kubebuilder:default:="certapi"
+	// +optional
+	Backend string `json:"backend,omitempty"`
+
 	// APIEndpoint is the base URL for the endpoint of the Cert API service.
-	APIEndpoint string `json:"apiEndpoint"`
+	// +optional
+	APIEndpoint string `json:"apiEndpoint,omitempty"`
 
 	// APIEndpoint is the download URL for the endpoint of the Cert API service.
-	DownloadEndpoint string `json:"downloadEndpoint"`
+	// +optional
+	DownloadEndpoint string `json:"downloadEndpoint,omitempty"`
+
+	// FailoverEndpoints are further endpoints of the Cert API service, such as in other regions.
+	// CSRs are posted to the APIEndpoint and then to the FailoverEndpoints in order, skipping endpoints
+	// which failed their last health check, until an endpoint accepts them. A task is always polled on
+	// the endpoint which accepted it.
+	// +optional
+	FailoverEndpoints []Endpoint `json:"failoverEndpoints,omitempty"`
+
+	// HealthCheckEndpoint is the path, relative to the APIEndpoint, which is probed with an
+	// authenticated GET request to check the health of the Cert API service.
+	// If unset, the APIEndpoint itself is probed and any response other than Unauthorized, Forbidden
+	// and 5xx, such as Not Found or Method Not Allowed, is considered healthy.
+	// +optional
+	HealthCheckEndpoint string `json:"healthCheckEndpoint,omitempty"`
+
+	// RequestProfile specifies how CSRs are encoded in the requests posted to the Cert API service.
+	// +optional
+	RequestProfile RequestProfile `json:"requestProfile,omitempty"`
+
+	// ResponseMapping specifies where the task ID and the certificate are found in the responses
+	// of the Cert API service, and how the certificate is encoded.
+	// +optional
+	ResponseMapping ResponseMapping `json:"responseMapping,omitempty"`
+
+	// MaxTaskAge is the time for which a signing task is polled after it was submitted. A CertificateRequest
+	// whose task is not issued within the MaxTaskAge is marked as Failed, so that cert-manager retries it with
+	// a new CertificateRequest. Defaults to 24h.
+	// +optional
+	MaxTaskAge *metav1.Duration `json:"maxTaskAge,omitempty"`
+
+	// RevokeEndpoint is the path, relative to the APIEndpoint, to which requests to revoke
+	// certificates are posted.
+	// +optional
+	RevokeEndpoint string `json:"revokeEndpoint,omitempty"`
+
+	// RevocationPolicy specifies which certificates are revoked when they are superseded by a
+	// certificate for a new private key, or when their Certificate is deleted.
+	// +kubebuilder:default:="Never"
+	// +kubebuilder:validation:Enum=Never;Annotated;Always
+	// +optional
+	RevocationPolicy string `json:"revocationPolicy,omitempty"`
 
 	// Form is the format of the Certificate that is downloaded from the Cert API service.
+	// The pkcs12 form is decrypted using the password in the "pkcs12Password" key of the AuthSecret.
 	// +kubebuilder:default:="chain"
-	// +kubebuilder:validation:Enum=chain;public
+	// +kubebuilder:validation:Enum=chain;public;pkcs12
 	Form string `json:"form,omitempty"`
 
 	// AuthSecretName is a reference to a Secret in the same namespace as the referent. If the
@@ -41,18 +180,188 @@ type IssuerSpec struct {
 	// namespace that the controller runs in).
 	AuthSecretName string `json:"authSecretName"`
 
+	// Auth specifies how requests to the Cert API service are authenticated using the
+	// credentials in the AuthSecret. Defaults to a bearer token.
+	// +optional
+	Auth Auth `json:"auth,omitempty"`
+
 	// HTTPConfig specifies configuration relating to the HTTP client used to interact
 	// with the cert API.
-	HTTPConfig HTTPConfig `json:"httpConfig"`
+	// +optional
+	HTTPConfig HTTPConfig `json:"httpConfig,omitempty"`
 
 	// CertificateRestrictions is a set of restrictions for a Certificate imposed by the Issuer.
 	CertificateRestrictions Restrictions `json:"certificateRestrictions,omitempty"`
 }
 
+// Endpoint is an endpoint of the Cert API service.
+type Endpoint struct {
+	// APIEndpoint is the base URL of the endpoint.
+	APIEndpoint string `json:"apiEndpoint"`
+
+	// DownloadEndpoint is the download URL of the endpoint. Defaults to the DownloadEndpoint of the issuer.
+	// +optional
+	DownloadEndpoint string `json:"downloadEndpoint,omitempty"`
+}
+
+// RequestProfile specifies how CSRs are encoded in the requests posted to the Cert API service.
+type RequestProfile struct {
+	// Encoding is the encoding of the CSR in the POST request.
+	// +kubebuilder:default:="multipart"
+	// +kubebuilder:validation:Enum=multipart;json;pem
+	// +optional
+	Encoding string `json:"encoding,omitempty"`
+
+	// Path is the path, relative to the APIEndpoint, to which CSRs are posted.
+	// Defaults to "csr".
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// FieldName is the name of the multipart form field or JSON field which holds the CSR.
+	// Defaults to "file" for the multipart encoding and to "csr" for the json encoding.
+	// +optional
+	FieldName string `json:"fieldName,omitempty"`
+
+	// FileName is the file name of the CSR in the multipart form. Defaults to "csr.pem".
+	// +optional
+	FileName string `json:"fileName,omitempty"`
+
+	// Parameters are static parameters which are sent along with the CSR. They are added as
+	// form fields for the multipart encoding, as JSON fields for the json encoding, and as
+	// query parameters for the pem encoding.
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+
+	// DurationParameter is the name of the parameter in which the duration requested by a
+	// CertificateRequest is sent along with the CSR, in whole seconds. The requested duration
+	// is not sent to the Cert API if no DurationParameter is given.
+	// +optional
+	DurationParameter string `json:"durationParameter,omitempty"`
+
+	// Attributes are parameters which are sent along with the CSR to record which CertificateRequest
+	// it was submitted for. Their values are Go templates, which can refer to the .ClusterID of the
+	// controller and to the .Namespace, .Name, .Username and .Annotations of the CertificateRequest,
+	// such as "{{ .Namespace }}/{{ .Name }}" or "{{ index .Annotations "cert-manager.io/certificate-name" }}".
+	// Attributes override the Parameters of the same name, and are not sent if they render empty.
+	// +optional
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// ResponseMapping specifies where the task ID and the certificate are found in the responses
+// of the Cert API service, and how the certificate is encoded.
+type ResponseMapping struct {
+	// TaskIDPath is the dot-separated JSON path of the task ID in the response to a posted CSR,
+	// such as "result.id". Defaults to "taskId".
+	// +optional
+	TaskIDPath string `json:"taskIDPath,omitempty"`
+
+	// CertificatePath is the dot-separated JSON path of the certificate in the download response,
+	// such as "result.certificates.0". Defaults to "data".
+	// +optional
+	CertificatePath string `json:"certificatePath,omitempty"`
+
+	// PollingIntervalPath is the dot-separated JSON path of the interval at which the task should be
+	// polled in the response to a posted CSR, given in seconds or as a duration such as "30s".
+	// Defaults to "pollingInterval". If the response holds no interval, the Retry-After header of the
+	// response is used, and otherwise the RetryBackoff of the HTTPConfig.
+	// +optional
+	PollingIntervalPath string `json:"pollingIntervalPath,omitempty"`
+
+	// CertificateEncoding is the encoding of the certificate in the download response.
+	// It is ignored for the pkcs12 form.
+	// +kubebuilder:default:="base64PEM"
+	// +kubebuilder:validation:Enum=base64PEM;pem;base64DER
+	// +optional
+	CertificateEncoding string `json:"certificateEncoding,omitempty"`
+}
+
+// Auth specifies how requests to the Cert API service are authenticated.
+type Auth struct {
+	// Type is the authentication scheme. Bearer sends the "token" key of the AuthSecret as a bearer
+	// token. Basic uses the "username" and "password" keys of the AuthSecret, as found in
+	// "kubernetes.io/basic-auth" Secrets. APIKey sends the "apiKey" key of the AuthSecret in the
+	// APIKeyHeader. OAuth2 obtains bearer tokens with the client credentials flow, using the
+	// "clientID" and "clientSecret" keys of the AuthSecret.
+	// +kubebuilder:default:="Bearer"
+	// +kubebuilder:validation:Enum=Bearer;Basic;APIKey;OAuth2
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// APIKeyHeader is the name of the header which carries the API key. Defaults to "X-API-Key".
+	// +optional
+	APIKeyHeader string `json:"apiKeyHeader,omitempty"`
+
+	// OAuth2 specifies the configuration of the OAuth2 client credentials flow.
+	// +optional
+	OAuth2 *OAuth2 `json:"oauth2,omitempty"`
+}
+
+// OAuth2 specifies the configuration of the OAuth2 client credentials flow.
+type OAuth2 struct {
+	// TokenURL is the URL of the token endpoint of the authorization server.
+	TokenURL string `json:"tokenURL"`
+
+	// Scopes is a set of scopes which are requested for the token.
+	// +optional
+	Scopes []string `json:"scopes,omitempty"`
+}
+
 type HTTPConfig struct {
 	// SkipVerifyTLS specifies whether to skip TLS verification in HTTP requests.
 	SkipVerifyTLS bool `json:"skipVerifyTLS"`
 
+	// ClientCertificateSecretName is a reference to a "kubernetes.io/tls" Secret whose certificate
+	// and key are presented as a client certificate in HTTP requests. It is looked up in the same
+	// namespace as the AuthSecret.
+	// +optional
+	ClientCertificateSecretName string `json:"clientCertificateSecretName,omitempty"`
+
+	// CABundle is a PEM encoded bundle of CA certificates which are trusted when verifying the
+	// certificate of the Cert API service. If no CA bundle is given, the system trust store is used.
+	// +optional
+	CABundle []byte `json:"caBundle,omitempty"`
+
+	// CABundleSecretRef references a key of a Secret holding a PEM encoded bundle of CA certificates
+	// which are trusted in addition to the CABundle. It is looked up in the same namespace as the AuthSecret.
+	// +optional
+	CABundleSecretRef *KeySelector `json:"caBundleSecretRef,omitempty"`
+
+	// CABundleConfigMapRef references a key of a ConfigMap holding a PEM encoded bundle of CA certificates
+	// which are trusted in addition to the CABundle. It is looked up in the same namespace as the AuthSecret.
+	// +optional
+	CABundleConfigMapRef *KeySelector `json:"caBundleConfigMapRef,omitempty"`
+
+	// MinTLSVersion is the minimum TLS version accepted from the Cert API service. Defaults to 1.2.
+	// +kubebuilder:validation:Enum="1.2";"1.3"
+	// +optional
+	MinTLSVersion string `json:"minTLSVersion,omitempty"`
+
+	// ServerName is the name which the certificate of the Cert API service is verified against,
+	// and which is sent in the TLS handshake. Defaults to the host of the APIEndpoint.
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+
+	// ProxyURL is the URL of the HTTP proxy through which the Cert API service is reached, such as
+	// "http://proxy.example.com:3128". If no proxy URL is given, the HTTPS_PROXY, HTTP_PROXY and
+	// NO_PROXY environment variables of the controller are used.
+	// +optional
+	ProxyURL string `json:"proxyURL,omitempty"`
+
+	// ProxySecretName is the name of a Secret holding the "username" and "password" keys with which
+	// the proxy is authenticated. It is looked up in the same namespace as the AuthSecret.
+	// +optional
+	ProxySecretName string `json:"proxySecretName,omitempty"`
+
+	// RateLimit limits the rate of requests sent to the Cert API service on behalf of the issuer.
+	// If no rate limit is given, requests are not limited.
+	// +optional
+	RateLimit *RateLimit `json:"rateLimit,omitempty"`
+
+	// CircuitBreaker configures the circuit breaker which stops sending requests to the Cert API service
+	// after consecutive transport or 5xx failures. If no circuit breaker is given, the defaults are used.
+	// +optional
+	CircuitBreaker *CircuitBreaker `json:"circuitBreaker,omitempty"`
+
 	// WaitTimeout specifies the maximum time duration for waiting for response in HTTP requests.
 	WaitTimeout *metav1.Duration `json:"waitTimeout,omitempty"`
 
@@ -60,6 +369,42 @@ type HTTPConfig struct {
 	RetryBackoff RetryBackoff `json:"retryBackoff,omitempty"`
 }
 
+// RateLimit limits the rate of requests using a token bucket.
+type RateLimit struct {
+	// QPS is the sustained number of requests per second, such as "10" or "0.5".
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]*)?|\.[0-9]+)$`
+	QPS string `json:"qps"`
+
+	// Burst is the number of requests which may be sent at once before the QPS applies. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Burst int `json:"burst,omitempty"`
+}
+
+// CircuitBreaker configures a circuit breaker.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failed requests after which the circuit breaker
+	// opens and requests fail fast. Defaults to 5.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FailureThreshold int `json:"failureThreshold,omitempty"`
+
+	// OpenDuration is the time for which requests fail fast once the circuit breaker opens, before a
+	// single probe request is sent. Defaults to 30s.
+	// +optional
+	OpenDuration *metav1.Duration `json:"openDuration,omitempty"`
+}
+
+// KeySelector references a key of a Secret or ConfigMap.
+type KeySelector struct {
+	// Name is the name of the Secret or ConfigMap.
+	Name string `json:"name"`
+
+	// Key is the key of the data which is referenced. Defaults to "ca.crt".
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
 // RetryBackoff specifies the retry configuration in HTTP requests.
 // It is the wait.Backoff but with json tags.
 type RetryBackoff struct {
@@ -102,6 +447,10 @@ type Restrictions struct {
 	// SubjectAltNamesRestrictions represents the SubjectAltNames restrictions imposed by the Issuer.
 	// +optional
 	SubjectAltNamesRestrictions SubjectAltNamesRestrictions `json:"subjectAltNamesRestrictions,omitempty"`
+
+	// ValidityRestrictions represents the Validity restrictions imposed by the Issuer.
+	// +optional
+	ValidityRestrictions ValidityRestrictions `json:"validityRestrictions,omitempty"`
 }
 
 // PrivateKeyRestrictions represents the PrivateKey restrictions imposed by the Issuer.
@@ -150,6 +499,54 @@ type SubjectRestrictions struct {
 	// AllowedSerialNumbers is a set of SerialNumbers that can be used on a Certificate and are supported by the Issuer.
 	// +optional
 	AllowedSerialNumbers []string `json:"allowedSerialNumbers,omitempty"`
+
+	// AllowedPatterns are patterns which the subject fields of a Certificate may match
+	// instead of one of their allowed values.
+	// +optional
+	AllowedPatterns SubjectPatterns `json:"allowedPatterns,omitempty"`
+}
+
+// SubjectPatterns represents the patterns which the subject fields of a Certificate may match.
+type SubjectPatterns struct {
+	// Syntax is the syntax of the patterns: Glob, in which "*" matches any sequence of characters
+	// and "?" matches any single character, or Regex, RE2 regular expressions which must match
+	// the whole value.
+	// +kubebuilder:default:="Glob"
+	// +kubebuilder:validation:Enum=Glob;Regex
+	// +optional
+	Syntax string `json:"syntax,omitempty"`
+
+	// Organizations is a set of patterns of the Organizations of a Certificate.
+	// +optional
+	Organizations []string `json:"organizations,omitempty"`
+
+	// Countries is a set of patterns of the Countries of a Certificate.
+	// +optional
+	Countries []string `json:"countries,omitempty"`
+
+	// OrganizationalUnits is a set of patterns of the OrganizationalUnits of a Certificate.
+	// +optional
+	OrganizationalUnits []string `json:"organizationalUnits,omitempty"`
+
+	// Localities is a set of patterns of the Localities of a Certificate.
+	// +optional
+	Localities []string `json:"localities,omitempty"`
+
+	// Provinces is a set of patterns of the Provinces of a Certificate.
+	// +optional
+	Provinces []string `json:"provinces,omitempty"`
+
+	// StreetAddresses is a set of patterns of the StreetAddresses of a Certificate.
+	// +optional
+	StreetAddresses []string `json:"streetAddresses,omitempty"`
+
+	// PostalCodes is a set of patterns of the PostalCodes of a Certificate.
+	// +optional
+	PostalCodes []string `json:"postalCodes,omitempty"`
+
+	// SerialNumbers is a set of patterns of the SerialNumber of a Certificate.
+	// +optional
+	SerialNumbers []string `json:"serialNumbers,omitempty"`
 }
 
 // UsageRestrictions represents the Usage restrictions imposed by the Issuer.
@@ -163,14 +560,25 @@ type UsageRestrictions struct {
 // DomainRestrictions represents the Domain restrictions imposed by the Issuer.
 type DomainRestrictions struct {
 	// AllowedDomains is a set of domains that are used on a Certificate
-	// and are supported by the Issuer.
+	// and are supported by the Issuer. The CommonName and every DNS name of a Certificate
+	// must match one of them, ignoring case and trailing dots. A wildcard domain, such as
+	// "*.apps.example.com", matches the names one label below it.
 	// +optional
 	AllowedDomains []string `json:"allowedDomains,omitempty"`
 
 	// AllowedSubdomains is a set of Subdomains that are used on a Certificate
-	// and are supported by the Issuer.
+	// and are supported by the Issuer. The CommonName and every DNS name of a Certificate
+	// must also match one of them, in the same way as the AllowedDomains.
 	// +optional
 	AllowedSubdomains []string `json:"allowedSubdomains,omitempty"`
+
+	// MatchMode specifies which names the allowed domains and subdomains match: the domain and
+	// its subdomains, only its subdomains, or only the domain itself. Wildcard domains always
+	// match the names one label below them.
+	// +kubebuilder:default:="ApexAndSubdomains"
+	// +kubebuilder:validation:Enum=ApexAndSubdomains;SubdomainsOnly;ApexOnly
+	// +optional
+	MatchMode string `json:"matchMode,omitempty"`
 }
 
 // SubjectAltNamesRestrictions represents the SubjectAltNames restrictions imposed by the Issuer.
@@ -188,6 +596,17 @@ type SubjectAltNamesRestrictions struct {
 	AllowEmailSANs bool `json:"allowAllowedEmailSANs,omitempty"`
 }
 
+// ValidityRestrictions represents the Validity restrictions imposed by the Issuer.
+type ValidityRestrictions struct {
+	// MinDuration is the minimum duration which can be requested for a Certificate.
+	// +optional
+	MinDuration *metav1.Duration `json:"minDuration,omitempty"`
+
+	// MaxDuration is the maximum duration which can be requested for a Certificate.
+	// +optional
+	MaxDuration *metav1.Duration `json:"maxDuration,omitempty"`
+}
+
 // IssuerStatus defines the observed state of Issuer
 type IssuerStatus struct {
 	// List of status conditions to indicate the status of a CertificateRequest.
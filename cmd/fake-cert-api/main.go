@@ -0,0 +1,112 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/dana-team/cert-external-issuer/internal/fakecertapi"
+	ctrl "sigs.k8s.io/controller-runtime"
+	runtimezap "sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+const readHeaderTimeout = 10 * time.Second
+
+var setupLog = ctrl.Log.WithName("fake-cert-api")
+
+var (
+	bindAddress         string
+	basePath            string
+	downloadPath        string
+	token               string
+	delay               time.Duration
+	pendingStatus       int
+	postErrorStatus     int
+	downloadErrorStatus int
+	durationParameter   string
+	pkcs12Password      string
+)
+
+func main() {
+	parseFlags()
+	ctrl.SetLogger(runtimezap.New())
+
+	server, err := fakecertapi.NewServer(
+		fakecertapi.WithBasePath(basePath),
+		fakecertapi.WithDownloadPath(downloadPath),
+		fakecertapi.WithToken(token),
+		fakecertapi.WithDelay(delay),
+		fakecertapi.WithPendingStatus(pendingStatus),
+		fakecertapi.WithErrorInjector(injectError),
+		fakecertapi.WithDurationParameter(durationParameter),
+		fakecertapi.WithPKCS12Password(pkcs12Password),
+	)
+	if err != nil {
+		setupLog.Error(err, "unable to create fake Cert API server")
+		os.Exit(1)
+	}
+
+	caPEM, err := server.CACertificatePEM()
+	if err != nil {
+		setupLog.Error(err, "unable to encode CA certificate")
+		os.Exit(1)
+	}
+	setupLog.Info("generated CA certificate", "ca", string(caPEM))
+
+	httpServer := &http.Server{
+		Addr:              bindAddress,
+		Handler:           server,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	setupLog.Info("starting fake Cert API server", "bind-address", bindAddress, "base-path", basePath, "download-path", downloadPath)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		setupLog.Error(err, "problem running fake Cert API server")
+		os.Exit(1)
+	}
+}
+
+// injectError fails POST and download requests with the status codes given in the flags.
+func injectError(r *http.Request) int {
+	switch r.Method {
+	case http.MethodPost:
+		return postErrorStatus
+	case http.MethodGet:
+		return downloadErrorStatus
+	default:
+		return 0
+	}
+}
+
+func parseFlags() {
+	flag.StringVar(&bindAddress, "bind-address", ":8443", "The address the fake Cert API binds to.")
+	flag.StringVar(&basePath, "base-path", "/", "The path of the apiEndpoint under which the fake Cert API is served.")
+	flag.StringVar(&downloadPath, "download-path", "/download/", "The path of the downloadEndpoint, which follows the task ID in download requests.")
+	flag.StringVar(&token, "token", "", "If set, requests must carry this bearer token.")
+	flag.DurationVar(&delay, "delay", 0, "The time after a CSR is posted until its certificate can be downloaded.")
+	flag.IntVar(&pendingStatus, "pending-status", http.StatusNotFound, "The status code of download requests of certificates which are not yet issued.")
+	flag.IntVar(&postErrorStatus, "post-error-status", 0, "If set, every POST request fails with this status code.")
+	flag.IntVar(&downloadErrorStatus, "download-error-status", 0, "If set, every download request fails with this status code.")
+	flag.StringVar(&durationParameter, "duration-parameter", "", "If set, certificates are issued for the duration in whole seconds in this request parameter.")
+	flag.StringVar(&pkcs12Password, "pkcs12-password", "", "The password of the PKCS#12 archives downloaded in the pkcs12 form.")
+
+	flag.Parse()
+}
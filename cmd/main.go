@@ -19,9 +19,12 @@ package main
 import (
 	"crypto/tls"
 	"flag"
+	"io"
 	"os"
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	httpClient "github.com/dana-team/cert-external-issuer/internal/issuer/clients/http"
+	"github.com/dana-team/cert-external-issuer/internal/issuer/signer"
 	"github.com/dana-team/cert-external-issuer/internal/setup"
 	"github.com/go-logr/zapr"
 	"go.elastic.co/ecszap"
@@ -53,9 +56,12 @@ var (
 	secureMetrics            bool
 	enableHTTP2              bool
 	clusterResourceNamespace string
+	clusterID                string
 	printVersion             bool
 	disableApprovedCheck     bool
 	ecsLogging               bool
+	auditBodyCapture         string
+	auditLogFile             string
 )
 
 func init() {
@@ -83,6 +89,11 @@ func main() {
 
 	ctrl.SetLogger(runtimezap.New())
 
+	if err := setupAuditLog(); err != nil {
+		setupLog.Error(err, "unable to set up the audit log")
+		os.Exit(1)
+	}
+
 	disableHTTP2 := func(c *tls.Config) {
 		setupLog.Info("disabling http/2")
 		c.NextProtos = []string{"http/1.1"}
@@ -116,7 +127,10 @@ func main() {
 	}
 
 	setupLog.Info("setting up reconcilers")
-	if err := setup.Controllers(mgr, clusterResourceNamespace, disableApprovedCheck); err != nil {
+	// additional signer backends can be registered here
+	backends := signer.NewDefaultRegistry()
+
+	if err := setup.Controllers(mgr, clusterResourceNamespace, clusterID, disableApprovedCheck, backends); err != nil {
 		setupLog.Error(err, "unable to successfully set up controllers")
 		os.Exit(1)
 	}
@@ -145,9 +159,33 @@ func parseFlags() {
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false, "Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false, "If set, HTTP/2 will be enabled for the metrics and webhook servers")
 	flag.StringVar(&clusterResourceNamespace, "cluster-resource-namespace", "default", "The namespace for secrets in which cluster-scoped resources are found.")
+	flag.StringVar(&clusterID, "cluster-id", "", "The ID of the cluster, which attribute templates of Issuers can send to the Cert API.")
 	flag.BoolVar(&printVersion, "version", false, "Print version to stdout and exit")
 	flag.BoolVar(&disableApprovedCheck, "disable-approved-check", false, "Disables waiting for CertificateRequests to have an approved condition before signing.")
 	flag.BoolVar(&ecsLogging, "ecs-logging", true, "Display controller logs in ecs format.")
+	flag.StringVar(&auditBodyCapture, "audit-body-capture", httpClient.BodyCaptureHashed, "How the bodies of requests to the Cert API and of their responses are recorded in the audit log: none, hashed or full.")
+	flag.StringVar(&auditLogFile, "audit-log-file", "", "The file to which the audit log of the requests to the Cert API is appended as JSON lines, in addition to the controller logs.")
 
 	flag.Parse()
 }
+
+// setupAuditLog sets up the audit log of the requests to the Cert API, appending it to the
+// audit log file if one is given.
+func setupAuditLog() error {
+	var sink io.Writer
+	if auditLogFile != "" {
+		file, err := os.OpenFile(auditLogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return err
+		}
+		sink = file
+	}
+
+	auditLog, err := httpClient.NewAuditLog(auditBodyCapture, sink)
+	if err != nil {
+		return err
+	}
+	httpClient.SetAuditLog(auditLog)
+
+	return nil
+}
@@ -0,0 +1,399 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmpkgutil "github.com/cert-manager/cert-manager/pkg/util/pki"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	"github.com/dana-team/cert-external-issuer/internal/common"
+	httpClient "github.com/dana-team/cert-external-issuer/internal/issuer/clients/http"
+	certsigner "github.com/dana-team/cert-external-issuer/internal/issuer/signer"
+)
+
+const (
+	// RevokeAnnotation is the annotation which opts a Certificate into revocation when its
+	// Issuer has the Annotated revocation policy.
+	RevokeAnnotation = "cert.dana.io/revoke"
+
+	// IssuedSerialNumberAnnotation is the annotation on a Certificate holding the serial number,
+	// in hexadecimal form, of the certificate which was last issued for it.
+	IssuedSerialNumberAnnotation = "cert.dana.io/issued-serial-number"
+
+	// IssuedPublicKeyAnnotation is the annotation on a Certificate holding the SHA-256 fingerprint, in
+	// hexadecimal form, of the public key of the certificate which was last issued for it.
+	IssuedPublicKeyAnnotation = "cert.dana.io/issued-public-key"
+
+	// UnrevokedSerialNumbersAnnotation is the annotation on a Certificate holding the comma separated serial
+	// numbers, in hexadecimal form, of the superseded certificates whose revocation the Cert API rejected.
+	UnrevokedSerialNumbersAnnotation = "cert.dana.io/unrevoked-serial-numbers"
+
+	// RevocationFinalizer is the finalizer which makes sure that the certificate of a deleted
+	// Certificate is revoked.
+	RevocationFinalizer = "cert.dana.io/revocation"
+
+	eventReasonCertificateReconciler = "CertificateReconciler"
+	defaultIssuerKind                = "Issuer"
+
+	// missingRevokerRequeueInterval is the interval at which a Certificate whose Issuer or Secret
+	// is missing is reconciled, until they are created.
+	missingRevokerRequeueInterval = 5 * time.Minute
+
+	// auditKeyCertificate is the key which correlates exchanges with the Cert API to the Certificate.
+	auditKeyCertificate = "certificate"
+)
+
+var (
+	errGetCertificate   = errors.New("error getting Certificate")
+	errGetRevoker       = errors.New("failed to build the Revoker")
+	errGetIssued        = errors.New("failed to get the issued certificate")
+	errRevoke           = errors.New("failed to revoke certificate")
+	errUnrecognisedKind = errors.New("unrecognised kind")
+)
+
+// CertificateReconciler reconciles a Certificate object, revoking the certificates
+// which are superseded or whose Certificate is deleted.
+type CertificateReconciler struct {
+	client.Client
+	Scheme                   *runtime.Scheme
+	SignerBuilder            certsigner.SignerBuilder
+	SignerCache              *certsigner.Cache
+	ClusterResourceNamespace string
+	recorder                 record.EventRecorder
+}
+
+// +kubebuilder:rbac.yaml:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac.yaml:groups=cert-manager.io,resources=certificates/finalizers,verbs=update
+// +kubebuilder:rbac.yaml:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac.yaml:groups="",resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac.yaml:groups="",resources=events,verbs=create;patch
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CertificateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor(common.EventSource)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&cmapi.Certificate{}).
+		Complete(r)
+}
+
+func (r *CertificateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("Certificate", req.NamespacedName)
+
+	certificate := cmapi.Certificate{}
+	if err := r.Get(ctx, req.NamespacedName, &certificate); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("%w: %v", errGetCertificate, err)
+	}
+
+	if certificate.Spec.IssuerRef.Group != certv1alpha1.GroupVersion.Group {
+		return ctrl.Result{}, nil
+	}
+
+	revoker, err := r.getRevoker(ctx, logger, certificate)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// without the Issuer or its credentials, the certificate can no longer be revoked
+			if !certificate.DeletionTimestamp.IsZero() {
+				return ctrl.Result{}, r.removeFinalizer(ctx, &certificate)
+			}
+
+			logger.Info("Issuer or its Secret not found. Waiting for them to be created.", "error", err.Error())
+			return ctrl.Result{RequeueAfter: missingRevokerRequeueInterval}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("%w: %v", errGetRevoker, err)
+	}
+
+	if revoker == nil {
+		return ctrl.Result{}, r.removeFinalizer(ctx, &certificate)
+	}
+
+	ctx = httpClient.WithAuditKeys(ctx, auditKeyCertificate, req.NamespacedName.String(), common.AuditKeyIssuer, issuerReference(certificate))
+
+	if !certificate.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.revokeDeleted(ctx, logger, &certificate, revoker)
+	}
+
+	if controllerutil.AddFinalizer(&certificate, RevocationFinalizer) {
+		return ctrl.Result{}, r.Update(ctx, &certificate)
+	}
+
+	return ctrl.Result{}, r.revokeSuperseded(ctx, logger, &certificate, revoker)
+}
+
+// getRevoker returns the Revoker of the Issuer of the Certificate, or nil if the
+// certificates of the Certificate should not be revoked.
+func (r *CertificateReconciler) getRevoker(ctx context.Context, logger logr.Logger, certificate cmapi.Certificate) (certsigner.Revoker, error) {
+	issuerInstance, err := r.getIssuer(ctx, certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	issuerSpec, _, err := common.GetIssuerSpecAndStatus(issuerInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	if !revocationEnabled(issuerSpec.RevocationPolicy, certificate) {
+		return nil, nil
+	}
+
+	// without a revoke endpoint, revocations would fail forever and block the deletion of the Certificate
+	if issuerSpec.RevokeEndpoint == "" {
+		logger.Info("Issuer has no revoke endpoint. Ignoring revocation policy.", "revocationPolicy", issuerSpec.RevocationPolicy)
+		return nil, nil
+	}
+
+	secret, err := common.GetSecret(r.Client, ctx, issuerInstance, issuerSpec.AuthSecretName, certificate.Namespace, r.ClusterResourceNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	secretData, resourceVersions, err := common.AddHTTPConfigData(r.Client, ctx, issuerInstance, issuerSpec, secret.Data, certificate.Namespace, r.ClusterResourceNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheKey := certsigner.NewCacheKey(issuerInstance, append([]string{secret.ResourceVersion}, resourceVersions...)...)
+	signer, err := r.SignerCache.Signer(cacheKey, func() (certsigner.Signer, error) {
+		return r.SignerBuilder(issuerSpec, secretData, r.Client)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	revoker, ok := signer.(certsigner.Revoker)
+	if !ok {
+		logger.Info("Signer backend does not support revocation. Ignoring.", "backend", issuerSpec.Backend)
+		return nil, nil
+	}
+
+	return revoker, nil
+}
+
+// issuerReference returns the reference to the Issuer or ClusterIssuer of the Certificate.
+func issuerReference(certificate cmapi.Certificate) string {
+	issuerKind := certificate.Spec.IssuerRef.Kind
+	if issuerKind == "" {
+		issuerKind = defaultIssuerKind
+	}
+
+	return common.IssuerReference(issuerKind, certificate.Namespace, certificate.Spec.IssuerRef.Name)
+}
+
+// getIssuer returns the Issuer or ClusterIssuer referenced by the Certificate.
+func (r *CertificateReconciler) getIssuer(ctx context.Context, certificate cmapi.Certificate) (client.Object, error) {
+	issuerKind := certificate.Spec.IssuerRef.Kind
+	if issuerKind == "" {
+		issuerKind = defaultIssuerKind
+	}
+
+	issuerRO, err := r.Scheme.New(certv1alpha1.GroupVersion.WithKind(issuerKind))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errUnrecognisedKind, err)
+	}
+
+	issuerInstance := issuerRO.(client.Object)
+
+	// create a Namespaced name for Issuer and a non-Namespaced name for ClusterIssuer
+	issuerName := types.NamespacedName{Name: certificate.Spec.IssuerRef.Name}
+	if _, ok := issuerInstance.(*certv1alpha1.Issuer); ok {
+		issuerName.Namespace = certificate.Namespace
+	}
+
+	if err := r.Get(ctx, issuerName, issuerInstance); err != nil {
+		return nil, err
+	}
+
+	return issuerInstance, nil
+}
+
+// revokeSuperseded records the serial number and public key of the certificate which is currently
+// issued for the Certificate, and revokes the previously recorded certificate if it was superseded
+// by a certificate for a new key. Certificates which are renewed for the same key are not revoked,
+// so that the workloads which still use them keep working while the renewed certificate is rolled out.
+func (r *CertificateReconciler) revokeSuperseded(ctx context.Context, logger logr.Logger, certificate *cmapi.Certificate, revoker certsigner.Revoker) error {
+	issued, err := r.getIssuedCertificate(ctx, certificate)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errGetIssued, err)
+	}
+
+	if issued == nil {
+		return nil
+	}
+
+	serialNumber := issued.SerialNumber.Text(16)
+	publicKey := publicKeyFingerprint(issued)
+
+	previousSerialNumber := certificate.GetAnnotations()[IssuedSerialNumberAnnotation]
+	previousPublicKey := certificate.GetAnnotations()[IssuedPublicKeyAnnotation]
+	if serialNumber == previousSerialNumber && publicKey == previousPublicKey {
+		return nil
+	}
+
+	// a certificate whose public key was not recorded is not known to be re-keyed, and is therefore not revoked
+	rekeyed := previousPublicKey != "" && previousPublicKey != publicKey
+	var rejected bool
+	if previousSerialNumber != "" && previousSerialNumber != serialNumber && rekeyed {
+		if rejected, err = r.revoke(ctx, logger, certificate, revoker, previousSerialNumber, "superseded"); err != nil {
+			return err
+		}
+	}
+
+	patch := client.MergeFrom(certificate.DeepCopy())
+	metav1.SetMetaDataAnnotation(&certificate.ObjectMeta, IssuedSerialNumberAnnotation, serialNumber)
+	metav1.SetMetaDataAnnotation(&certificate.ObjectMeta, IssuedPublicKeyAnnotation, publicKey)
+
+	// the superseded certificate is no longer tracked by the annotations above, so that its rejected
+	// revocation is recorded for it to be revoked by other means
+	if rejected {
+		unrevoked := certificate.GetAnnotations()[UnrevokedSerialNumbersAnnotation]
+		metav1.SetMetaDataAnnotation(&certificate.ObjectMeta, UnrevokedSerialNumbersAnnotation, appendSerialNumber(unrevoked, previousSerialNumber))
+	}
+
+	return r.Patch(ctx, certificate, patch)
+}
+
+// revokeDeleted revokes the certificate of a deleted Certificate and removes the finalizer.
+func (r *CertificateReconciler) revokeDeleted(ctx context.Context, logger logr.Logger, certificate *cmapi.Certificate, revoker certsigner.Revoker) error {
+	if !controllerutil.ContainsFinalizer(certificate, RevocationFinalizer) {
+		return nil
+	}
+
+	if err := r.revokeSuperseded(ctx, logger, certificate, revoker); err != nil {
+		return err
+	}
+
+	if serialNumber := certificate.GetAnnotations()[IssuedSerialNumberAnnotation]; serialNumber != "" {
+		if _, err := r.revoke(ctx, logger, certificate, revoker, serialNumber, "deleted"); err != nil {
+			return err
+		}
+	}
+
+	return r.removeFinalizer(ctx, certificate)
+}
+
+// revoke revokes the certificate with the given serial number. For added visibility
+// it also logs a message and emits a Kubernetes Event. A revocation which the Cert API
+// rejected, such as of an unknown or already revoked certificate, is not retried, and
+// is reported through the returned boolean.
+func (r *CertificateReconciler) revoke(ctx context.Context, logger logr.Logger, certificate *cmapi.Certificate, revoker certsigner.Revoker, serialNumber, cause string) (bool, error) {
+	err := revoker.Revoke(ctx, logger, serialNumber)
+	if errors.Is(err, certsigner.ErrRequestRejected) {
+		message := fmt.Sprintf("Revocation of %s certificate with serial number %q was rejected. Not retrying", cause, serialNumber)
+		logger.Error(err, message)
+		r.recorder.Event(certificate, corev1.EventTypeWarning, eventReasonCertificateReconciler, fmt.Sprintf("%s: %v", message, err))
+		return true, nil
+	}
+	if err != nil {
+		message := fmt.Sprintf("Failed to revoke %s certificate with serial number %q", cause, serialNumber)
+		logger.Error(err, message)
+		r.recorder.Event(certificate, corev1.EventTypeWarning, eventReasonCertificateReconciler, fmt.Sprintf("%s: %v", message, err))
+		return false, fmt.Errorf("%w, serial number: %s, reason: %v", errRevoke, serialNumber, err)
+	}
+
+	message := fmt.Sprintf("Revoked %s certificate with serial number %q", cause, serialNumber)
+	logger.Info(message)
+	r.recorder.Event(certificate, corev1.EventTypeNormal, eventReasonCertificateReconciler, message)
+
+	return false, nil
+}
+
+// appendSerialNumber appends the serial number to the comma separated serial numbers, unless it is already listed.
+func appendSerialNumber(serialNumbers, serialNumber string) string {
+	if serialNumbers == "" {
+		return serialNumber
+	}
+
+	for _, listed := range strings.Split(serialNumbers, ",") {
+		if listed == serialNumber {
+			return serialNumbers
+		}
+	}
+
+	return serialNumbers + "," + serialNumber
+}
+
+// getIssuedCertificate returns the certificate in the Secret of the Certificate,
+// or nil if no certificate was issued by this issuer.
+func (r *CertificateReconciler) getIssuedCertificate(ctx context.Context, certificate *cmapi.Certificate) (*x509.Certificate, error) {
+	secret := corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: certificate.Spec.SecretName, Namespace: certificate.Namespace}, &secret); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+
+	if secret.Annotations[cmapi.IssuerGroupAnnotationKey] != certv1alpha1.GroupVersion.Group {
+		return nil, nil
+	}
+
+	certificatePEM := secret.Data[corev1.TLSCertKey]
+	if len(certificatePEM) == 0 {
+		return nil, nil
+	}
+
+	return cmpkgutil.DecodeX509CertificateBytes(certificatePEM)
+}
+
+// publicKeyFingerprint returns the SHA-256 fingerprint, in hexadecimal form, of the public key of the certificate.
+func publicKeyFingerprint(certificate *x509.Certificate) string {
+	fingerprint := sha256.Sum256(certificate.RawSubjectPublicKeyInfo)
+	return hex.EncodeToString(fingerprint[:])
+}
+
+// removeFinalizer removes the revocation finalizer from the Certificate, if it exists.
+func (r *CertificateReconciler) removeFinalizer(ctx context.Context, certificate *cmapi.Certificate) error {
+	if !controllerutil.RemoveFinalizer(certificate, RevocationFinalizer) {
+		return nil
+	}
+
+	return r.Update(ctx, certificate)
+}
+
+// revocationEnabled returns a boolean indicating whether the certificates of the Certificate
+// should be revoked according to the revocation policy of its Issuer.
+func revocationEnabled(revocationPolicy string, certificate cmapi.Certificate) bool {
+	switch revocationPolicy {
+	case certv1alpha1.RevocationPolicyAlways:
+		return true
+	case certv1alpha1.RevocationPolicyAnnotated:
+		return certificate.GetAnnotations()[RevokeAnnotation] == "true"
+	default:
+		return false
+	}
+}
@@ -0,0 +1,489 @@
+package certificate
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/go-logr/logr"
+	logrtesting "github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	"github.com/dana-team/cert-external-issuer/internal/issuer/signer"
+	kube "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	certificateNS         = "ns-1"
+	certificateName       = "certificate-1"
+	certificateSecretName = "certificate-1-tls"
+
+	issuerName        = "issuer-1"
+	issuerKind        = "Issuer"
+	issuerCredentials = issuerName + "-credentials"
+
+	revokeEndpoint = "revoke"
+
+	issuedSerialNumber   = "2a"
+	previousSerialNumber = "1a"
+	previousPublicKey    = "0123456789abcdef"
+)
+
+var errFakeRevoke = errors.New("fake revoke error")
+
+type fakeRevokingSigner struct {
+	errRevoke error
+	revoked   []string
+}
+
+func (o *fakeRevokingSigner) Sign(context.Context, logr.Logger, signer.SignRequest, signer.Task) (signer.SignResult, error) {
+	return signer.SignResult{}, nil
+}
+
+func (o *fakeRevokingSigner) Revoke(_ context.Context, _ logr.Logger, serialNumber string) error {
+	if o.errRevoke != nil {
+		return o.errRevoke
+	}
+	o.revoked = append(o.revoked, serialNumber)
+	return nil
+}
+
+type fakeSigner struct{}
+
+func (o *fakeSigner) Sign(context.Context, logr.Logger, signer.SignRequest, signer.Task) (signer.SignResult, error) {
+	return signer.SignResult{}, nil
+}
+
+type args struct {
+	certificate      *cmapi.Certificate
+	revocationPolicy string
+	revokeEndpoint   string
+	issuedSerial     string
+	signer           signer.Signer
+	missingIssuer    bool
+}
+
+type want struct {
+	error              error
+	revoked            []string
+	finalizer          bool
+	serialNumber       string
+	publicKey          string
+	unrevoked          string
+	certificateDeleted bool
+	requeueAfter       time.Duration
+}
+
+func TestReconcile(t *testing.T) {
+	issuedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	issuedPublicKey := testPublicKeyFingerprint(t, issuedKey)
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldAddFinalizer": {
+			args: args{
+				certificate:      newCertificate(nil, nil),
+				revocationPolicy: certv1alpha1.RevocationPolicyAlways,
+				revokeEndpoint:   revokeEndpoint,
+				issuedSerial:     issuedSerialNumber,
+				signer:           &fakeRevokingSigner{},
+			},
+			want: want{
+				finalizer: true,
+			},
+		},
+		"ShouldRecordIssuedSerialNumber": {
+			args: args{
+				certificate:      newCertificate(nil, []string{RevocationFinalizer}),
+				revocationPolicy: certv1alpha1.RevocationPolicyAlways,
+				revokeEndpoint:   revokeEndpoint,
+				issuedSerial:     issuedSerialNumber,
+				signer:           &fakeRevokingSigner{},
+			},
+			want: want{
+				finalizer:    true,
+				serialNumber: issuedSerialNumber,
+				publicKey:    issuedPublicKey,
+			},
+		},
+		"ShouldRevokeRekeyedCertificate": {
+			args: args{
+				certificate:      newCertificate(previousAnnotations(previousPublicKey), []string{RevocationFinalizer}),
+				revocationPolicy: certv1alpha1.RevocationPolicyAlways,
+				revokeEndpoint:   revokeEndpoint,
+				issuedSerial:     issuedSerialNumber,
+				signer:           &fakeRevokingSigner{},
+			},
+			want: want{
+				revoked:      []string{previousSerialNumber},
+				finalizer:    true,
+				serialNumber: issuedSerialNumber,
+				publicKey:    issuedPublicKey,
+			},
+		},
+		"ShouldNotRevokeCertificateRenewedForSameKey": {
+			args: args{
+				certificate:      newCertificate(previousAnnotations(issuedPublicKey), []string{RevocationFinalizer}),
+				revocationPolicy: certv1alpha1.RevocationPolicyAlways,
+				revokeEndpoint:   revokeEndpoint,
+				issuedSerial:     issuedSerialNumber,
+				signer:           &fakeRevokingSigner{},
+			},
+			want: want{
+				finalizer:    true,
+				serialNumber: issuedSerialNumber,
+				publicKey:    issuedPublicKey,
+			},
+		},
+		"ShouldNotRevokeCertificateWithoutRecordedPublicKey": {
+			args: args{
+				certificate:      newCertificate(map[string]string{IssuedSerialNumberAnnotation: previousSerialNumber}, []string{RevocationFinalizer}),
+				revocationPolicy: certv1alpha1.RevocationPolicyAlways,
+				revokeEndpoint:   revokeEndpoint,
+				issuedSerial:     issuedSerialNumber,
+				signer:           &fakeRevokingSigner{},
+			},
+			want: want{
+				finalizer:    true,
+				serialNumber: issuedSerialNumber,
+				publicKey:    issuedPublicKey,
+			},
+		},
+		"ShouldNotRecordSerialNumberWhenRevocationFails": {
+			args: args{
+				certificate:      newCertificate(previousAnnotations(previousPublicKey), []string{RevocationFinalizer}),
+				revocationPolicy: certv1alpha1.RevocationPolicyAlways,
+				revokeEndpoint:   revokeEndpoint,
+				issuedSerial:     issuedSerialNumber,
+				signer:           &fakeRevokingSigner{errRevoke: errFakeRevoke},
+			},
+			want: want{
+				error:        errRevoke,
+				finalizer:    true,
+				serialNumber: previousSerialNumber,
+				publicKey:    previousPublicKey,
+			},
+		},
+		"ShouldRevokeDeletedCertificate": {
+			args: args{
+				certificate:      newDeletedCertificate(newCertificate(map[string]string{IssuedSerialNumberAnnotation: issuedSerialNumber}, []string{RevocationFinalizer})),
+				revocationPolicy: certv1alpha1.RevocationPolicyAlways,
+				revokeEndpoint:   revokeEndpoint,
+				issuedSerial:     issuedSerialNumber,
+				signer:           &fakeRevokingSigner{},
+			},
+			want: want{
+				revoked:            []string{issuedSerialNumber},
+				certificateDeleted: true,
+			},
+		},
+		"ShouldRemoveFinalizerWhenRevocationIsRejected": {
+			args: args{
+				certificate:      newDeletedCertificate(newCertificate(map[string]string{IssuedSerialNumberAnnotation: issuedSerialNumber}, []string{RevocationFinalizer})),
+				revocationPolicy: certv1alpha1.RevocationPolicyAlways,
+				revokeEndpoint:   revokeEndpoint,
+				issuedSerial:     issuedSerialNumber,
+				signer:           &fakeRevokingSigner{errRevoke: fmt.Errorf("%w: simulated unknown serial number", signer.ErrRequestRejected)},
+			},
+			want: want{
+				certificateDeleted: true,
+			},
+		},
+		"ShouldRecordSerialNumberWhenRevocationIsRejected": {
+			args: args{
+				certificate:      newCertificate(previousAnnotations(previousPublicKey), []string{RevocationFinalizer}),
+				revocationPolicy: certv1alpha1.RevocationPolicyAlways,
+				revokeEndpoint:   revokeEndpoint,
+				issuedSerial:     issuedSerialNumber,
+				signer:           &fakeRevokingSigner{errRevoke: fmt.Errorf("%w: simulated already revoked", signer.ErrRequestRejected)},
+			},
+			want: want{
+				finalizer:    true,
+				serialNumber: issuedSerialNumber,
+				publicKey:    issuedPublicKey,
+				unrevoked:    previousSerialNumber,
+			},
+		},
+		"ShouldAppendToUnrevokedSerialNumbersWhenRevocationIsRejected": {
+			args: args{
+				certificate: newCertificate(map[string]string{
+					IssuedSerialNumberAnnotation:     previousSerialNumber,
+					IssuedPublicKeyAnnotation:        previousPublicKey,
+					UnrevokedSerialNumbersAnnotation: "a",
+				}, []string{RevocationFinalizer}),
+				revocationPolicy: certv1alpha1.RevocationPolicyAlways,
+				revokeEndpoint:   revokeEndpoint,
+				issuedSerial:     issuedSerialNumber,
+				signer:           &fakeRevokingSigner{errRevoke: fmt.Errorf("%w: simulated unknown serial number", signer.ErrRequestRejected)},
+			},
+			want: want{
+				finalizer:    true,
+				serialNumber: issuedSerialNumber,
+				publicKey:    issuedPublicKey,
+				unrevoked:    "a," + previousSerialNumber,
+			},
+		},
+		"ShouldRemoveFinalizerWithoutRevokeEndpoint": {
+			args: args{
+				certificate:      newDeletedCertificate(newCertificate(map[string]string{IssuedSerialNumberAnnotation: issuedSerialNumber}, []string{RevocationFinalizer})),
+				revocationPolicy: certv1alpha1.RevocationPolicyAlways,
+				issuedSerial:     issuedSerialNumber,
+				signer:           &fakeRevokingSigner{},
+			},
+			want: want{
+				certificateDeleted: true,
+			},
+		},
+		"ShouldWaitForMissingIssuer": {
+			args: args{
+				certificate:   newCertificate(previousAnnotations(previousPublicKey), []string{RevocationFinalizer}),
+				issuedSerial:  issuedSerialNumber,
+				signer:        &fakeRevokingSigner{},
+				missingIssuer: true,
+			},
+			want: want{
+				finalizer:    true,
+				serialNumber: previousSerialNumber,
+				publicKey:    previousPublicKey,
+				requeueAfter: missingRevokerRequeueInterval,
+			},
+		},
+		"ShouldRemoveFinalizerOfDeletedCertificateWithMissingIssuer": {
+			args: args{
+				certificate:   newDeletedCertificate(newCertificate(map[string]string{IssuedSerialNumberAnnotation: issuedSerialNumber}, []string{RevocationFinalizer})),
+				issuedSerial:  issuedSerialNumber,
+				signer:        &fakeRevokingSigner{},
+				missingIssuer: true,
+			},
+			want: want{
+				certificateDeleted: true,
+			},
+		},
+		"ShouldNotRevokeWithNeverPolicy": {
+			args: args{
+				certificate:      newCertificate(map[string]string{IssuedSerialNumberAnnotation: previousSerialNumber}, []string{RevocationFinalizer}),
+				revocationPolicy: certv1alpha1.RevocationPolicyNever,
+				revokeEndpoint:   revokeEndpoint,
+				issuedSerial:     issuedSerialNumber,
+				signer:           &fakeRevokingSigner{},
+			},
+			want: want{
+				serialNumber: previousSerialNumber,
+			},
+		},
+		"ShouldNotRevokeUnannotatedCertificateWithAnnotatedPolicy": {
+			args: args{
+				certificate:      newCertificate(nil, nil),
+				revocationPolicy: certv1alpha1.RevocationPolicyAnnotated,
+				revokeEndpoint:   revokeEndpoint,
+				issuedSerial:     issuedSerialNumber,
+				signer:           &fakeRevokingSigner{},
+			},
+		},
+		"ShouldRevokeAnnotatedCertificateWithAnnotatedPolicy": {
+			args: args{
+				certificate: newCertificate(map[string]string{
+					RevokeAnnotation:             "true",
+					IssuedSerialNumberAnnotation: previousSerialNumber,
+					IssuedPublicKeyAnnotation:    previousPublicKey,
+				}, []string{RevocationFinalizer}),
+				revocationPolicy: certv1alpha1.RevocationPolicyAnnotated,
+				revokeEndpoint:   revokeEndpoint,
+				issuedSerial:     issuedSerialNumber,
+				signer:           &fakeRevokingSigner{},
+			},
+			want: want{
+				revoked:      []string{previousSerialNumber},
+				finalizer:    true,
+				serialNumber: issuedSerialNumber,
+				publicKey:    issuedPublicKey,
+			},
+		},
+		"ShouldIgnoreBackendWithoutRevocation": {
+			args: args{
+				certificate:      newCertificate(nil, nil),
+				revocationPolicy: certv1alpha1.RevocationPolicyAlways,
+				revokeEndpoint:   revokeEndpoint,
+				issuedSerial:     issuedSerialNumber,
+				signer:           &fakeSigner{},
+			},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, certv1alpha1.AddToScheme(scheme))
+	assert.NoError(t, cmapi.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fakeClient, controller := setupController(t, scheme, issuedKey, tc.args)
+			name := types.NamespacedName{Namespace: certificateNS, Name: certificateName}
+
+			result, reconcileErr := controller.Reconcile(
+				ctrl.LoggerInto(context.TODO(), logrtesting.New(t)),
+				reconcile.Request{NamespacedName: name},
+			)
+			if tc.want.error != nil {
+				assert.True(t, errors.Is(reconcileErr, tc.want.error), "unexpected error: %v", reconcileErr)
+			} else {
+				assert.NoError(t, reconcileErr)
+			}
+			assert.Equal(t, tc.want.requeueAfter, result.RequeueAfter)
+
+			if revokingSigner, ok := tc.args.signer.(*fakeRevokingSigner); ok {
+				assert.Equal(t, tc.want.revoked, revokingSigner.revoked, "unexpected revoked serial numbers")
+			}
+
+			var certificate cmapi.Certificate
+			err := fakeClient.Get(context.TODO(), name, &certificate)
+			if tc.want.certificateDeleted {
+				assert.True(t, apierrors.IsNotFound(err), "expected Certificate to be deleted, got: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.want.finalizer, len(certificate.Finalizers) > 0, "unexpected finalizers: %v", certificate.Finalizers)
+			assert.Equal(t, tc.want.serialNumber, certificate.Annotations[IssuedSerialNumberAnnotation], "unexpected serial number")
+			assert.Equal(t, tc.want.publicKey, certificate.Annotations[IssuedPublicKeyAnnotation], "unexpected public key")
+			assert.Equal(t, tc.want.unrevoked, certificate.Annotations[UnrevokedSerialNumbersAnnotation], "unexpected unrevoked serial numbers")
+		})
+	}
+}
+
+// setupController sets up the controller with the fake client, whose Secret holds a certificate for the key.
+func setupController(t *testing.T, scheme *runtime.Scheme, key *ecdsa.PrivateKey, args args) (client.Client, CertificateReconciler) {
+	objects := []client.Object{
+		args.certificate,
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: issuerCredentials, Namespace: certificateNS},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        certificateSecretName,
+				Namespace:   certificateNS,
+				Annotations: map[string]string{cmapi.IssuerGroupAnnotationKey: certv1alpha1.GroupVersion.Group},
+			},
+			Data: map[string][]byte{corev1.TLSCertKey: generateTestCertificate(t, key, args.issuedSerial)},
+		},
+	}
+	if !args.missingIssuer {
+		objects = append(objects, &certv1alpha1.Issuer{
+			ObjectMeta: metav1.ObjectMeta{Name: issuerName, Namespace: certificateNS},
+			Spec: certv1alpha1.IssuerSpec{
+				AuthSecretName:   issuerCredentials,
+				RevocationPolicy: args.revocationPolicy,
+				RevokeEndpoint:   args.revokeEndpoint,
+			},
+		})
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		Build()
+
+	controller := CertificateReconciler{
+		Client: fakeClient,
+		Scheme: scheme,
+		SignerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
+			return args.signer, nil
+		},
+		recorder: record.NewFakeRecorder(100),
+	}
+	return fakeClient, controller
+}
+
+// newCertificate returns a Certificate of the Issuer with the given annotations and finalizers.
+func newCertificate(annotations map[string]string, finalizers []string) *cmapi.Certificate {
+	return &cmapi.Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        certificateName,
+			Namespace:   certificateNS,
+			Annotations: annotations,
+			Finalizers:  finalizers,
+		},
+		Spec: cmapi.CertificateSpec{
+			SecretName: certificateSecretName,
+			IssuerRef: cmmeta.ObjectReference{
+				Name:  issuerName,
+				Kind:  issuerKind,
+				Group: certv1alpha1.GroupVersion.Group,
+			},
+		},
+	}
+}
+
+// previousAnnotations returns the annotations of a Certificate for which a certificate with the
+// previous serial number was issued for the given public key.
+func previousAnnotations(publicKey string) map[string]string {
+	return map[string]string{
+		IssuedSerialNumberAnnotation: previousSerialNumber,
+		IssuedPublicKeyAnnotation:    publicKey,
+	}
+}
+
+// newDeletedCertificate marks the Certificate as deleted.
+func newDeletedCertificate(certificate *cmapi.Certificate) *cmapi.Certificate {
+	now := metav1.Now()
+	certificate.DeletionTimestamp = &now
+	return certificate
+}
+
+// generateTestCertificate is a helper to generate a PEM encoded self-signed certificate for the key with the given serial number.
+func generateTestCertificate(t *testing.T, key *ecdsa.PrivateKey, serialNumber string) []byte {
+	t.Helper()
+
+	serial, ok := new(big.Int).SetString(serialNumber, 16)
+	assert.True(t, ok)
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: certificateName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+}
+
+// testPublicKeyFingerprint is a helper to return the SHA-256 fingerprint, in hexadecimal form, of the public key.
+func testPublicKeyFingerprint(t *testing.T, key *ecdsa.PrivateKey) string {
+	t.Helper()
+
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.NoError(t, err)
+
+	fingerprint := sha256.Sum256(publicKeyDER)
+	return hex.EncodeToString(fingerprint[:])
+}
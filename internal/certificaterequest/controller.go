@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	cmutil "github.com/cert-manager/cert-manager/pkg/api/util"
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	cmpki "github.com/cert-manager/cert-manager/pkg/util/pki"
 	"github.com/dana-team/cert-external-issuer/internal/common"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
@@ -16,6 +18,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
@@ -25,11 +28,18 @@ import (
 
 	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
 	"github.com/dana-team/cert-external-issuer/internal/issuer"
+	httpClient "github.com/dana-team/cert-external-issuer/internal/issuer/clients/http"
 	certsigner "github.com/dana-team/cert-external-issuer/internal/issuer/signer"
+	"github.com/dana-team/cert-external-issuer/internal/issuer/validate"
 )
 
 const (
 	eventReasonCertificateRequestReconciler = "CertificateRequestReconciler"
+	eventReasonDurationMismatch             = "DurationMismatch"
+	eventReasonPolicyViolation              = "PolicyViolation"
+
+	// auditKeyCertificateRequest is the key which correlates exchanges with the Cert API to the CertificateRequest.
+	auditKeyCertificateRequest = "certificateRequest"
 )
 
 var (
@@ -38,8 +48,13 @@ var (
 	errUnrecognisedKind      = errors.New("unrecognised kind")
 	errIssuerNotReady        = errors.New("issuer is not ready")
 	errGetAuthSecret         = errors.New("failed to get Secret containing Issuer credentials")
+	errGetHTTPConfigData     = errors.New("failed to get Secret or ConfigMap referenced in Issuer HTTPConfig")
 	errSignerBuilder         = errors.New("failed to build the Signer")
 	errSignerSign            = errors.New("failed to sign")
+	errSetTask               = errors.New("failed to record the signing task")
+	errSubmission            = errors.New("failed to record the submission of the CSR")
+	errSubmissionInterrupted = errors.New("the CSR may have been submitted without its signing task being recorded")
+	errParseCSR              = errors.New("failed to parse the CSR of the CertificateRequest")
 )
 
 // CertificateRequestReconciler reconciles a CertificateRequest object
@@ -47,15 +62,18 @@ type CertificateRequestReconciler struct {
 	client.Client
 	Scheme                   *runtime.Scheme
 	SignerBuilder            certsigner.SignerBuilder
+	SignerCache              *certsigner.Cache
 	Clock                    clock.Clock
 	recorder                 record.EventRecorder
 	CheckApprovedCondition   bool
 	ClusterResourceNamespace string
+	ClusterID                string
 }
 
-// +kubebuilder:rbac.yaml:groups=cert-manager.io,resources=certificaterequests,verbs=get;list;watch
+// +kubebuilder:rbac.yaml:groups=cert-manager.io,resources=certificaterequests,verbs=get;list;watch;patch
 // +kubebuilder:rbac.yaml:groups=cert-manager.io,resources=certificaterequests/status,verbs=get;update;patch
 // +kubebuilder:rbac.yaml:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac.yaml:groups="",resources=configmaps,verbs=get;list;watch
 // +kubebuilder:rbac.yaml:groups="",resources=events,verbs=create;patch
 
 // SetupWithManager sets up the controller with the Manager.
@@ -82,11 +100,16 @@ func (r *CertificateRequestReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, nil
 	}
 
-	// always attempt to update the Ready condition
+	// always attempt to update the Ready condition, unless the status is unchanged, so that
+	// polling a pending task neither rewrites the status nor triggers another reconcile
+	status := certificateRequest.Status.DeepCopy()
 	defer func() {
 		if err != nil {
 			r.report(logger, &certificateRequest, cmapi.CertificateRequestReasonPending, "Error", err)
 		}
+		if apiequality.Semantic.DeepEqual(status, &certificateRequest.Status) {
+			return
+		}
 		if updateErr := r.Status().Update(ctx, &certificateRequest); updateErr != nil {
 			err = utilerrors.NewAggregate([]error{err, updateErr})
 			result = ctrl.Result{}
@@ -117,7 +140,10 @@ func (r *CertificateRequestReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, nil
 	}
 
-	if !issuer.IsReady(issuerStatus) {
+	// an Issuer whose circuit breaker is not closed fails its health checks, but the CertificateRequest
+	// is still passed to the signer, which fails fast while the circuit breaker is open, so that the
+	// CertificateRequest is kept pending until the circuit breaker lets a probe request through
+	if !issuer.IsReady(issuerStatus) && !issuer.IsCircuitBreakerOpen(issuerStatus) {
 		return ctrl.Result{}, errIssuerNotReady
 	}
 
@@ -126,23 +152,163 @@ func (r *CertificateRequestReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, fmt.Errorf("%w, secret name: %s, reason: %v", errGetAuthSecret, issuerSpec.AuthSecretName, err)
 	}
 
-	signer, err := r.SignerBuilder(issuerSpec, secret.Data, r.Client)
+	secretData, resourceVersions, err := common.AddHTTPConfigData(r.Client, ctx, issuerInstance, issuerSpec, secret.Data, certificateRequest.Namespace, r.ClusterResourceNamespace)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("%w: %v", errGetHTTPConfigData, err)
+	}
+
+	cacheKey := certsigner.NewCacheKey(issuerInstance, append([]string{secret.ResourceVersion}, resourceVersions...)...)
+	signer, err := r.SignerCache.Signer(cacheKey, func() (certsigner.Signer, error) {
+		return r.SignerBuilder(issuerSpec, secretData, r.Client)
+	})
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("%w: %v", errSignerBuilder, err)
 	}
 
-	leaf, ca, err := signer.Sign(ctx, logger, certificateRequest.Spec.Request)
+	ctx = httpClient.WithAuditKeys(ctx, auditKeyCertificateRequest, req.NamespacedName.String(), common.AuditKeyIssuer,
+		common.IssuerReference(certificateRequest.Spec.IssuerRef.Kind, issuerInstance.GetNamespace(), issuerInstance.GetName()))
+
+	task := getTask(certificateRequest)
+	if task.ID == "" {
+		if submissionStarted(certificateRequest) {
+			r.markAsFailed(logger, &certificateRequest, "Not submitting the CSR again", errSubmissionInterrupted)
+			return ctrl.Result{}, nil
+		}
+		if err := r.startSubmission(ctx, &certificateRequest); err != nil {
+			return ctrl.Result{}, fmt.Errorf("%w: %v", errSubmission, err)
+		}
+	}
+
+	signResult, err := signer.Sign(ctx, logger, signRequest(certificateRequest, r.ClusterID), task)
+	if task.ID == "" && (err != nil || signResult.Task.ID == "") {
+		// the CSR was not accepted as a task, or was signed without one, so that it can be submitted again
+		if cancelErr := r.cancelSubmission(ctx, &certificateRequest); cancelErr != nil {
+			return ctrl.Result{}, fmt.Errorf("%w: %v", errSubmission, utilerrors.NewAggregate([]error{err, cancelErr}))
+		}
+	}
 	if err != nil {
+		var circuitOpenErr *certsigner.CircuitOpenError
+		if errors.As(err, &circuitOpenErr) {
+			return r.handleCircuitOpen(logger, &certificateRequest, circuitOpenErr)
+		}
+		var retryAfterErr *certsigner.RetryAfterError
+		if errors.As(err, &retryAfterErr) {
+			return r.handleRetryAfter(logger, &certificateRequest, retryAfterErr)
+		}
+		if errors.Is(err, certsigner.ErrPolicyViolation) {
+			r.markAsPolicyViolation(logger, &certificateRequest, err)
+			return ctrl.Result{}, nil
+		}
+		if errors.Is(err, certsigner.ErrRequestRejected) || errors.Is(err, certsigner.ErrUnknownTaskEndpoint) ||
+			errors.Is(err, certsigner.ErrTaskExpired) {
+			r.markAsFailed(logger, &certificateRequest, "Signing failed", err)
+			return ctrl.Result{}, nil
+		}
 		return ctrl.Result{}, fmt.Errorf("%w: %v", errSignerSign, err)
 	}
 
-	certificateRequest.Status.Certificate = leaf
-	certificateRequest.Status.CA = ca
+	if signResult.Pending() {
+		return r.handlePending(logger, ctx, &certificateRequest, task, signResult)
+	}
+
+	if err := verifyIssuedCertificate(certificateRequest, signResult, r.Clock.Now()); err != nil {
+		r.markAsFailed(logger, &certificateRequest, "Issued certificate failed verification", err)
+		return ctrl.Result{}, nil
+	}
+
+	r.checkDuration(logger, &certificateRequest, signResult)
+
+	certificateRequest.Status.Certificate = signResult.Certificate
+	certificateRequest.Status.CA = signResult.CA
 	r.report(logger, &certificateRequest, cmapi.CertificateRequestReasonIssued, "Signed", nil)
 
 	return ctrl.Result{}, nil
 }
 
+// handlePending records a newly submitted signing task on the CertificateRequest and
+// requeues the CertificateRequest so that the task is polled again later.
+func (r *CertificateRequestReconciler) handlePending(logger logr.Logger, ctx context.Context, certificateRequest *cmapi.CertificateRequest, task certsigner.Task, signResult certsigner.SignResult) (ctrl.Result, error) {
+	if signResult.Task.ID != task.ID {
+		if err := r.setTask(ctx, certificateRequest, signResult.Task); err != nil {
+			return ctrl.Result{}, fmt.Errorf("%w, task ID: %s, reason: %v", errSetTask, signResult.Task.ID, err)
+		}
+	}
+
+	// the message is the same while the task is polled, so that the Ready condition is only updated once
+	message := fmt.Sprintf("Submitted for signing with task %q. Waiting for it to be issued", signResult.Task.ID)
+	r.report(logger, certificateRequest, cmapi.CertificateRequestReasonPending, message, nil)
+	return ctrl.Result{RequeueAfter: signResult.RequeueAfter}, nil
+}
+
+// handleCircuitOpen keeps the CertificateRequest pending while the circuit breaker of the Cert API
+// is open, and requeues it once the circuit breaker lets a probe request through, instead of
+// retrying with the rate limited backoff of failed reconciles.
+func (r *CertificateRequestReconciler) handleCircuitOpen(logger logr.Logger, certificateRequest *cmapi.CertificateRequest, circuitOpenErr *certsigner.CircuitOpenError) (ctrl.Result, error) {
+	message := fmt.Sprintf("Waiting for the Cert API to recover: %v", circuitOpenErr)
+	r.report(logger, certificateRequest, cmapi.CertificateRequestReasonPending, message, nil)
+	return ctrl.Result{RequeueAfter: circuitOpenErr.RetryAfter}, nil
+}
+
+// handleRetryAfter keeps the CertificateRequest pending when the Cert API failed a request with a
+// Retry-After header, and requeues it after the delay which the Cert API asked for, so that the
+// controller cooperates with the load shedding of the Cert API.
+func (r *CertificateRequestReconciler) handleRetryAfter(logger logr.Logger, certificateRequest *cmapi.CertificateRequest, retryAfterErr *certsigner.RetryAfterError) (ctrl.Result, error) {
+	message := fmt.Sprintf("Waiting for the Cert API to accept requests: %v", retryAfterErr)
+	r.report(logger, certificateRequest, cmapi.CertificateRequestReasonPending, message, nil)
+	return ctrl.Result{RequeueAfter: retryAfterErr.RetryAfter}, nil
+}
+
+// signRequest returns the request to sign the CSR of the CertificateRequest for its requested duration,
+// described by the metadata of the CertificateRequest and the ID of the cluster.
+func signRequest(certificateRequest cmapi.CertificateRequest, clusterID string) certsigner.SignRequest {
+	request := certsigner.SignRequest{
+		CSR: certificateRequest.Spec.Request,
+		Metadata: certsigner.RequestMetadata{
+			ClusterID:   clusterID,
+			Namespace:   certificateRequest.Namespace,
+			Name:        certificateRequest.Name,
+			Username:    certificateRequest.Spec.Username,
+			Annotations: certificateRequest.Annotations,
+		},
+	}
+	if certificateRequest.Spec.Duration != nil {
+		request.Duration = certificateRequest.Spec.Duration.Duration
+	}
+
+	return request
+}
+
+// checkDuration emits a Warning event if the certificate issued by the signer does not expire after the
+// duration requested by the CertificateRequest. The certificate is still stored, as the signer backend
+// may cap the duration according to its own policy. Certificates of signer backends which were not asked
+// for the duration are not checked, as their duration is left to the signer backend.
+func (r *CertificateRequestReconciler) checkDuration(logger logr.Logger, certificateRequest *cmapi.CertificateRequest, signResult certsigner.SignResult) {
+	if certificateRequest.Spec.Duration == nil || !signResult.DurationRequested {
+		return
+	}
+
+	err := validate.EnsureNotAfter(signResult.Certificate, certificateRequest.Spec.Duration.Duration, certificateRequest.CreationTimestamp.Time, r.Clock.Now())
+	if err == nil {
+		return
+	}
+
+	message := fmt.Sprintf("Issued certificate does not match the requested duration: %v", err)
+	logger.Info(message)
+	r.recorder.Event(certificateRequest, corev1.EventTypeWarning, eventReasonDurationMismatch, message)
+}
+
+// verifyIssuedCertificate makes sure that the certificate issued by the signer matches the CSR of the
+// CertificateRequest and builds up to the returned CA, so that a wrong certificate is never handed
+// to workloads.
+func verifyIssuedCertificate(certificateRequest cmapi.CertificateRequest, signResult certsigner.SignResult, now time.Time) error {
+	csr, err := cmpki.DecodeX509CertificateRequestBytes(certificateRequest.Spec.Request)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errParseCSR, err)
+	}
+
+	return validate.EnsureCertificate(csr, signResult.Certificate, signResult.CA, now)
+}
+
 // ignore returns a boolean indicating whether reconciliation should be skipped.
 func (r *CertificateRequestReconciler) ignore(logger logr.Logger, certificateRequest cmapi.CertificateRequest) bool {
 	if !issuerRefMatchesGroup(certificateRequest) {
@@ -176,21 +342,34 @@ func (r *CertificateRequestReconciler) ignore(logger logr.Logger, certificateReq
 }
 
 // report gives feedback by updating the Ready Condition of the Certificate Request.
-// For added visibility it also logs a message and emits a Kubernetes Event.
+// For added visibility it also logs a message and emits a Kubernetes Event. A Ready condition
+// which already holds the feedback is left as is, so that a CertificateRequest which is polled
+// or retried does not emit an Event on every reconcile.
 func (r *CertificateRequestReconciler) report(logger logr.Logger, certificateRequest *cmapi.CertificateRequest, reason, message string, err error) {
 	status := cmmeta.ConditionFalse
 	if reason == cmapi.CertificateRequestReasonIssued {
 		status = cmmeta.ConditionTrue
 	}
 
+	conditionMessage := message
+	if err != nil {
+		conditionMessage = fmt.Sprintf("%s: %v", message, err)
+	}
+
+	ready := cmutil.GetCertificateRequestCondition(certificateRequest, cmapi.CertificateRequestConditionReady)
+	if ready != nil && ready.Status == status && ready.Reason == reason && ready.Message == conditionMessage {
+		logger.V(1).Info("Ready condition is unchanged", "reason", reason, "message", conditionMessage)
+		return
+	}
+
 	eventType := corev1.EventTypeNormal
 	if err != nil {
 		logger.Error(err, message)
 		eventType = corev1.EventTypeWarning
-		message = fmt.Sprintf("%s: %v", message, err)
 	} else {
 		logger.Info(message)
 	}
+	message = conditionMessage
 
 	r.recorder.Event(certificateRequest, eventType, eventReasonCertificateRequestReconciler, message)
 	cmutil.SetCertificateRequestCondition(certificateRequest, cmapi.CertificateRequestConditionReady, status, reason, message)
@@ -208,6 +387,26 @@ func (r *CertificateRequestReconciler) markAsDenied(logger logr.Logger, certific
 	r.report(logger, &certificateRequest, cmapi.CertificateRequestReasonDenied, message, nil)
 }
 
+// markAsFailed marks the certificateRequest as Failed by setting Ready=Failed and
+// setting FailureTime, so that a request rejected by the Cert API, a task which can no
+// longer be polled or has expired, or a certificate which does not match the request, is not retried.
+func (r *CertificateRequestReconciler) markAsFailed(logger logr.Logger, certificateRequest *cmapi.CertificateRequest, message string, err error) {
+	if certificateRequest.Status.FailureTime == nil {
+		nowTime := metav1.NewTime(r.Clock.Now())
+		certificateRequest.Status.FailureTime = &nowTime
+	}
+
+	r.report(logger, certificateRequest, cmapi.CertificateRequestReasonFailed, message, err)
+}
+
+// markAsPolicyViolation marks the certificateRequest as Failed when it violates the restrictions of the
+// Issuer, such as a duration outside of its validity restrictions, which retrying cannot satisfy, and emits
+// a PolicyViolation Warning event.
+func (r *CertificateRequestReconciler) markAsPolicyViolation(logger logr.Logger, certificateRequest *cmapi.CertificateRequest, err error) {
+	r.recorder.Event(certificateRequest, corev1.EventTypeWarning, eventReasonPolicyViolation, err.Error())
+	r.markAsFailed(logger, certificateRequest, "Request violates the restrictions of the Issuer", err)
+}
+
 // initializeReadyCondition returns true if it has added a Ready condition if such does not already exist,
 // and false if the Ready condition already exists.
 func (r *CertificateRequestReconciler) initializeReadyCondition(logger logr.Logger, certificateRequest *cmapi.CertificateRequest) bool {
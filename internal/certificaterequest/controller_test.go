@@ -2,8 +2,13 @@ package certificaterequest
 
 import (
 	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"math/big"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,11 +17,13 @@ import (
 	cmutil "github.com/cert-manager/cert-manager/pkg/api/util"
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	cmpki "github.com/cert-manager/cert-manager/pkg/util/pki"
 	cmgen "github.com/cert-manager/cert-manager/test/unit/gen"
 	logrtesting "github.com/go-logr/logr/testr"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -26,9 +33,11 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	"github.com/dana-team/cert-external-issuer/internal/issuer"
 	"github.com/dana-team/cert-external-issuer/internal/issuer/signer"
 	"github.com/go-logr/logr"
 	kube "sigs.k8s.io/controller-runtime/pkg/client"
@@ -49,19 +58,36 @@ const (
 	kubeSystemNS  = "kube-system"
 	foreignIssuer = "foreign-issuer.example.com"
 	foreignKind   = "ForeignKind"
+
+	fakeTaskID       = "fake-task-id"
+	fakeTaskEndpoint = "https://cert-api.example.com/"
+	fakeRequeueAfter = 5 * time.Second
 )
 
 var (
 	fixedClockStart = time.Date(2021, time.January, 1, 1, 0, 0, 0, time.UTC)
 	fixedClock      = clock.NewFakeClock(fixedClockStart)
+
+	errPolicyViolation = fmt.Errorf("%w: the requested duration is longer than the maximum duration allowed by the Issuer", signer.ErrPolicyViolation)
 )
 
 type fakeSigner struct {
-	errSign error
+	errSign           error
+	pendingTask       signer.Task
+	certificate       []byte
+	ca                []byte
+	durationRequested bool
+	submissions       int
 }
 
-func (o *fakeSigner) Sign(context.Context, logr.Logger, []byte) ([]byte, []byte, error) {
-	return []byte("fake signed certificate"), []byte("fake ca"), o.errSign
+func (o *fakeSigner) Sign(_ context.Context, _ logr.Logger, _ signer.SignRequest, task signer.Task) (signer.SignResult, error) {
+	if task.ID == "" {
+		o.submissions++
+	}
+	if o.pendingTask.ID != "" {
+		return signer.SignResult{Task: o.pendingTask, RequeueAfter: fakeRequeueAfter}, o.errSign
+	}
+	return signer.SignResult{Certificate: o.certificate, CA: o.ca, DurationRequested: o.durationRequested}, o.errSign
 }
 
 type args struct {
@@ -71,6 +97,7 @@ type args struct {
 	crObjects                []client.Object
 	signerBuilder            signer.SignerBuilder
 	clusterResourceNamespace string
+	interceptorFuncs         interceptor.Funcs
 }
 type want struct {
 	result               ctrl.Result
@@ -79,10 +106,15 @@ type want struct {
 	readyConditionReason string
 	failureTime          *metav1.Time
 	certificate          []byte
+	taskID               string
+	taskEndpoint         string
+	events               []string
 }
 
 func TestReconcile(t *testing.T) {
 	nowMetaTime := metav1.NewTime(fixedClockStart)
+	issued := generateTestIssuance(t)
+	otherIssued := generateTestIssuance(t)
 
 	cases := map[string]struct {
 		args args
@@ -95,6 +127,7 @@ func TestReconcile(t *testing.T) {
 					cmgen.CertificateRequest(
 						certificateRequestName,
 						cmgen.SetCertificateRequestNamespace(certificateRequestNS),
+						cmgen.SetCertificateRequestCSR(issued.csr),
 						cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
 							Name:  issuerName,
 							Group: certv1alpha1.GroupVersion.Group,
@@ -136,14 +169,258 @@ func TestReconcile(t *testing.T) {
 				},
 				},
 				signerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
-					return &fakeSigner{}, nil
+					return &fakeSigner{certificate: issued.certificate, ca: issued.ca}, nil
+				},
+			},
+			want: want{
+				readyConditionStatus: cmmeta.ConditionTrue,
+				readyConditionReason: cmapi.CertificateRequestReasonIssued,
+				failureTime:          nil,
+				certificate:          issued.certificate,
+			},
+		},
+		"ShouldHandleIssuerWithRequestedDuration": {
+			args: args{
+				name: types.NamespacedName{Namespace: certificateRequestNS, Name: certificateRequestName},
+				crObjects: []client.Object{
+					cmgen.CertificateRequest(
+						certificateRequestName,
+						cmgen.SetCertificateRequestNamespace(certificateRequestNS),
+						cmgen.SetCertificateRequestCSR(issued.csr),
+						cmgen.SetCertificateRequestDuration(&metav1.Duration{Duration: time.Hour}),
+						setCertificateRequestCreationTimestamp(nowMetaTime),
+						cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+							Name:  issuerName,
+							Group: certv1alpha1.GroupVersion.Group,
+							Kind:  issuerKind,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionApproved,
+							Status: cmmeta.ConditionTrue,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionReady,
+							Status: cmmeta.ConditionUnknown,
+						}),
+					),
+				},
+				secretObjects: []client.Object{&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      issuerCredentials,
+						Namespace: certificateRequestNS,
+					},
+				},
+				},
+				issuerObjects: []client.Object{&certv1alpha1.Issuer{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      issuerName,
+						Namespace: certificateRequestNS,
+					},
+					Spec: certv1alpha1.IssuerSpec{
+						AuthSecretName: issuerCredentials,
+					},
+					Status: certv1alpha1.IssuerStatus{
+						Conditions: []metav1.Condition{
+							{
+								Type:   string(cmapi.CertificateRequestConditionReady),
+								Status: metav1.ConditionStatus(cmmeta.ConditionTrue),
+							},
+						},
+					},
+				},
+				},
+				signerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
+					return &fakeSigner{certificate: issued.certificate, ca: issued.ca}, nil
+				},
+			},
+			want: want{
+				readyConditionStatus: cmmeta.ConditionTrue,
+				readyConditionReason: cmapi.CertificateRequestReasonIssued,
+				failureTime:          nil,
+				certificate:          issued.certificate,
+			},
+		},
+		"ShouldWarnWhenCertificateDoesNotMatchRequestedDuration": {
+			args: args{
+				name: types.NamespacedName{Namespace: certificateRequestNS, Name: certificateRequestName},
+				crObjects: []client.Object{
+					cmgen.CertificateRequest(
+						certificateRequestName,
+						cmgen.SetCertificateRequestNamespace(certificateRequestNS),
+						cmgen.SetCertificateRequestCSR(issued.csr),
+						cmgen.SetCertificateRequestDuration(&metav1.Duration{Duration: 24 * time.Hour}),
+						setCertificateRequestCreationTimestamp(nowMetaTime),
+						cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+							Name:  issuerName,
+							Group: certv1alpha1.GroupVersion.Group,
+							Kind:  issuerKind,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionApproved,
+							Status: cmmeta.ConditionTrue,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionReady,
+							Status: cmmeta.ConditionUnknown,
+						}),
+					),
+				},
+				secretObjects: []client.Object{&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      issuerCredentials,
+						Namespace: certificateRequestNS,
+					},
+				},
+				},
+				issuerObjects: []client.Object{&certv1alpha1.Issuer{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      issuerName,
+						Namespace: certificateRequestNS,
+					},
+					Spec: certv1alpha1.IssuerSpec{
+						AuthSecretName: issuerCredentials,
+					},
+					Status: certv1alpha1.IssuerStatus{
+						Conditions: []metav1.Condition{
+							{
+								Type:   string(cmapi.CertificateRequestConditionReady),
+								Status: metav1.ConditionStatus(cmmeta.ConditionTrue),
+							},
+						},
+					},
+				},
+				},
+				signerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
+					return &fakeSigner{certificate: issued.certificate, ca: issued.ca, durationRequested: true}, nil
+				},
+			},
+			want: want{
+				readyConditionStatus: cmmeta.ConditionTrue,
+				readyConditionReason: cmapi.CertificateRequestReasonIssued,
+				failureTime:          nil,
+				certificate:          issued.certificate,
+				events: []string{
+					fmt.Sprintf("%s %s %s", corev1.EventTypeWarning, eventReasonDurationMismatch, "Issued certificate does not match the requested duration: validity validation failed: notAfter of the Certificate does not match the requested duration 24h0m0s: expected between 2021-01-02T00:55:00Z and 2021-01-02T01:05:00Z, got 2021-01-01T02:00:00Z"),
+				},
+			},
+		},
+		"ShouldNotWarnWhenDurationWasNotRequested": {
+			args: args{
+				name: types.NamespacedName{Namespace: certificateRequestNS, Name: certificateRequestName},
+				crObjects: []client.Object{
+					cmgen.CertificateRequest(
+						certificateRequestName,
+						cmgen.SetCertificateRequestNamespace(certificateRequestNS),
+						cmgen.SetCertificateRequestCSR(issued.csr),
+						cmgen.SetCertificateRequestDuration(&metav1.Duration{Duration: 24 * time.Hour}),
+						setCertificateRequestCreationTimestamp(nowMetaTime),
+						cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+							Name:  issuerName,
+							Group: certv1alpha1.GroupVersion.Group,
+							Kind:  issuerKind,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionApproved,
+							Status: cmmeta.ConditionTrue,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionReady,
+							Status: cmmeta.ConditionUnknown,
+						}),
+					),
+				},
+				secretObjects: []client.Object{&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      issuerCredentials,
+						Namespace: certificateRequestNS,
+					},
+				},
+				},
+				issuerObjects: []client.Object{&certv1alpha1.Issuer{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      issuerName,
+						Namespace: certificateRequestNS,
+					},
+					Spec: certv1alpha1.IssuerSpec{
+						AuthSecretName: issuerCredentials,
+					},
+					Status: certv1alpha1.IssuerStatus{
+						Conditions: []metav1.Condition{
+							{
+								Type:   string(cmapi.CertificateRequestConditionReady),
+								Status: metav1.ConditionStatus(cmmeta.ConditionTrue),
+							},
+						},
+					},
+				},
+				},
+				signerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
+					return &fakeSigner{certificate: issued.certificate, ca: issued.ca}, nil
 				},
 			},
 			want: want{
 				readyConditionStatus: cmmeta.ConditionTrue,
 				readyConditionReason: cmapi.CertificateRequestReasonIssued,
 				failureTime:          nil,
-				certificate:          []byte("fake signed certificate"),
+				certificate:          issued.certificate,
+			},
+		},
+		"ShouldFailWhenCertificateDoesNotMatchRequest": {
+			args: args{
+				name: types.NamespacedName{Namespace: certificateRequestNS, Name: certificateRequestName},
+				crObjects: []client.Object{
+					cmgen.CertificateRequest(
+						certificateRequestName,
+						cmgen.SetCertificateRequestNamespace(certificateRequestNS),
+						cmgen.SetCertificateRequestCSR(issued.csr),
+						cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+							Name:  issuerName,
+							Group: certv1alpha1.GroupVersion.Group,
+							Kind:  issuerKind,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionApproved,
+							Status: cmmeta.ConditionTrue,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionReady,
+							Status: cmmeta.ConditionUnknown,
+						}),
+					),
+				},
+				secretObjects: []client.Object{&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      issuerCredentials,
+						Namespace: certificateRequestNS,
+					},
+				},
+				},
+				issuerObjects: []client.Object{&certv1alpha1.Issuer{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      issuerName,
+						Namespace: certificateRequestNS,
+					},
+					Spec: certv1alpha1.IssuerSpec{
+						AuthSecretName: issuerCredentials,
+					},
+					Status: certv1alpha1.IssuerStatus{
+						Conditions: []metav1.Condition{
+							{
+								Type:   string(cmapi.CertificateRequestConditionReady),
+								Status: metav1.ConditionStatus(cmmeta.ConditionTrue),
+							},
+						},
+					},
+				},
+				},
+				signerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
+					return &fakeSigner{certificate: otherIssued.certificate, ca: otherIssued.ca}, nil
+				},
+			},
+			want: want{
+				readyConditionStatus: cmmeta.ConditionFalse,
+				readyConditionReason: cmapi.CertificateRequestReasonFailed,
+				failureTime:          &metav1.Time{Time: fixedClockStart},
 			},
 		},
 		"ShouldHandleClusterIssuer": {
@@ -153,6 +430,7 @@ func TestReconcile(t *testing.T) {
 					cmgen.CertificateRequest(
 						certificateRequestName,
 						cmgen.SetCertificateRequestNamespace(certificateRequestNS),
+						cmgen.SetCertificateRequestCSR(issued.csr),
 						cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
 							Name:  clusterIssuerName,
 							Group: certv1alpha1.GroupVersion.Group,
@@ -194,7 +472,7 @@ func TestReconcile(t *testing.T) {
 				},
 				},
 				signerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
-					return &fakeSigner{}, nil
+					return &fakeSigner{certificate: issued.certificate, ca: issued.ca}, nil
 				},
 				clusterResourceNamespace: kubeSystemNS,
 			},
@@ -202,7 +480,7 @@ func TestReconcile(t *testing.T) {
 				readyConditionStatus: cmmeta.ConditionTrue,
 				readyConditionReason: cmapi.CertificateRequestReasonIssued,
 				failureTime:          nil,
-				certificate:          []byte("fake signed certificate"),
+				certificate:          issued.certificate,
 			},
 		},
 		"ShouldHandleCertificateRequestNotFound": {
@@ -570,7 +848,7 @@ func TestReconcile(t *testing.T) {
 				readyConditionReason: cmapi.CertificateRequestReasonPending,
 			},
 		},
-		"ShouldHandleRequestNotApproved": {
+		"ShouldFailWhenCertAPIRejectsRequest": {
 			args: args{
 				name: types.NamespacedName{Namespace: certificateRequestNS, Name: certificateRequestName},
 				crObjects: []client.Object{
@@ -582,6 +860,10 @@ func TestReconcile(t *testing.T) {
 							Group: certv1alpha1.GroupVersion.Group,
 							Kind:  issuerKind,
 						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionApproved,
+							Status: cmmeta.ConditionTrue,
+						}),
 						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
 							Type:   cmapi.CertificateRequestConditionReady,
 							Status: cmmeta.ConditionUnknown,
@@ -616,20 +898,21 @@ func TestReconcile(t *testing.T) {
 					},
 				},
 				signerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
-					return &fakeSigner{}, nil
+					return &fakeSigner{errSign: fmt.Errorf("%w: simulated bad request", signer.ErrRequestRejected)}, nil
 				},
 			},
 			want: want{
-				failureTime: nil,
-				certificate: nil,
+				readyConditionStatus: cmmeta.ConditionFalse,
+				readyConditionReason: cmapi.CertificateRequestReasonFailed,
+				failureTime:          &metav1.Time{Time: fixedClockStart},
 			},
 		},
-		"ShouldHandleRequestDenied": {
+		"ShouldFailWhenTaskEndpointIsUnknown": {
 			args: args{
 				name: types.NamespacedName{Namespace: certificateRequestNS, Name: certificateRequestName},
 				crObjects: []client.Object{
 					cmgen.CertificateRequest(
-						certificateRequestNS,
+						certificateRequestName,
 						cmgen.SetCertificateRequestNamespace(certificateRequestNS),
 						cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
 							Name:  issuerName,
@@ -637,7 +920,7 @@ func TestReconcile(t *testing.T) {
 							Kind:  issuerKind,
 						}),
 						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
-							Type:   cmapi.CertificateRequestConditionDenied,
+							Type:   cmapi.CertificateRequestConditionApproved,
 							Status: cmmeta.ConditionTrue,
 						}),
 						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
@@ -646,6 +929,14 @@ func TestReconcile(t *testing.T) {
 						}),
 					),
 				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerCredentials,
+							Namespace: certificateRequestNS,
+						},
+					},
+				},
 				issuerObjects: []client.Object{
 					&certv1alpha1.Issuer{
 						ObjectMeta: metav1.ObjectMeta{
@@ -665,13 +956,665 @@ func TestReconcile(t *testing.T) {
 						},
 					},
 				},
-				secretObjects: []client.Object{
-					&corev1.Secret{
-						ObjectMeta: metav1.ObjectMeta{
-							Name:      "issuer1-credentials",
-							Namespace: "ns1",
-						},
-					},
+				signerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
+					return &fakeSigner{errSign: fmt.Errorf("%w: %q", signer.ErrUnknownTaskEndpoint, fakeTaskEndpoint)}, nil
+				},
+			},
+			want: want{
+				readyConditionStatus: cmmeta.ConditionFalse,
+				readyConditionReason: cmapi.CertificateRequestReasonFailed,
+				failureTime:          &metav1.Time{Time: fixedClockStart},
+			},
+		},
+		"ShouldFailWhenTaskExpired": {
+			args: args{
+				name: types.NamespacedName{Namespace: certificateRequestNS, Name: certificateRequestName},
+				crObjects: []client.Object{
+					cmgen.CertificateRequest(
+						certificateRequestName,
+						cmgen.SetCertificateRequestNamespace(certificateRequestNS),
+						cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+							Name:  issuerName,
+							Group: certv1alpha1.GroupVersion.Group,
+							Kind:  issuerKind,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionApproved,
+							Status: cmmeta.ConditionTrue,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionReady,
+							Status: cmmeta.ConditionUnknown,
+						}),
+					),
+				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerCredentials,
+							Namespace: certificateRequestNS,
+						},
+					},
+				},
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerName,
+							Namespace: certificateRequestNS,
+						},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+						},
+						Status: certv1alpha1.IssuerStatus{
+							Conditions: []metav1.Condition{
+								{
+									Type:   string(cmapi.CertificateRequestConditionReady),
+									Status: metav1.ConditionStatus(cmmeta.ConditionTrue),
+								},
+							},
+						},
+					},
+				},
+				signerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
+					return &fakeSigner{errSign: fmt.Errorf("%w: task %q", signer.ErrTaskExpired, fakeTaskID)}, nil
+				},
+			},
+			want: want{
+				readyConditionStatus: cmmeta.ConditionFalse,
+				readyConditionReason: cmapi.CertificateRequestReasonFailed,
+				failureTime:          &metav1.Time{Time: fixedClockStart},
+			},
+		},
+		"ShouldFailWhenRequestViolatesPolicy": {
+			args: args{
+				name: types.NamespacedName{Namespace: certificateRequestNS, Name: certificateRequestName},
+				crObjects: []client.Object{
+					cmgen.CertificateRequest(
+						certificateRequestName,
+						cmgen.SetCertificateRequestNamespace(certificateRequestNS),
+						cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+							Name:  issuerName,
+							Group: certv1alpha1.GroupVersion.Group,
+							Kind:  issuerKind,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionApproved,
+							Status: cmmeta.ConditionTrue,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionReady,
+							Status: cmmeta.ConditionUnknown,
+						}),
+					),
+				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerCredentials,
+							Namespace: certificateRequestNS,
+						},
+					},
+				},
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerName,
+							Namespace: certificateRequestNS,
+						},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+						},
+						Status: certv1alpha1.IssuerStatus{
+							Conditions: []metav1.Condition{
+								{
+									Type:   string(cmapi.CertificateRequestConditionReady),
+									Status: metav1.ConditionStatus(cmmeta.ConditionTrue),
+								},
+							},
+						},
+					},
+				},
+				signerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
+					return &fakeSigner{errSign: errPolicyViolation}, nil
+				},
+			},
+			want: want{
+				readyConditionStatus: cmmeta.ConditionFalse,
+				readyConditionReason: cmapi.CertificateRequestReasonFailed,
+				failureTime:          &metav1.Time{Time: fixedClockStart},
+				events: []string{
+					fmt.Sprintf("%s %s %s", corev1.EventTypeWarning, eventReasonPolicyViolation, errPolicyViolation),
+				},
+			},
+		},
+		"ShouldRequeueWhenCircuitBreakerIsOpen": {
+			args: args{
+				name: types.NamespacedName{Namespace: certificateRequestNS, Name: certificateRequestName},
+				crObjects: []client.Object{
+					cmgen.CertificateRequest(
+						certificateRequestName,
+						cmgen.SetCertificateRequestNamespace(certificateRequestNS),
+						cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+							Name:  issuerName,
+							Group: certv1alpha1.GroupVersion.Group,
+							Kind:  issuerKind,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionApproved,
+							Status: cmmeta.ConditionTrue,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionReady,
+							Status: cmmeta.ConditionUnknown,
+						}),
+					),
+				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerCredentials,
+							Namespace: certificateRequestNS,
+						},
+					},
+				},
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerName,
+							Namespace: certificateRequestNS,
+						},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+						},
+						Status: certv1alpha1.IssuerStatus{
+							Conditions: []metav1.Condition{
+								{
+									Type:   string(cmapi.CertificateRequestConditionReady),
+									Status: metav1.ConditionStatus(cmmeta.ConditionTrue),
+								},
+							},
+						},
+					},
+				},
+				signerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
+					return &fakeSigner{errSign: fmt.Errorf("simulated sign error: %w", &signer.CircuitOpenError{Failures: 5, RetryAfter: fakeRequeueAfter})}, nil
+				},
+			},
+			want: want{
+				result:               ctrl.Result{RequeueAfter: fakeRequeueAfter},
+				readyConditionStatus: cmmeta.ConditionFalse,
+				readyConditionReason: cmapi.CertificateRequestReasonPending,
+			},
+		},
+		"ShouldRequeueWhenCircuitBreakerOfNotReadyIssuerIsOpen": {
+			args: args{
+				name: types.NamespacedName{Namespace: certificateRequestNS, Name: certificateRequestName},
+				crObjects: []client.Object{
+					cmgen.CertificateRequest(
+						certificateRequestName,
+						cmgen.SetCertificateRequestNamespace(certificateRequestNS),
+						cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+							Name:  issuerName,
+							Group: certv1alpha1.GroupVersion.Group,
+							Kind:  issuerKind,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionApproved,
+							Status: cmmeta.ConditionTrue,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionReady,
+							Status: cmmeta.ConditionUnknown,
+						}),
+					),
+				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerCredentials,
+							Namespace: certificateRequestNS,
+						},
+					},
+				},
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerName,
+							Namespace: certificateRequestNS,
+						},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+						},
+						Status: openCircuitBreakerIssuerStatus(),
+					},
+				},
+				signerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
+					return &fakeSigner{errSign: fmt.Errorf("simulated sign error: %w", &signer.CircuitOpenError{Failures: 5, RetryAfter: fakeRequeueAfter})}, nil
+				},
+			},
+			want: want{
+				result:               ctrl.Result{RequeueAfter: fakeRequeueAfter},
+				readyConditionStatus: cmmeta.ConditionFalse,
+				readyConditionReason: cmapi.CertificateRequestReasonPending,
+			},
+		},
+		"ShouldRequeueAfterDelayRequestedByCertAPI": {
+			args: args{
+				name: types.NamespacedName{Namespace: certificateRequestNS, Name: certificateRequestName},
+				crObjects: []client.Object{
+					cmgen.CertificateRequest(
+						certificateRequestName,
+						cmgen.SetCertificateRequestNamespace(certificateRequestNS),
+						cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+							Name:  issuerName,
+							Group: certv1alpha1.GroupVersion.Group,
+							Kind:  issuerKind,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionApproved,
+							Status: cmmeta.ConditionTrue,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionReady,
+							Status: cmmeta.ConditionUnknown,
+						}),
+					),
+				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerCredentials,
+							Namespace: certificateRequestNS,
+						},
+					},
+				},
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerName,
+							Namespace: certificateRequestNS,
+						},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+						},
+						Status: certv1alpha1.IssuerStatus{
+							Conditions: []metav1.Condition{
+								{
+									Type:   string(cmapi.CertificateRequestConditionReady),
+									Status: metav1.ConditionStatus(cmmeta.ConditionTrue),
+								},
+							},
+						},
+					},
+				},
+				signerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
+					return &fakeSigner{errSign: &signer.RetryAfterError{Err: errors.New("simulated too many requests"), RetryAfter: fakeRequeueAfter}}, nil
+				},
+			},
+			want: want{
+				result:               ctrl.Result{RequeueAfter: fakeRequeueAfter},
+				readyConditionStatus: cmmeta.ConditionFalse,
+				readyConditionReason: cmapi.CertificateRequestReasonPending,
+			},
+		},
+		"ShouldRecordPendingTask": {
+			args: args{
+				name: types.NamespacedName{Namespace: certificateRequestNS, Name: certificateRequestName},
+				crObjects: []client.Object{
+					cmgen.CertificateRequest(
+						certificateRequestName,
+						cmgen.SetCertificateRequestNamespace(certificateRequestNS),
+						cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+							Name:  issuerName,
+							Group: certv1alpha1.GroupVersion.Group,
+							Kind:  issuerKind,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionApproved,
+							Status: cmmeta.ConditionTrue,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionReady,
+							Status: cmmeta.ConditionUnknown,
+						}),
+					),
+				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerCredentials,
+							Namespace: certificateRequestNS,
+						},
+					},
+				},
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerName,
+							Namespace: certificateRequestNS,
+						},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+						},
+						Status: certv1alpha1.IssuerStatus{
+							Conditions: []metav1.Condition{
+								{
+									Type:   string(cmapi.CertificateRequestConditionReady),
+									Status: metav1.ConditionStatus(cmmeta.ConditionTrue),
+								},
+							},
+						},
+					},
+				},
+				signerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
+					return &fakeSigner{pendingTask: signer.Task{ID: fakeTaskID, SubmittedAt: fixedClockStart, Endpoint: fakeTaskEndpoint}}, nil
+				},
+			},
+			want: want{
+				result:               ctrl.Result{RequeueAfter: fakeRequeueAfter},
+				readyConditionStatus: cmmeta.ConditionFalse,
+				readyConditionReason: cmapi.CertificateRequestReasonPending,
+				taskID:               fakeTaskID,
+				taskEndpoint:         fakeTaskEndpoint,
+			},
+		},
+		"ShouldIssueRecordedTask": {
+			args: args{
+				name: types.NamespacedName{Namespace: certificateRequestNS, Name: certificateRequestName},
+				crObjects: []client.Object{
+					cmgen.CertificateRequest(
+						certificateRequestName,
+						cmgen.SetCertificateRequestNamespace(certificateRequestNS),
+						cmgen.SetCertificateRequestCSR(issued.csr),
+						cmgen.SetCertificateRequestAnnotations(map[string]string{
+							TaskIDAnnotation:          fakeTaskID,
+							TaskSubmittedAtAnnotation: fixedClockStart.Format(time.RFC3339),
+						}),
+						cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+							Name:  issuerName,
+							Group: certv1alpha1.GroupVersion.Group,
+							Kind:  issuerKind,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionApproved,
+							Status: cmmeta.ConditionTrue,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionReady,
+							Status: cmmeta.ConditionUnknown,
+						}),
+					),
+				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerCredentials,
+							Namespace: certificateRequestNS,
+						},
+					},
+				},
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerName,
+							Namespace: certificateRequestNS,
+						},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+						},
+						Status: certv1alpha1.IssuerStatus{
+							Conditions: []metav1.Condition{
+								{
+									Type:   string(cmapi.CertificateRequestConditionReady),
+									Status: metav1.ConditionStatus(cmmeta.ConditionTrue),
+								},
+							},
+						},
+					},
+				},
+				signerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
+					return &fakeSigner{certificate: issued.certificate, ca: issued.ca}, nil
+				},
+			},
+			want: want{
+				readyConditionStatus: cmmeta.ConditionTrue,
+				readyConditionReason: cmapi.CertificateRequestReasonIssued,
+				certificate:          issued.certificate,
+				taskID:               fakeTaskID,
+			},
+		},
+		"ShouldRequeueRecordedPendingTask": {
+			args: args{
+				name: types.NamespacedName{Namespace: certificateRequestNS, Name: certificateRequestName},
+				crObjects: []client.Object{
+					cmgen.CertificateRequest(
+						certificateRequestName,
+						cmgen.SetCertificateRequestNamespace(certificateRequestNS),
+						cmgen.SetCertificateRequestAnnotations(map[string]string{
+							TaskIDAnnotation:          fakeTaskID,
+							TaskSubmittedAtAnnotation: fixedClockStart.Format(time.RFC3339),
+						}),
+						cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+							Name:  issuerName,
+							Group: certv1alpha1.GroupVersion.Group,
+							Kind:  issuerKind,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionApproved,
+							Status: cmmeta.ConditionTrue,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionReady,
+							Status: cmmeta.ConditionUnknown,
+						}),
+					),
+				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerCredentials,
+							Namespace: certificateRequestNS,
+						},
+					},
+				},
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerName,
+							Namespace: certificateRequestNS,
+						},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+						},
+						Status: certv1alpha1.IssuerStatus{
+							Conditions: []metav1.Condition{
+								{
+									Type:   string(cmapi.CertificateRequestConditionReady),
+									Status: metav1.ConditionStatus(cmmeta.ConditionTrue),
+								},
+							},
+						},
+					},
+				},
+				signerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
+					return &fakeSigner{pendingTask: signer.Task{ID: fakeTaskID, SubmittedAt: fixedClockStart}}, nil
+				},
+			},
+			want: want{
+				result:               ctrl.Result{RequeueAfter: fakeRequeueAfter},
+				readyConditionStatus: cmmeta.ConditionFalse,
+				readyConditionReason: cmapi.CertificateRequestReasonPending,
+				taskID:               fakeTaskID,
+			},
+		},
+		"ShouldNotReportRecordedPendingTaskAgain": {
+			args: args{
+				name: types.NamespacedName{Namespace: certificateRequestNS, Name: certificateRequestName},
+				crObjects: []client.Object{
+					cmgen.CertificateRequest(
+						certificateRequestName,
+						cmgen.SetCertificateRequestNamespace(certificateRequestNS),
+						cmgen.SetCertificateRequestAnnotations(map[string]string{
+							TaskIDAnnotation:          fakeTaskID,
+							TaskSubmittedAtAnnotation: fixedClockStart.Format(time.RFC3339),
+						}),
+						cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+							Name:  issuerName,
+							Group: certv1alpha1.GroupVersion.Group,
+							Kind:  issuerKind,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionApproved,
+							Status: cmmeta.ConditionTrue,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:    cmapi.CertificateRequestConditionReady,
+							Status:  cmmeta.ConditionFalse,
+							Reason:  cmapi.CertificateRequestReasonPending,
+							Message: fmt.Sprintf("Submitted for signing with task %q. Waiting for it to be issued", fakeTaskID),
+						}),
+					),
+				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerCredentials,
+							Namespace: certificateRequestNS,
+						},
+					},
+				},
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerName,
+							Namespace: certificateRequestNS,
+						},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+						},
+						Status: certv1alpha1.IssuerStatus{
+							Conditions: []metav1.Condition{
+								{
+									Type:   string(cmapi.CertificateRequestConditionReady),
+									Status: metav1.ConditionStatus(cmmeta.ConditionTrue),
+								},
+							},
+						},
+					},
+				},
+				signerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
+					return &fakeSigner{pendingTask: signer.Task{ID: fakeTaskID, SubmittedAt: fixedClockStart}}, nil
+				},
+			},
+			want: want{
+				result:               ctrl.Result{RequeueAfter: fakeRequeueAfter},
+				readyConditionStatus: cmmeta.ConditionFalse,
+				readyConditionReason: cmapi.CertificateRequestReasonPending,
+				taskID:               fakeTaskID,
+			},
+		},
+		"ShouldHandleRequestNotApproved": {
+			args: args{
+				name: types.NamespacedName{Namespace: certificateRequestNS, Name: certificateRequestName},
+				crObjects: []client.Object{
+					cmgen.CertificateRequest(
+						certificateRequestName,
+						cmgen.SetCertificateRequestNamespace(certificateRequestNS),
+						cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+							Name:  issuerName,
+							Group: certv1alpha1.GroupVersion.Group,
+							Kind:  issuerKind,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionReady,
+							Status: cmmeta.ConditionUnknown,
+						}),
+					),
+				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerCredentials,
+							Namespace: certificateRequestNS,
+						},
+					},
+				},
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerName,
+							Namespace: certificateRequestNS,
+						},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+						},
+						Status: certv1alpha1.IssuerStatus{
+							Conditions: []metav1.Condition{
+								{
+									Type:   string(cmapi.CertificateRequestConditionReady),
+									Status: metav1.ConditionStatus(cmmeta.ConditionTrue),
+								},
+							},
+						},
+					},
+				},
+				signerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
+					return &fakeSigner{}, nil
+				},
+			},
+			want: want{
+				failureTime: nil,
+				certificate: nil,
+			},
+		},
+		"ShouldHandleRequestDenied": {
+			args: args{
+				name: types.NamespacedName{Namespace: certificateRequestNS, Name: certificateRequestName},
+				crObjects: []client.Object{
+					cmgen.CertificateRequest(
+						certificateRequestNS,
+						cmgen.SetCertificateRequestNamespace(certificateRequestNS),
+						cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+							Name:  issuerName,
+							Group: certv1alpha1.GroupVersion.Group,
+							Kind:  issuerKind,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionDenied,
+							Status: cmmeta.ConditionTrue,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionReady,
+							Status: cmmeta.ConditionUnknown,
+						}),
+					),
+				},
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerName,
+							Namespace: certificateRequestNS,
+						},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+						},
+						Status: certv1alpha1.IssuerStatus{
+							Conditions: []metav1.Condition{
+								{
+									Type:   string(cmapi.CertificateRequestConditionReady),
+									Status: metav1.ConditionStatus(cmmeta.ConditionTrue),
+								},
+							},
+						},
+					},
+				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "issuer1-credentials",
+							Namespace: "ns1",
+						},
+					},
 				},
 				signerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
 					return &fakeSigner{}, nil
@@ -716,10 +1659,204 @@ func TestReconcile(t *testing.T) {
 			}
 
 			verifyCertificate(t, tc.want.certificate, crAfter.Status.Certificate, tc.want.failureTime, crAfter.Status.FailureTime)
+			assert.Equal(t, tc.want.taskID, crAfter.Annotations[TaskIDAnnotation], "unexpected task ID")
+			assert.Equal(t, tc.want.taskEndpoint, crAfter.Annotations[TaskEndpointAnnotation], "unexpected task endpoint")
 			condition := cmutil.GetCertificateRequestCondition(&crAfter, cmapi.CertificateRequestConditionReady)
 
 			verifyCondition(t, condition, tc.want)
-			verifyEvents(t, condition, actualEvents, reconcileErr)
+			verifyEvents(t, condition, tc.want.events, actualEvents, reconcileErr)
+		})
+	}
+}
+
+func TestReconcileSubmitsCSROnce(t *testing.T) {
+	type params struct {
+		failedTaskPatches int
+		errSign           error
+	}
+	type want struct {
+		error       error
+		submissions int
+		taskID      string
+		failed      bool
+	}
+
+	cases := map[string]struct {
+		params params
+		want   want
+	}{
+		"ShouldRetryRecordingTask": {
+			params: params{failedTaskPatches: 1},
+			want:   want{submissions: 1, taskID: fakeTaskID},
+		},
+		"ShouldNotResubmitWhenTaskIsNotRecorded": {
+			params: params{failedTaskPatches: -1},
+			want:   want{error: errSetTask, submissions: 1, failed: true},
+		},
+		"ShouldResubmitWhenSubmissionFailed": {
+			params: params{errSign: errors.New("simulated sign error")},
+			want:   want{error: errSignerSign, submissions: 2},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, certv1alpha1.AddToScheme(scheme))
+	assert.NoError(t, cmapi.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pendingSigner := &fakeSigner{errSign: tc.params.errSign}
+			if tc.params.errSign == nil {
+				pendingSigner.pendingTask = signer.Task{ID: fakeTaskID, SubmittedAt: fixedClockStart}
+			}
+			failedTaskPatches := 0
+
+			name := types.NamespacedName{Namespace: certificateRequestNS, Name: certificateRequestName}
+			_, fakeClient, controller := setupController(scheme, args{
+				crObjects: []client.Object{
+					cmgen.CertificateRequest(
+						certificateRequestName,
+						cmgen.SetCertificateRequestNamespace(certificateRequestNS),
+						cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+							Name:  issuerName,
+							Group: certv1alpha1.GroupVersion.Group,
+							Kind:  issuerKind,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionApproved,
+							Status: cmmeta.ConditionTrue,
+						}),
+						cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:   cmapi.CertificateRequestConditionReady,
+							Status: cmmeta.ConditionUnknown,
+						}),
+					),
+				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerCredentials,
+							Namespace: certificateRequestNS,
+						},
+					},
+				},
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerName,
+							Namespace: certificateRequestNS,
+						},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+						},
+						Status: certv1alpha1.IssuerStatus{
+							Conditions: []metav1.Condition{
+								{
+									Type:   string(cmapi.CertificateRequestConditionReady),
+									Status: metav1.ConditionStatus(cmmeta.ConditionTrue),
+								},
+							},
+						},
+					},
+				},
+				signerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (signer.Signer, error) {
+					return pendingSigner, nil
+				},
+				interceptorFuncs: interceptor.Funcs{
+					Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+						data, err := patch.Data(obj)
+						assert.NoError(t, err)
+
+						recordsTask := strings.Contains(string(data), TaskIDAnnotation)
+						if recordsTask && (tc.params.failedTaskPatches < 0 || failedTaskPatches < tc.params.failedTaskPatches) {
+							failedTaskPatches++
+							return apierrors.NewConflict(cmapi.Resource("certificaterequests"), obj.GetName(), errors.New("simulated conflict"))
+						}
+
+						return c.Patch(ctx, obj, patch, opts...)
+					},
+				},
+			})
+
+			for reconciles := 0; reconciles < 2; reconciles++ {
+				_, reconcileErr := controller.Reconcile(
+					ctrl.LoggerInto(context.TODO(), logrtesting.New(t)),
+					reconcile.Request{NamespacedName: name},
+				)
+				if tc.want.error != nil && (reconciles == 0 || !tc.want.failed) {
+					assertErrorIs(t, tc.want.error, reconcileErr)
+				} else {
+					assert.NoError(t, reconcileErr)
+				}
+			}
+
+			assert.Equal(t, tc.want.submissions, pendingSigner.submissions, "unexpected number of submissions of the CSR")
+
+			cr := getCertificateRequest(t, fakeClient, name)
+			assert.Equal(t, tc.want.taskID, cr.Annotations[TaskIDAnnotation], "unexpected task ID")
+			assert.Equal(t, tc.want.failed, IsAlreadyFailed(cr), "unexpected failure")
+		})
+	}
+}
+
+func TestSignRequest(t *testing.T) {
+	const clusterID = "cluster-1"
+	csr := []byte("csr")
+
+	type want struct {
+		request signer.SignRequest
+	}
+
+	cases := map[string]struct {
+		certificateRequest *cmapi.CertificateRequest
+		want               want
+	}{
+		"ShouldDescribeCertificateRequest": {
+			certificateRequest: cmgen.CertificateRequest(
+				certificateRequestName,
+				cmgen.SetCertificateRequestNamespace(certificateRequestNS),
+				cmgen.SetCertificateRequestCSR(csr),
+				cmgen.SetCertificateRequestUsername("user-1"),
+				cmgen.SetCertificateRequestAnnotations(map[string]string{cmapi.CertificateNameKey: "certificate-1"}),
+			),
+			want: want{
+				request: signer.SignRequest{
+					CSR: csr,
+					Metadata: signer.RequestMetadata{
+						ClusterID:   clusterID,
+						Namespace:   certificateRequestNS,
+						Name:        certificateRequestName,
+						Username:    "user-1",
+						Annotations: map[string]string{cmapi.CertificateNameKey: "certificate-1"},
+					},
+				},
+			},
+		},
+		"ShouldRequestDurationOfCertificateRequest": {
+			certificateRequest: cmgen.CertificateRequest(
+				certificateRequestName,
+				cmgen.SetCertificateRequestNamespace(certificateRequestNS),
+				cmgen.SetCertificateRequestCSR(csr),
+				cmgen.SetCertificateRequestDuration(&metav1.Duration{Duration: time.Hour}),
+			),
+			want: want{
+				request: signer.SignRequest{
+					CSR:      csr,
+					Duration: time.Hour,
+					Metadata: signer.RequestMetadata{
+						ClusterID: clusterID,
+						Namespace: certificateRequestNS,
+						Name:      certificateRequestName,
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want.request, signRequest(*tc.certificateRequest, clusterID))
 		})
 	}
 }
@@ -734,6 +1871,7 @@ func setupController(scheme *runtime.Scheme, args args) (*record.FakeRecorder, c
 		WithObjects(args.issuerObjects...).
 		WithStatusSubresource(args.issuerObjects...).
 		WithStatusSubresource(args.crObjects...).
+		WithInterceptorFuncs(args.interceptorFuncs).
 		Build()
 
 	controller := CertificateRequestReconciler{
@@ -795,7 +1933,8 @@ func verifyCondition(t *testing.T, condition *cmapi.CertificateRequestCondition,
 // Event contents should match the status and message of the condition;
 // Event type should be Warning if the Reconcile failed (temporary error);
 // Event type should be warning if the condition status is failed (permanent error).
-func verifyEvents(t *testing.T, condition *cmapi.CertificateRequestCondition, actualEvents []string, reconcileErr error) {
+// The expected events, such as warnings about the issued certificate, are emitted before it.
+func verifyEvents(t *testing.T, condition *cmapi.CertificateRequestCondition, expectedEvents, actualEvents []string, reconcileErr error) {
 	if condition == nil {
 		assert.Empty(t, actualEvents, "Found unexpected Events without a corresponding Ready condition")
 		return
@@ -811,8 +1950,8 @@ func verifyEvents(t *testing.T, condition *cmapi.CertificateRequestCondition, ac
 		eventMessage = fmt.Sprintf("Error: %v", reconcileErr)
 	}
 
-	assert.Equal(t, []string{fmt.Sprintf("%s %s %s", expectedEventType, eventReasonCertificateRequestReconciler, eventMessage)},
-		actualEvents, "expected a single event matching the condition",
+	assert.Equal(t, append(expectedEvents, fmt.Sprintf("%s %s %s", expectedEventType, eventReasonCertificateRequestReconciler, eventMessage)),
+		actualEvents, "expected the expected events and a single event matching the condition",
 	)
 }
 
@@ -836,3 +1975,66 @@ func verifyCertificateRequestReadyCondition(t *testing.T, status cmmeta.Conditio
 	assert.Contains(t, validReasons, reason, "unexpected condition reason")
 	assert.Equal(t, reason, condition.Reason, "unexpected condition reason")
 }
+
+// setCertificateRequestCreationTimestamp sets the time at which the CertificateRequest was created.
+// openCircuitBreakerIssuerStatus returns the status which the Issuer controller reports for an Issuer
+// whose health check failed while the circuit breaker of its signer backend is open.
+func openCircuitBreakerIssuerStatus() certv1alpha1.IssuerStatus {
+	status := certv1alpha1.IssuerStatus{}
+	issuer.SetCircuitBreakerCondition(&status, signer.CircuitBreakerStatus{State: signer.CircuitBreakerOpen, Failures: 5, RetryAfter: fakeRequeueAfter})
+	issuer.SetReadyCondition(&status, metav1.ConditionFalse, "Error", "simulated health check error")
+	return status
+}
+
+func setCertificateRequestCreationTimestamp(creationTimestamp metav1.Time) cmgen.CertificateRequestModifier {
+	return func(cr *cmapi.CertificateRequest) {
+		cr.CreationTimestamp = creationTimestamp
+	}
+}
+
+// testIssuance holds a PEM encoded CSR, and the certificate and CA issued for it, valid at fixedClockStart.
+type testIssuance struct {
+	csr         []byte
+	certificate []byte
+	ca          []byte
+}
+
+// generateTestIssuance returns a CSR for a new key, and a certificate issued for it by a new CA.
+func generateTestIssuance(t *testing.T) testIssuance {
+	t.Helper()
+
+	caKey, err := cmpki.GenerateECPrivateKey(cmpki.ECCurve256)
+	assert.NoError(t, err)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             fixedClockStart.Add(-time.Hour),
+		NotAfter:              fixedClockStart.Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caPEM, caCert, err := cmpki.SignCertificate(caTemplate, caTemplate, caKey.Public(), caKey)
+	assert.NoError(t, err)
+
+	key, err := cmpki.GenerateECPrivateKey(cmpki.ECCurve256)
+	assert.NoError(t, err)
+
+	csrDER, err := cmpki.EncodeCSR(&x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "test.example.com"},
+		DNSNames: []string{"test.example.com"},
+	}, key)
+	assert.NoError(t, err)
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+
+	template, err := cmpki.CertificateTemplateFromCSRPEM(csrPEM)
+	assert.NoError(t, err)
+	template.NotBefore = fixedClockStart
+	template.NotAfter = fixedClockStart.Add(time.Hour)
+
+	bundle, err := cmpki.SignCSRTemplate([]*x509.Certificate{caCert}, caKey, template)
+	assert.NoError(t, err)
+
+	return testIssuance{csr: csrPEM, certificate: bundle.ChainPEM, ca: caPEM}
+}
@@ -0,0 +1,145 @@
+//go:build envtest
+
+package certificaterequest
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	cmutil "github.com/cert-manager/cert-manager/pkg/api/util"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	cmpki "github.com/cert-manager/cert-manager/pkg/util/pki"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	"github.com/dana-team/cert-external-issuer/internal/fakecertapi"
+	"github.com/dana-team/cert-external-issuer/internal/issuer"
+)
+
+const (
+	signingTestToken          = "dummy-token"
+	signingTestPKCS12Password = "dummy-password"
+	signingTestDownloadPath   = "/download/"
+	signingTestCommonName     = "test.example.com"
+	signingTestIssueDelay     = 2 * time.Second
+	signingTestTimeout        = 30 * time.Second
+	signingTestPollInterval   = 250 * time.Millisecond
+)
+
+var _ = Describe("Signing CertificateRequests with the Cert API", func() {
+	DescribeTable("should submit the CSR, poll its task and store the downloaded certificate",
+		func(form string, options ...func(*fakecertapi.Server)) {
+			options = append(options, fakecertapi.WithToken(signingTestToken), fakecertapi.WithDelay(signingTestIssueDelay))
+			certAPI, err := fakecertapi.NewServer(options...)
+			Expect(err).NotTo(HaveOccurred())
+
+			server := httptest.NewServer(certAPI)
+			DeferCleanup(server.Close)
+
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "signing-"}}
+			Expect(k8sClient.Create(ctx, namespace)).To(Succeed())
+
+			By("creating a ready Issuer of the Cert API")
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "cert-api-credentials", Namespace: namespace.Name},
+				Data: map[string][]byte{
+					"token":          []byte(signingTestToken),
+					"pkcs12Password": []byte(signingTestPKCS12Password),
+				},
+			}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+
+			issuerInstance := &certv1alpha1.Issuer{
+				ObjectMeta: metav1.ObjectMeta{Name: "cert-api", Namespace: namespace.Name},
+				Spec: certv1alpha1.IssuerSpec{
+					APIEndpoint:      server.URL + "/",
+					DownloadEndpoint: signingTestDownloadPath,
+					Form:             form,
+					AuthSecretName:   secret.Name,
+					HTTPConfig: certv1alpha1.HTTPConfig{
+						RetryBackoff: certv1alpha1.RetryBackoff{Duration: metav1.Duration{Duration: time.Second}},
+					},
+					CertificateRestrictions: certv1alpha1.Restrictions{
+						SubjectAltNamesRestrictions: certv1alpha1.SubjectAltNamesRestrictions{AllowDNSNames: true},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, issuerInstance)).To(Succeed())
+
+			issuer.SetReadyCondition(&issuerInstance.Status, metav1.ConditionTrue, "Checked", "Signing CertificateRequests")
+			Expect(k8sClient.Status().Update(ctx, issuerInstance)).To(Succeed())
+
+			By("creating an approved CertificateRequest")
+			certificateRequest := &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "certificate", Namespace: namespace.Name},
+				Spec: cmapi.CertificateRequestSpec{
+					Request: generateSigningTestCSR(),
+					IssuerRef: cmmeta.ObjectReference{
+						Name:  issuerInstance.Name,
+						Kind:  "Issuer",
+						Group: certv1alpha1.GroupVersion.Group,
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, certificateRequest)).To(Succeed())
+
+			// the controller may update the status of the CertificateRequest before it is approved
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(certificateRequest), certificateRequest)).To(Succeed())
+				cmutil.SetCertificateRequestCondition(certificateRequest, cmapi.CertificateRequestConditionApproved,
+					cmmeta.ConditionTrue, "Approved", "Approved by the signing test")
+				g.Expect(k8sClient.Status().Update(ctx, certificateRequest)).To(Succeed())
+			}, signingTestTimeout, signingTestPollInterval).Should(Succeed())
+
+			By("waiting for the task to be submitted")
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(certificateRequest), certificateRequest)).To(Succeed())
+				g.Expect(certificateRequest.Annotations).To(HaveKey(TaskIDAnnotation))
+				g.Expect(certificateRequest.Annotations).To(HaveKey(TaskSubmittedAtAnnotation))
+			}, signingTestTimeout, signingTestPollInterval).Should(Succeed())
+
+			By("waiting for the certificate to be downloaded once the task is issued")
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(certificateRequest), certificateRequest)).To(Succeed())
+				g.Expect(cmutil.CertificateRequestHasCondition(certificateRequest, cmapi.CertificateRequestCondition{
+					Type:   cmapi.CertificateRequestConditionReady,
+					Status: cmmeta.ConditionTrue,
+					Reason: cmapi.CertificateRequestReasonIssued,
+				})).To(BeTrue())
+			}, signingTestTimeout, signingTestPollInterval).Should(Succeed())
+
+			certificate, err := cmpki.DecodeX509CertificateBytes(certificateRequest.Status.Certificate)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(certificate.Subject.CommonName).To(Equal(signingTestCommonName))
+
+			caPEM, err := certAPI.CACertificatePEM()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(certificateRequest.Status.CA).To(Equal(caPEM))
+		},
+		Entry("in the chain form", fakecertapi.FormChain),
+		Entry("in the pkcs12 form", fakecertapi.FormPKCS12, fakecertapi.WithPKCS12Password(signingTestPKCS12Password)),
+	)
+})
+
+// generateSigningTestCSR returns a PEM encoded CSR for a new key.
+func generateSigningTestCSR() []byte {
+	key, err := cmpki.GenerateECPrivateKey(cmpki.ECCurve256)
+	Expect(err).NotTo(HaveOccurred())
+
+	csrDER, err := cmpki.EncodeCSR(&x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: signingTestCommonName},
+		DNSNames: []string{signingTestCommonName},
+	}, key)
+	Expect(err).NotTo(HaveOccurred())
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+}
@@ -0,0 +1,125 @@
+package certificaterequest
+
+import (
+	"context"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	certsigner "github.com/dana-team/cert-external-issuer/internal/issuer/signer"
+)
+
+const (
+	// TaskIDAnnotation is the annotation on a CertificateRequest holding the ID of the
+	// signing task that the CSR was submitted as.
+	TaskIDAnnotation = "cert.dana.io/task-id"
+
+	// TaskSubmittedAtAnnotation is the annotation on a CertificateRequest holding the time
+	// at which the signing task was submitted, in RFC 3339 format.
+	TaskSubmittedAtAnnotation = "cert.dana.io/task-submitted-at"
+
+	// TaskPollingIntervalAnnotation is the annotation on a CertificateRequest holding the interval
+	// at which the Cert API asked for the signing task to be polled, such as "30s".
+	TaskPollingIntervalAnnotation = "cert.dana.io/task-polling-interval"
+
+	// TaskEndpointAnnotation is the annotation on a CertificateRequest holding the URL of the
+	// Cert API endpoint which accepted the signing task, and on which the task is polled.
+	TaskEndpointAnnotation = "cert.dana.io/task-endpoint"
+
+	// TaskSubmissionAnnotation is the annotation on a CertificateRequest holding the time at which its
+	// CSR started to be submitted, in RFC 3339 format. It is removed once the signing task is recorded,
+	// so that a CertificateRequest which holds it without a task may have been submitted already.
+	TaskSubmissionAnnotation = "cert.dana.io/task-submission-started-at"
+)
+
+// getTask returns the signing task recorded on the CertificateRequest.
+// An empty task is returned if the CSR has not been submitted yet.
+func getTask(certificateRequest cmapi.CertificateRequest) certsigner.Task {
+	annotations := certificateRequest.GetAnnotations()
+
+	task := certsigner.Task{
+		ID:       annotations[TaskIDAnnotation],
+		Endpoint: annotations[TaskEndpointAnnotation],
+	}
+	if submittedAt, err := time.Parse(time.RFC3339, annotations[TaskSubmittedAtAnnotation]); err == nil {
+		task.SubmittedAt = submittedAt
+	}
+	if pollingInterval, err := time.ParseDuration(annotations[TaskPollingIntervalAnnotation]); err == nil {
+		task.PollingInterval = pollingInterval
+	}
+
+	return task
+}
+
+// submissionStarted returns a boolean indicating whether the CSR of the CertificateRequest started to be
+// submitted without its signing task being recorded, such as when the controller was restarted in between.
+func submissionStarted(certificateRequest cmapi.CertificateRequest) bool {
+	_, ok := certificateRequest.GetAnnotations()[TaskSubmissionAnnotation]
+	return ok
+}
+
+// startSubmission records on the CertificateRequest that its CSR is about to be submitted, so that
+// the CSR is never submitted again if its signing task is not recorded afterwards.
+func (r *CertificateRequestReconciler) startSubmission(ctx context.Context, certificateRequest *cmapi.CertificateRequest) error {
+	return r.patchAnnotations(ctx, certificateRequest, func(annotations map[string]string) {
+		annotations[TaskSubmissionAnnotation] = r.Clock.Now().UTC().Format(time.RFC3339)
+	})
+}
+
+// cancelSubmission removes the record of the submission of the CSR from the CertificateRequest, once
+// the signer failed to submit it or signed it without a task, so that the CSR can be submitted again.
+func (r *CertificateRequestReconciler) cancelSubmission(ctx context.Context, certificateRequest *cmapi.CertificateRequest) error {
+	return r.patchAnnotations(ctx, certificateRequest, func(annotations map[string]string) {
+		delete(annotations, TaskSubmissionAnnotation)
+	})
+}
+
+// setTask records the signing task on the CertificateRequest in place of the record of its submission,
+// so that later reconciles poll the task instead of submitting the CSR again.
+func (r *CertificateRequestReconciler) setTask(ctx context.Context, certificateRequest *cmapi.CertificateRequest, task certsigner.Task) error {
+	return r.patchAnnotations(ctx, certificateRequest, func(annotations map[string]string) {
+		delete(annotations, TaskSubmissionAnnotation)
+		annotations[TaskIDAnnotation] = task.ID
+		annotations[TaskSubmittedAtAnnotation] = task.SubmittedAt.UTC().Format(time.RFC3339)
+		if task.PollingInterval > 0 {
+			annotations[TaskPollingIntervalAnnotation] = task.PollingInterval.String()
+		}
+		if task.Endpoint != "" {
+			annotations[TaskEndpointAnnotation] = task.Endpoint
+		}
+	})
+}
+
+// patchAnnotations patches the annotations of the CertificateRequest with the given mutation. The patch
+// is retried on conflicts and transient errors of the API server, as the annotations keep the CSR from
+// being submitted twice.
+func (r *CertificateRequestReconciler) patchAnnotations(ctx context.Context, certificateRequest *cmapi.CertificateRequest, mutate func(map[string]string)) error {
+	return retry.OnError(retry.DefaultBackoff, isTransientError, func() error {
+		original := certificateRequest.DeepCopy()
+		patch := client.MergeFrom(original)
+
+		annotations := certificateRequest.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		mutate(annotations)
+		certificateRequest.SetAnnotations(annotations)
+
+		if err := r.Patch(ctx, certificateRequest, patch); err != nil {
+			original.DeepCopyInto(certificateRequest)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// isTransientError returns a boolean indicating whether a request to the API server failed with an
+// error which may not occur when the request is retried.
+func isTransientError(err error) bool {
+	return apierrors.IsConflict(err) || apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) || apierrors.IsServiceUnavailable(err) || apierrors.IsInternalError(err)
+}
@@ -8,6 +8,8 @@ import (
 	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
 )
 
+const clusterIssuerKind = "ClusterIssuer"
+
 // GetIssuerSpecAndStatus returns the spec and status of an Issuer or ClusterIssuer.
 func GetIssuerSpecAndStatus(issuer client.Object) (*certv1alpha1.IssuerSpec, *certv1alpha1.IssuerStatus, error) {
 	switch t := issuer.(type) {
@@ -19,3 +21,16 @@ func GetIssuerSpecAndStatus(issuer client.Object) (*certv1alpha1.IssuerSpec, *ce
 		return nil, nil, fmt.Errorf("not an issuer type: %t", t)
 	}
 }
+
+// AuditKeyIssuer is the key which correlates exchanges with the Cert API to the issuer on whose behalf they are made.
+const AuditKeyIssuer = "issuer"
+
+// IssuerReference returns the reference to an Issuer or ClusterIssuer which correlates exchanges with the
+// Cert API to it, "Issuer/<namespace>/<name>" or "ClusterIssuer/<name>".
+func IssuerReference(kind, namespace, name string) string {
+	if kind == clusterIssuerKind {
+		return kind + "/" + name
+	}
+
+	return kind + "/" + namespace + "/" + name
+}
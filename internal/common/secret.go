@@ -5,11 +5,14 @@ import (
 	"fmt"
 
 	certyv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	"github.com/dana-team/cert-external-issuer/internal/issuer/signer"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+const caBundleDefaultKey = "ca.crt"
+
 // getSecretNamespace returns the namespace where the credentials for the issuer are stored.
 func getSecretNamespace(issuer client.Object, namespace, clusterResourceNamespace string) (string, error) {
 	var secretNamespace string
@@ -35,3 +38,88 @@ func GetSecret(cl client.Client, ctx context.Context, issuer client.Object, secr
 	err = cl.Get(ctx, types.NamespacedName{Name: secretName, Namespace: secretNamespace}, &secret)
 	return secret, err
 }
+
+// AddHTTPConfigData returns a copy of the secret data of the issuer, to which the data referenced in its
+// HTTPConfig is added under the keys read by the signer: the certificate and key of the client certificate
+// Secret, the CA bundles of the CA bundle Secret and ConfigMap, and the credentials of the proxy Secret.
+// The resourceVersions of the referenced objects are returned alongside, so that changes to them can be detected.
+// The secret data is returned as is if the HTTPConfig does not reference any Secret or ConfigMap.
+func AddHTTPConfigData(cl client.Client, ctx context.Context, issuer client.Object, issuerSpec *certyv1alpha1.IssuerSpec, secretData map[string][]byte, namespace, clusterResourceNamespace string) (map[string][]byte, []string, error) {
+	httpConfig := issuerSpec.HTTPConfig
+	if httpConfig.ClientCertificateSecretName == "" && httpConfig.CABundleSecretRef == nil &&
+		httpConfig.CABundleConfigMapRef == nil && httpConfig.ProxySecretName == "" {
+		return secretData, nil, nil
+	}
+
+	var resourceVersions []string
+	data := make(map[string][]byte, len(secretData)+5)
+	for key, value := range secretData {
+		data[key] = value
+	}
+
+	if httpConfig.ClientCertificateSecretName != "" {
+		secret, err := GetSecret(cl, ctx, issuer, httpConfig.ClientCertificateSecretName, namespace, clusterResourceNamespace)
+		if err != nil {
+			return nil, nil, fmt.Errorf("client certificate secret %q: %w", httpConfig.ClientCertificateSecretName, err)
+		}
+		resourceVersions = append(resourceVersions, secret.ResourceVersion)
+		data[signer.ClientCertificateSecretKey] = secret.Data[corev1.TLSCertKey]
+		data[signer.ClientKeySecretKey] = secret.Data[corev1.TLSPrivateKeyKey]
+	}
+
+	if httpConfig.ProxySecretName != "" {
+		secret, err := GetSecret(cl, ctx, issuer, httpConfig.ProxySecretName, namespace, clusterResourceNamespace)
+		if err != nil {
+			return nil, nil, fmt.Errorf("proxy secret %q: %w", httpConfig.ProxySecretName, err)
+		}
+		resourceVersions = append(resourceVersions, secret.ResourceVersion)
+		data[signer.ProxyUsernameSecretKey] = secret.Data[corev1.BasicAuthUsernameKey]
+		data[signer.ProxyPasswordSecretKey] = secret.Data[corev1.BasicAuthPasswordKey]
+	}
+
+	var caBundle []byte
+	if ref := httpConfig.CABundleSecretRef; ref != nil {
+		secret, err := GetSecret(cl, ctx, issuer, ref.Name, namespace, clusterResourceNamespace)
+		if err != nil {
+			return nil, nil, fmt.Errorf("CA bundle secret %q: %w", ref.Name, err)
+		}
+		resourceVersions = append(resourceVersions, secret.ResourceVersion)
+		caBundle = append(caBundle, secret.Data[keyOrDefault(ref.Key)]...)
+	}
+
+	if ref := httpConfig.CABundleConfigMapRef; ref != nil {
+		configMap, err := GetConfigMap(cl, ctx, issuer, ref.Name, namespace, clusterResourceNamespace)
+		if err != nil {
+			return nil, nil, fmt.Errorf("CA bundle configmap %q: %w", ref.Name, err)
+		}
+		resourceVersions = append(resourceVersions, configMap.ResourceVersion)
+		caBundle = append(caBundle, configMap.Data[keyOrDefault(ref.Key)]...)
+	}
+
+	if caBundle != nil {
+		data[signer.CABundleSecretKey] = caBundle
+	}
+
+	return data, resourceVersions, nil
+}
+
+// GetConfigMap returns a configmap in the namespace where the credentials for the issuer exist.
+func GetConfigMap(cl client.Client, ctx context.Context, issuer client.Object, configMapName, namespace, clusterResourceNamespace string) (corev1.ConfigMap, error) {
+	configMap := corev1.ConfigMap{}
+	configMapNamespace, err := getSecretNamespace(issuer, namespace, clusterResourceNamespace)
+	if err != nil {
+		return configMap, err
+	}
+
+	err = cl.Get(ctx, types.NamespacedName{Name: configMapName, Namespace: configMapNamespace}, &configMap)
+	return configMap, err
+}
+
+// keyOrDefault returns the key of a CA bundle reference, which defaults to "ca.crt".
+func keyOrDefault(key string) string {
+	if key == "" {
+		return caBundleDefaultKey
+	}
+
+	return key
+}
@@ -0,0 +1,453 @@
+package fakecertapi
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmpkgutil "github.com/cert-manager/cert-manager/pkg/util/pki"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+const (
+	// FormChain is the form in which the certificate chain is downloaded.
+	FormChain = "chain"
+
+	// FormPublic is the form in which only the leaf certificate is downloaded.
+	FormPublic = "public"
+
+	// FormPKCS12 is the form in which the certificate chain is downloaded as a PKCS#12 archive,
+	// encrypted with the password of the Server.
+	FormPKCS12 = "pkcs12"
+
+	csrPath               = "csr"
+	csrFieldName          = "file"
+	csrJSONFieldName      = "csr"
+	authorizationHeader   = "Authorization"
+	bearerPrefix          = "Bearer "
+	contentTypeHeader     = "Content-Type"
+	contentTypeJSON       = "application/json"
+	contentTypeMultipart  = "multipart/form-data"
+	contentTypePKCS10     = "application/pkcs10"
+	maxCSRSize            = 1 << 20
+	taskIDSize            = 16
+	caValidity            = 10 * 365 * 24 * time.Hour
+	defaultDownloadPath   = "/download/"
+	defaultBasePath       = "/"
+	defaultPendingStatus  = http.StatusNotFound
+	defaultCertificateTTL = cmapi.DefaultCertificateDuration
+)
+
+var (
+	errMissingCSR       = errors.New("missing CSR in request")
+	errUnsupportedType  = errors.New("unsupported content type")
+	errInvalidCSR       = errors.New("invalid CSR")
+	errUnknownTask      = errors.New("unknown task")
+	errUnsupportedForm  = errors.New("unsupported form")
+	errUnauthorized     = errors.New("missing or invalid bearer token")
+	errFailedSigningCSR = errors.New("failed to sign CSR")
+	errInvalidDuration  = errors.New("invalid duration")
+	errFailedEncoding   = errors.New("failed to encode certificate")
+)
+
+// ErrorInjector returns the status code with which a request should fail,
+// or zero if the request should be served normally.
+type ErrorInjector func(r *http.Request) int
+
+// Server is a fake implementation of the Cert API. CSRs posted to it are signed
+// by an in-memory CA and can be downloaded once the configured delay has passed.
+type Server struct {
+	basePath      string
+	downloadPath  string
+	token         string
+	delay         time.Duration
+	pendingStatus int
+	injectError   ErrorInjector
+
+	durationParameter string
+	pkcs12Password    string
+
+	caCert *x509.Certificate
+	caKey  crypto.Signer
+
+	mu    sync.Mutex
+	tasks map[string]task
+}
+
+// task is a CSR which was posted to the Server.
+type task struct {
+	bundle      cmpkgutil.PEMBundle
+	submittedAt time.Time
+}
+
+// postResponse is the response body of a POST request of a CSR.
+type postResponse struct {
+	TaskID string `json:"taskId"`
+}
+
+// downloadResponse is the response body of a download request of a certificate.
+type downloadResponse struct {
+	Data string `json:"data"`
+}
+
+// errorResponse is the response body of a failed request.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewServer returns a new Server with a freshly generated CA.
+func NewServer(options ...func(*Server)) (*Server, error) {
+	s := &Server{
+		basePath:      defaultBasePath,
+		downloadPath:  defaultDownloadPath,
+		pendingStatus: defaultPendingStatus,
+		tasks:         map[string]task{},
+	}
+	for _, o := range options {
+		o(s)
+	}
+
+	if s.caCert == nil {
+		caCert, caKey, err := generateCA()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate CA: %v", err)
+		}
+		s.caCert, s.caKey = caCert, caKey
+	}
+
+	return s, nil
+}
+
+// WithBasePath returns a Server which serves the Cert API under the given path.
+// It corresponds to the path of the apiEndpoint of the Issuer.
+func WithBasePath(basePath string) func(*Server) {
+	return func(s *Server) {
+		s.basePath = basePath
+	}
+}
+
+// WithDownloadPath returns a Server which serves certificates on the given path,
+// which follows the task ID. It corresponds to the downloadEndpoint of the Issuer.
+func WithDownloadPath(downloadPath string) func(*Server) {
+	return func(s *Server) {
+		s.downloadPath = downloadPath
+	}
+}
+
+// WithToken returns a Server which requires requests to carry the given bearer token.
+func WithToken(token string) func(*Server) {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithDelay returns a Server which only serves a certificate once the given delay
+// has passed since its CSR was posted.
+func WithDelay(delay time.Duration) func(*Server) {
+	return func(s *Server) {
+		s.delay = delay
+	}
+}
+
+// WithPendingStatus returns a Server which responds with the given status code to
+// download requests of certificates which are not yet issued.
+func WithPendingStatus(status int) func(*Server) {
+	return func(s *Server) {
+		s.pendingStatus = status
+	}
+}
+
+// WithErrorInjector returns a Server which fails requests for which the injector returns a status code.
+func WithErrorInjector(injector ErrorInjector) func(*Server) {
+	return func(s *Server) {
+		s.injectError = injector
+	}
+}
+
+// WithDurationParameter returns a Server which issues certificates for the duration, in whole seconds,
+// in the given parameter of the request, if it is set. It corresponds to the durationParameter of the
+// request profile of the Issuer.
+func WithDurationParameter(durationParameter string) func(*Server) {
+	return func(s *Server) {
+		s.durationParameter = durationParameter
+	}
+}
+
+// WithPKCS12Password returns a Server which encrypts the PKCS#12 archives of the pkcs12 form with the
+// given password. It corresponds to the "pkcs12Password" key of the AuthSecret of the Issuer.
+func WithPKCS12Password(password string) func(*Server) {
+	return func(s *Server) {
+		s.pkcs12Password = password
+	}
+}
+
+// WithCA returns a Server which signs CSRs with the given CA key pair.
+func WithCA(caCert *x509.Certificate, caKey crypto.Signer) func(*Server) {
+	return func(s *Server) {
+		s.caCert = caCert
+		s.caKey = caKey
+	}
+}
+
+// CACertificatePEM returns the PEM encoded certificate of the CA which signs the CSRs.
+func (s *Server) CACertificatePEM() ([]byte, error) {
+	return cmpkgutil.EncodeX509(s.caCert)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.token != "" && r.Header.Get(authorizationHeader) != bearerPrefix+s.token {
+		writeError(w, http.StatusUnauthorized, errUnauthorized)
+		return
+	}
+
+	if s.injectError != nil {
+		if status := s.injectError(r); status != 0 {
+			writeError(w, status, fmt.Errorf("injected error for %s %s", r.Method, r.URL.Path))
+			return
+		}
+	}
+
+	path, ok := strings.CutPrefix(r.URL.Path, s.basePath)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && path == csrPath:
+		s.handlePost(w, r)
+	case r.Method == http.MethodGet && strings.Contains(path, s.downloadPath):
+		taskID, form, _ := strings.Cut(path, s.downloadPath)
+		s.handleDownload(w, taskID, form)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// handlePost signs the CSR in the request and responds with the ID of its task.
+func (s *Server) handlePost(w http.ResponseWriter, r *http.Request) {
+	csrPEM, parameters, err := readCSR(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	duration, err := s.requestedDuration(parameters)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	template, err := cmpkgutil.CertificateTemplateFromCSRPEM(csrPEM, cmpkgutil.CertificateTemplateOverrideDuration(duration))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("%w: %v", errInvalidCSR, err))
+		return
+	}
+
+	bundle, err := cmpkgutil.SignCSRTemplate([]*x509.Certificate{s.caCert}, s.caKey, template)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("%w: %v", errFailedSigningCSR, err))
+		return
+	}
+
+	taskID, err := newTaskID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	s.mu.Lock()
+	s.tasks[taskID] = task{bundle: bundle, submittedAt: time.Now()}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, postResponse{TaskID: taskID})
+}
+
+// requestedDuration returns the duration of the certificate requested in the parameters of the
+// request, or the default duration if none was requested.
+func (s *Server) requestedDuration(parameters map[string]string) (time.Duration, error) {
+	value := parameters[s.durationParameter]
+	if s.durationParameter == "" || value == "" {
+		return defaultCertificateTTL, nil
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return 0, fmt.Errorf("%w: %q", errInvalidDuration, value)
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// readCSR returns the CSR and the parameters of the request. They are read from the "file" field and
+// the other fields of a multipart form, the "csr" field and the other fields of a JSON object, or the
+// raw "application/pkcs10" body and the query parameters.
+func readCSR(r *http.Request) ([]byte, map[string]string, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get(contentTypeHeader))
+	body := io.LimitReader(r.Body, maxCSRSize)
+
+	switch mediaType {
+	case contentTypeMultipart:
+		file, _, err := r.FormFile(csrFieldName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", errMissingCSR, err)
+		}
+		defer func() { _ = file.Close() }()
+
+		csrPEM, err := io.ReadAll(io.LimitReader(file, maxCSRSize))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", errMissingCSR, err)
+		}
+		return csrPEM, firstValues(r.MultipartForm.Value), nil
+	case contentTypeJSON:
+		var fields map[string]string
+		if err := json.NewDecoder(body).Decode(&fields); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", errMissingCSR, err)
+		}
+		csrPEM, ok := fields[csrJSONFieldName]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: missing JSON field %q", errMissingCSR, csrJSONFieldName)
+		}
+		delete(fields, csrJSONFieldName)
+		return []byte(csrPEM), fields, nil
+	case contentTypePKCS10:
+		csrPEM, err := io.ReadAll(body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", errMissingCSR, err)
+		}
+		return csrPEM, firstValues(r.URL.Query()), nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %q", errUnsupportedType, mediaType)
+	}
+}
+
+// firstValues returns the first value of each key of the form or query values.
+func firstValues(values map[string][]string) map[string]string {
+	first := make(map[string]string, len(values))
+	for key, value := range values {
+		if len(value) > 0 {
+			first[key] = value[0]
+		}
+	}
+
+	return first
+}
+
+// handleDownload responds with the base64 encoded certificate of the task in the given form.
+func (s *Server) handleDownload(w http.ResponseWriter, taskID, form string) {
+	s.mu.Lock()
+	t, ok := s.tasks[taskID]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%w: %q", errUnknownTask, taskID))
+		return
+	}
+
+	if time.Since(t.submittedAt) < s.delay {
+		writeError(w, s.pendingStatus, fmt.Errorf("task %q is still being processed", taskID))
+		return
+	}
+
+	var data []byte
+	switch form {
+	case FormChain:
+		data = append(append([]byte{}, t.bundle.ChainPEM...), t.bundle.CAPEM...)
+	case FormPublic:
+		data = t.bundle.ChainPEM
+	case FormPKCS12:
+		var err error
+		if data, err = s.encodePKCS12(t.bundle); err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Errorf("%w: %v", errFailedEncoding, err))
+			return
+		}
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("%w: %q", errUnsupportedForm, form))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, downloadResponse{Data: base64.StdEncoding.EncodeToString(data)})
+}
+
+// encodePKCS12 returns the certificate chain of the bundle as a PKCS#12 archive encrypted with the password
+// of the Server. The Server never sees the private key of the CSR, so the archive holds a placeholder key,
+// as PKCS#12 archives of a certificate chain must hold a private key.
+func (s *Server) encodePKCS12(bundle cmpkgutil.PEMBundle) ([]byte, error) {
+	chain, err := cmpkgutil.DecodeX509CertificateChainBytes(append(append([]byte{}, bundle.ChainPEM...), bundle.CAPEM...))
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return pkcs12.Modern.Encode(key, chain[0], chain[1:], s.pkcs12Password)
+}
+
+// writeJSON writes the JSON encoding of body to the response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set(contentTypeHeader, contentTypeJSON)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeError writes err to the response with the given status code.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// newTaskID returns a random task ID.
+func newTaskID() (string, error) {
+	id := make([]byte, taskIDSize)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(id), nil
+}
+
+// generateCA returns a self-signed CA certificate and its private key.
+func generateCA() (*x509.Certificate, crypto.Signer, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(now.UnixNano()),
+		Subject:               pkix.Name{CommonName: "fake-cert-api-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+
+	_, caCert, err := cmpkgutil.SignCertificate(template, template, key.Public(), key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return caCert, key, nil
+}
@@ -0,0 +1,256 @@
+package fakecertapi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cmpkgutil "github.com/cert-manager/cert-manager/pkg/util/pki"
+	"github.com/dana-team/cert-external-issuer/internal/issuer/certhandler"
+	"github.com/dana-team/cert-external-issuer/internal/issuer/clients/cert"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testToken        = "dummy-token"
+	testBasePath     = "/api/"
+	testDownloadPath = "/download/"
+	testCommonName   = "test.example.com"
+	testDurationKey  = "validitySeconds"
+	testPassword     = "dummy-password"
+)
+
+func TestServer(t *testing.T) {
+	type args struct {
+		options        []func(*Server)
+		token          string
+		form           string
+		requestProfile cert.RequestProfile
+		parameters     map[string]string
+	}
+	type want struct {
+		postErr          bool
+		downloadErr      string
+		certificateCount int
+		duration         time.Duration
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldIssueChain": {
+			args: args{
+				token: testToken,
+				form:  FormChain,
+			},
+			want: want{
+				certificateCount: 2,
+			},
+		},
+		"ShouldIssuePublic": {
+			args: args{
+				token: testToken,
+				form:  FormPublic,
+			},
+			want: want{
+				certificateCount: 1,
+			},
+		},
+		"ShouldIssuePKCS12": {
+			args: args{
+				options: []func(*Server){WithPKCS12Password(testPassword)},
+				token:   testToken,
+				form:    FormPKCS12,
+			},
+			want: want{
+				certificateCount: 2,
+			},
+		},
+		"ShouldIssueFromJSONRequest": {
+			args: args{
+				token:          testToken,
+				form:           FormChain,
+				requestProfile: cert.RequestProfile{Encoding: cert.RequestEncodingJSON},
+			},
+			want: want{
+				certificateCount: 2,
+			},
+		},
+		"ShouldIssueFromPEMRequest": {
+			args: args{
+				token:          testToken,
+				form:           FormChain,
+				requestProfile: cert.RequestProfile{Encoding: cert.RequestEncodingPEM},
+			},
+			want: want{
+				certificateCount: 2,
+			},
+		},
+		"ShouldIssueForRequestedDuration": {
+			args: args{
+				options:    []func(*Server){WithDurationParameter(testDurationKey)},
+				token:      testToken,
+				form:       FormPublic,
+				parameters: map[string]string{testDurationKey: "3600"},
+			},
+			want: want{
+				certificateCount: 1,
+				duration:         time.Hour,
+			},
+		},
+		"ShouldIssueForRequestedDurationFromPEMRequest": {
+			args: args{
+				options:        []func(*Server){WithDurationParameter(testDurationKey)},
+				token:          testToken,
+				form:           FormPublic,
+				requestProfile: cert.RequestProfile{Encoding: cert.RequestEncodingPEM},
+				parameters:     map[string]string{testDurationKey: "3600"},
+			},
+			want: want{
+				certificateCount: 1,
+				duration:         time.Hour,
+			},
+		},
+		"ShouldFailWithInvalidDuration": {
+			args: args{
+				options:    []func(*Server){WithDurationParameter(testDurationKey)},
+				token:      testToken,
+				form:       FormPublic,
+				parameters: map[string]string{testDurationKey: "1h"},
+			},
+			want: want{
+				postErr: true,
+			},
+		},
+		"ShouldReturnNotFoundWhilePending": {
+			args: args{
+				options: []func(*Server){WithDelay(time.Hour)},
+				token:   testToken,
+				form:    FormChain,
+			},
+			want: want{
+				downloadErr: http.StatusText(http.StatusNotFound),
+			},
+		},
+		"ShouldReturnPendingStatusWhilePending": {
+			args: args{
+				options: []func(*Server){WithDelay(time.Hour), WithPendingStatus(http.StatusAccepted)},
+				token:   testToken,
+				form:    FormChain,
+			},
+			want: want{
+				downloadErr: http.StatusText(http.StatusAccepted),
+			},
+		},
+		"ShouldFailWithInvalidToken": {
+			args: args{
+				token: "invalid",
+				form:  FormChain,
+			},
+			want: want{
+				postErr: true,
+			},
+		},
+		"ShouldFailWithInjectedError": {
+			args: args{
+				options: []func(*Server){WithErrorInjector(func(r *http.Request) int {
+					if r.Method == http.MethodGet {
+						return http.StatusServiceUnavailable
+					}
+					return 0
+				})},
+				token: testToken,
+				form:  FormChain,
+			},
+			want: want{
+				downloadErr: http.StatusText(http.StatusServiceUnavailable),
+			},
+		},
+	}
+
+	csrBytes := generateTestCSR(t)
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			options := append([]func(*Server){WithBasePath(testBasePath), WithDownloadPath(testDownloadPath), WithToken(testToken)}, tc.args.options...)
+			server, err := NewServer(options...)
+			assert.NoError(t, err)
+
+			httpServer := httptest.NewServer(server)
+			defer httpServer.Close()
+
+			client := cert.NewClient(
+				cert.WithAPIEndpoint(httpServer.URL+testBasePath),
+				cert.WithDownloadEndpoint(testDownloadPath),
+				cert.WithForm(tc.args.form),
+				cert.WithToken(tc.args.token),
+				cert.WithRequestProfile(tc.args.requestProfile),
+				cert.WithHTTPClient(http.Client{}),
+			)
+
+			posted, err := client.PostCertificate(context.Background(), logr.Discard(), csrBytes, tc.args.parameters)
+			if tc.want.postErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotEmpty(t, posted.TaskID)
+
+			response, err := client.DownloadCertificate(context.Background(), logr.Discard(), posted.TaskID)
+			if tc.want.downloadErr != "" {
+				assert.ErrorContains(t, err, tc.want.downloadErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			var certificatePEM []byte
+			if tc.args.form == FormPKCS12 {
+				chainPEM, caPEM, err := certhandler.Decoder(response.Data, testPassword)
+				assert.NoError(t, err)
+				certificatePEM = append(chainPEM, caPEM...)
+			} else {
+				certificatePEM, err = base64.StdEncoding.DecodeString(response.Data)
+				assert.NoError(t, err)
+			}
+
+			certificates, err := cmpkgutil.DecodeX509CertificateChainBytes(certificatePEM)
+			assert.NoError(t, err)
+			assert.Len(t, certificates, tc.want.certificateCount)
+			assert.Equal(t, testCommonName, certificates[0].Subject.CommonName)
+			assert.NoError(t, certificates[0].CheckSignatureFrom(server.caCert))
+
+			duration := tc.want.duration
+			if duration == 0 {
+				duration = defaultCertificateTTL
+			}
+			assert.Equal(t, duration, certificates[0].NotAfter.Sub(certificates[0].NotBefore))
+		})
+	}
+}
+
+// generateTestCSR is a helper to generate a PEM encoded CSR.
+func generateTestCSR(t *testing.T) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	csrBytes, err := cmpkgutil.EncodeCSR(&x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: testCommonName},
+		DNSNames: []string{testCommonName},
+	}, key)
+	assert.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrBytes})
+}
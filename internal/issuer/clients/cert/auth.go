@@ -0,0 +1,153 @@
+package cert
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sync"
+
+	httpClient "github.com/dana-team/cert-external-issuer/internal/issuer/clients/http"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const (
+	basicAuthorization = "Basic %v"
+
+	// DefaultAPIKeyHeader is the header which carries the API key if no other header is given.
+	DefaultAPIKeyHeader = "X-API-Key"
+)
+
+// Authenticator authenticates the requests to the Cert API.
+type Authenticator interface {
+	// Headers returns the headers which authenticate a request. The requests which the Authenticator
+	// itself sends, such as OAuth2 token requests, are sent with the client of the request.
+	Headers(ctx context.Context, client httpClient.Client) (map[string][]string, error)
+
+	// CredentialHeaders returns the names of the headers which carry the credentials, whose values
+	// are redacted in the audit log.
+	CredentialHeaders() []string
+}
+
+// OAuth2Config is the configuration of the OAuth2 client credentials flow.
+type OAuth2Config struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
+type bearerAuthenticator struct {
+	token string
+}
+
+type basicAuthenticator struct {
+	username string
+	password string
+}
+
+type apiKeyAuthenticator struct {
+	header string
+	apiKey string
+}
+
+type oauth2Authenticator struct {
+	config clientcredentials.Config
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+// NewBearerAuthenticator returns an Authenticator which sends the given bearer token.
+func NewBearerAuthenticator(token string) Authenticator {
+	return &bearerAuthenticator{token: token}
+}
+
+// NewBasicAuthenticator returns an Authenticator which uses HTTP basic auth with the given credentials.
+func NewBasicAuthenticator(username, password string) Authenticator {
+	return &basicAuthenticator{username: username, password: password}
+}
+
+// NewAPIKeyAuthenticator returns an Authenticator which sends the API key in the given header.
+func NewAPIKeyAuthenticator(header, apiKey string) Authenticator {
+	if header == "" {
+		header = DefaultAPIKeyHeader
+	}
+
+	return &apiKeyAuthenticator{header: header, apiKey: apiKey}
+}
+
+// NewOAuth2Authenticator returns an Authenticator which sends bearer tokens obtained by the OAuth2
+// client credentials flow. The token is kept by the Authenticator, and requested again once it expires,
+// so that it lives as long as the signer which owns the Authenticator.
+func NewOAuth2Authenticator(config OAuth2Config) Authenticator {
+	return &oauth2Authenticator{
+		config: clientcredentials.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			TokenURL:     config.TokenURL,
+			Scopes:       config.Scopes,
+		},
+	}
+}
+
+// Headers implements Authenticator.
+func (a *bearerAuthenticator) Headers(context.Context, httpClient.Client) (map[string][]string, error) {
+	return map[string][]string{authorizationHeaderKey: {fmt.Sprintf(authorizationToken, a.token)}}, nil
+}
+
+// Headers implements Authenticator.
+func (a *basicAuthenticator) Headers(context.Context, httpClient.Client) (map[string][]string, error) {
+	credentials := base64.StdEncoding.EncodeToString([]byte(a.username + ":" + a.password))
+	return map[string][]string{authorizationHeaderKey: {fmt.Sprintf(basicAuthorization, credentials)}}, nil
+}
+
+// Headers implements Authenticator.
+func (a *apiKeyAuthenticator) Headers(context.Context, httpClient.Client) (map[string][]string, error) {
+	return map[string][]string{a.header: {a.apiKey}}, nil
+}
+
+// Headers implements Authenticator. An expired token is requested again within the context of the
+// request, through the client of the request, so that the token request is rate limited and recorded
+// in the audit log along with it. The bodies of token requests are recorded at most hashed, as they
+// carry the credentials and the token.
+func (a *oauth2Authenticator) Headers(ctx context.Context, client httpClient.Client) (map[string][]string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.token.Valid() {
+		tokenCtx := httpClient.WithRedactedAuditBodies(ctx)
+		if client != nil {
+			tokenCtx = context.WithValue(tokenCtx, oauth2.HTTPClient, &http.Client{Transport: httpClient.NewTransport(client)})
+		}
+
+		token, err := a.config.Token(tokenCtx)
+		if err != nil {
+			return nil, err
+		}
+		a.token = token
+	}
+
+	return map[string][]string{authorizationHeaderKey: {fmt.Sprintf(authorizationToken, a.token.AccessToken)}}, nil
+}
+
+// CredentialHeaders implements Authenticator.
+func (a *bearerAuthenticator) CredentialHeaders() []string {
+	return []string{authorizationHeaderKey}
+}
+
+// CredentialHeaders implements Authenticator.
+func (a *basicAuthenticator) CredentialHeaders() []string {
+	return []string{authorizationHeaderKey}
+}
+
+// CredentialHeaders implements Authenticator.
+func (a *apiKeyAuthenticator) CredentialHeaders() []string {
+	return []string{a.header}
+}
+
+// CredentialHeaders implements Authenticator.
+func (a *oauth2Authenticator) CredentialHeaders() []string {
+	return []string{authorizationHeaderKey}
+}
@@ -0,0 +1,185 @@
+package cert
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testUsername     = "user"
+	testPassword     = "password"
+	testAPIKey       = "dummy-api-key"
+	testAPIKeyHeader = "X-Custom-Key"
+	testClientSecret = "dummy-client-secret"
+	testAccessToken  = "dummy-access-token"
+)
+
+func TestAuthenticatorHeaders(t *testing.T) {
+	type args struct {
+		authenticator Authenticator
+	}
+	type want struct {
+		headers map[string][]string
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldSendBearerToken": {
+			args: args{
+				authenticator: NewBearerAuthenticator(testToken),
+			},
+			want: want{
+				headers: map[string][]string{authorizationHeaderKey: {"Bearer " + testToken}},
+			},
+		},
+		"ShouldSendBasicAuth": {
+			args: args{
+				authenticator: NewBasicAuthenticator(testUsername, testPassword),
+			},
+			want: want{
+				headers: map[string][]string{
+					authorizationHeaderKey: {"Basic " + base64.StdEncoding.EncodeToString([]byte(testUsername+":"+testPassword))},
+				},
+			},
+		},
+		"ShouldSendAPIKeyInDefaultHeader": {
+			args: args{
+				authenticator: NewAPIKeyAuthenticator("", testAPIKey),
+			},
+			want: want{
+				headers: map[string][]string{DefaultAPIKeyHeader: {testAPIKey}},
+			},
+		},
+		"ShouldSendAPIKeyInCustomHeader": {
+			args: args{
+				authenticator: NewAPIKeyAuthenticator(testAPIKeyHeader, testAPIKey),
+			},
+			want: want{
+				headers: map[string][]string{testAPIKeyHeader: {testAPIKey}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			headers, err := tc.args.authenticator.Headers(context.Background(), testHTTPClient)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.headers, headers)
+			for key := range tc.want.headers {
+				assert.Contains(t, tc.args.authenticator.CredentialHeaders(), key)
+			}
+		})
+	}
+}
+
+func TestOAuth2Authenticator(t *testing.T) {
+	type args struct {
+		clientID  string
+		expiresIn int
+		status    int
+	}
+	type want struct {
+		err           bool
+		tokenRequests int32
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldCacheToken": {
+			args: args{
+				clientID:  "cached-client",
+				expiresIn: 3600,
+				status:    http.StatusOK,
+			},
+			want: want{
+				tokenRequests: 1,
+			},
+		},
+		"ShouldRefreshExpiredToken": {
+			args: args{
+				clientID:  "expiring-client",
+				expiresIn: 1,
+				status:    http.StatusOK,
+			},
+			want: want{
+				tokenRequests: 2,
+			},
+		},
+		"ShouldFailOnRejectedCredentials": {
+			args: args{
+				clientID: "rejected-client",
+				status:   http.StatusUnauthorized,
+			},
+			want: want{
+				err: true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var tokenRequests int32
+			tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&tokenRequests, 1)
+				clientID, clientSecret, ok := r.BasicAuth()
+				if tc.args.status != http.StatusOK || !ok || clientID != tc.args.clientID || clientSecret != testClientSecret {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.Header().Set(contentTypeHeaderKey, contentTypeJSONKey)
+				_, _ = fmt.Fprintf(w, `{"access_token":%q,"token_type":"Bearer","expires_in":%d}`, testAccessToken, tc.args.expiresIn)
+			}))
+			defer tokenServer.Close()
+
+			config := OAuth2Config{TokenURL: tokenServer.URL, ClientID: tc.args.clientID, ClientSecret: testClientSecret}
+			authenticator := NewOAuth2Authenticator(config)
+
+			for i := 0; i < 2; i++ {
+				headers, err := authenticator.Headers(context.Background(), testHTTPClient)
+				if tc.want.err {
+					assert.Error(t, err)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, map[string][]string{authorizationHeaderKey: {"Bearer " + testAccessToken}}, headers)
+			}
+
+			assert.Equal(t, tc.want.tokenRequests, atomic.LoadInt32(&tokenRequests))
+		})
+	}
+}
+
+func TestOAuth2AuthenticatorTokenRequests(t *testing.T) {
+	var tokenRequests int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&tokenRequests, 1)
+		w.Header().Set(contentTypeHeaderKey, contentTypeJSONKey)
+		_, _ = fmt.Fprintf(w, `{"access_token":%q,"token_type":"Bearer","expires_in":3600}`, testAccessToken)
+	}))
+	defer tokenServer.Close()
+
+	config := OAuth2Config{TokenURL: tokenServer.URL, ClientID: "client", ClientSecret: testClientSecret}
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewOAuth2Authenticator(config).Headers(cancelledCtx, testHTTPClient)
+	assert.ErrorIs(t, err, context.Canceled, "expected the token request to be sent within the context of the request")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&tokenRequests))
+
+	for i := 0; i < 2; i++ {
+		_, err := NewOAuth2Authenticator(config).Headers(context.Background(), testHTTPClient)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&tokenRequests), "expected every Authenticator to request its own token")
+}
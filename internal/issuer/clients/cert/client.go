@@ -10,19 +10,33 @@ import (
 
 // Client is the interface to interact with Cert API service.
 type Client interface {
-	// PostCertificate sends a POST request to cert to create a new certificate and returns the GUID.
-	PostCertificate(ctx context.Context, log logr.Logger, csrBytes []byte) (string, error)
+	// PostCertificate sends a POST request to cert to create a new certificate and returns the GUID
+	// and the polling interval of the signing task. The parameters are sent along with the CSR.
+	PostCertificate(ctx context.Context, log logr.Logger, csrBytes []byte, parameters map[string]string) (PostCertificateResponse, error)
 
 	// DownloadCertificate downloads a certificate from the Cert API.
 	DownloadCertificate(ctx context.Context, log logr.Logger, guid string) (DownloadCertificateResponse, error)
+
+	// RevokeCertificate sends a request to the Cert API to revoke the certificate with the given serial number.
+	RevokeCertificate(ctx context.Context, log logr.Logger, serialNumber string) error
+
+	// CheckHealth sends an authenticated request to the health check endpoint of the Cert API.
+	CheckHealth(ctx context.Context, log logr.Logger) error
 }
 
 type client struct {
-	localHttpClient  httpClient.Client
-	apiEndpoint      string
-	downloadEndpoint string
-	form             string
-	token            string
+	hClient             *http.Client
+	localHttpClient     httpClient.Client
+	apiEndpoint         string
+	downloadEndpoint    string
+	healthCheckEndpoint string
+	revokeEndpoint      string
+	form                string
+	token               string
+	authenticator       Authenticator
+	requestProfile      RequestProfile
+	responseMapping     ResponseMapping
+	rateLimiter         *httpClient.RateLimiter
 }
 
 // NewClient returns a new client.
@@ -32,6 +46,18 @@ func NewClient(options ...func(*client)) Client {
 		o(cl)
 	}
 
+	if cl.hClient != nil {
+		var credentialHeaders []string
+		if cl.authenticator != nil {
+			credentialHeaders = cl.authenticator.CredentialHeaders()
+		}
+		cl.localHttpClient = httpClient.NewClient(*cl.hClient, credentialHeaders...)
+	}
+
+	if cl.rateLimiter != nil && cl.localHttpClient != nil {
+		cl.localHttpClient = httpClient.NewRateLimitedClient(cl.localHttpClient, cl.rateLimiter)
+	}
+
 	return cl
 }
 
@@ -49,17 +75,40 @@ func WithDownloadEndpoint(downloadEndpoint string) func(*client) {
 	}
 }
 
-// WithToken returns a client with the Token field populated.
+// WithHealthCheckEndpoint returns a client with the Health Check Endpoint field populated.
+func WithHealthCheckEndpoint(healthCheckEndpoint string) func(*client) {
+	return func(c *client) {
+		c.healthCheckEndpoint = healthCheckEndpoint
+	}
+}
+
+// WithRevokeEndpoint returns a client with the Revoke Endpoint field populated.
+func WithRevokeEndpoint(revokeEndpoint string) func(*client) {
+	return func(c *client) {
+		c.revokeEndpoint = revokeEndpoint
+	}
+}
+
+// WithToken returns a client with the Token field populated, which authenticates with the token as a bearer token.
 func WithToken(token string) func(*client) {
 	return func(c *client) {
 		c.token = token
+		c.authenticator = NewBearerAuthenticator(token)
+	}
+}
+
+// WithAuthenticator returns a client with the Authenticator field populated.
+func WithAuthenticator(authenticator Authenticator) func(*client) {
+	return func(c *client) {
+		c.authenticator = authenticator
 	}
 }
 
-// WithHTTPClient returns a client with the API Endpoint field populated.
+// WithHTTPClient returns a client which sends its requests using the HTTP client. The credential headers
+// of the Authenticator of the client are redacted in the audit log.
 func WithHTTPClient(hClient http.Client) func(*client) {
 	return func(c *client) {
-		c.localHttpClient = httpClient.NewClient(hClient)
+		c.hClient = &hClient
 	}
 }
 
@@ -69,3 +118,24 @@ func WithForm(form string) func(*client) {
 		c.form = form
 	}
 }
+
+// WithRequestProfile returns a client with the Request Profile field populated.
+func WithRequestProfile(requestProfile RequestProfile) func(*client) {
+	return func(c *client) {
+		c.requestProfile = requestProfile
+	}
+}
+
+// WithResponseMapping returns a client with the Response Mapping field populated.
+func WithResponseMapping(responseMapping ResponseMapping) func(*client) {
+	return func(c *client) {
+		c.responseMapping = responseMapping
+	}
+}
+
+// WithRateLimiter returns a client which waits for the Rate Limiter before each request.
+func WithRateLimiter(rateLimiter *httpClient.RateLimiter) func(*client) {
+	return func(c *client) {
+		c.rateLimiter = rateLimiter
+	}
+}
@@ -12,6 +12,8 @@ import (
 var (
 	testAPIEndpoint      = "https://api.endpoint"
 	testDownloadEndpoint = "https://download.endpoint"
+	testHealthEndpoint   = "health"
+	testRevokeEndpoint   = "revoke"
 	testToken            = "dummy-token"
 	testForm             = "form"
 
@@ -22,6 +24,8 @@ var (
 const (
 	withAPIEndpoint      = "WithAPIEndpoint"
 	withDownloadEndpoint = "WithDownloadEndpoint"
+	withHealthEndpoint   = "WithHealthCheckEndpoint"
+	withRevokeEndpoint   = "WithRevokeEndpoint"
 	withForm             = "WithForm"
 	withToken            = "WithToken"
 	withHTTPClient       = "WithHTTPClient"
@@ -58,6 +62,24 @@ func TestClientOptions(t *testing.T) {
 				value: testDownloadEndpoint,
 			},
 		},
+		"ShouldCreateSuccessfullyWithHealthCheckEndpoint": {
+			args: args{
+				name:   withHealthEndpoint,
+				option: WithHealthCheckEndpoint(testHealthEndpoint),
+			},
+			want: want{
+				value: testHealthEndpoint,
+			},
+		},
+		"ShouldCreateSuccessfullyWithRevokeEndpoint": {
+			args: args{
+				name:   withRevokeEndpoint,
+				option: WithRevokeEndpoint(testRevokeEndpoint),
+			},
+			want: want{
+				value: testRevokeEndpoint,
+			},
+		},
 		"ShouldCreateSuccessfullyWithToken": {
 			args: args{
 				name:   withToken,
@@ -99,6 +121,14 @@ func TestClientOptions(t *testing.T) {
 				if diff := cmp.Diff(tc.want.value, cl.(*client).downloadEndpoint, test.EquateErrors()); diff != "" {
 					t.Fatalf("createClient(...): -want error, +got error: %v", diff)
 				}
+			case withHealthEndpoint:
+				if diff := cmp.Diff(tc.want.value, cl.(*client).healthCheckEndpoint, test.EquateErrors()); diff != "" {
+					t.Fatalf("createClient(...): -want error, +got error: %v", diff)
+				}
+			case withRevokeEndpoint:
+				if diff := cmp.Diff(tc.want.value, cl.(*client).revokeEndpoint, test.EquateErrors()); diff != "" {
+					t.Fatalf("createClient(...): -want error, +got error: %v", diff)
+				}
 			case withToken:
 				if diff := cmp.Diff(tc.want.value, cl.(*client).token, test.EquateErrors()); diff != "" {
 					t.Fatalf("createClient(...): -want error, +got error: %v", diff)
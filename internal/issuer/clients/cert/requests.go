@@ -8,21 +8,32 @@ import (
 	"fmt"
 	"mime/multipart"
 	"net/http"
+	neturl "net/url"
+	"sort"
+	"strconv"
+	"time"
 
+	httpClient "github.com/dana-team/cert-external-issuer/internal/issuer/clients/http"
 	"github.com/dana-team/cert-external-issuer/internal/issuer/jsonutil"
 	"github.com/go-logr/logr"
 )
 
 const (
-	authorizationToken      = "Bearer %v"
-	authorizationHeaderKey  = "Authorization"
-	acceptHeaderKey         = "accept"
-	acceptHeaderValue       = "application/json"
-	contentTypeHeaderKey    = "Content-Type"
-	contentTypeTextPlainKey = "text/plain"
-	csrKey                  = "csr"
-	fieldName               = "file"
-	fileName                = "csr.pem"
+	authorizationToken         = "Bearer %v"
+	authorizationHeaderKey     = "Authorization"
+	acceptHeaderKey            = "accept"
+	acceptHeaderValue          = "application/json"
+	contentTypeHeaderKey       = "Content-Type"
+	contentTypeTextPlainKey    = "text/plain"
+	contentTypeJSONKey         = "application/json"
+	contentTypePKCS10Key       = "application/pkcs10"
+	defaultCSRPath             = "csr"
+	defaultMultipartField      = "file"
+	defaultJSONField           = "csr"
+	defaultFileName            = "csr.pem"
+	defaultTaskIDPath          = "taskId"
+	defaultCertificatePath     = "data"
+	defaultPollingIntervalPath = "pollingInterval"
 )
 
 var (
@@ -30,38 +41,132 @@ var (
 	errFailedToUnmarshalBody       = errors.New("failed to unmarshal response body")
 	errPostToCertFailed            = errors.New("POST to cert failed")
 	errDownloadToCertFailed        = errors.New("download request to Cert API failed")
+	errHealthCheckToCertFailed     = errors.New("health check request to Cert API failed")
+	errRevokeToCertFailed          = errors.New("revoke request to Cert API failed")
+	errFailedToMarshalBody         = errors.New("failed to marshal request body")
 	errFailedToCreateMultipartForm = errors.New("failed to create multipart form")
+	errFailedToEncodeRequest       = errors.New("failed to encode request body")
+	errUnsupportedRequestEncoding  = errors.New("unsupported request encoding")
+	errFailedToAuthenticate        = errors.New("failed to authenticate")
+	errInvalidPollingInterval      = errors.New("invalid polling interval")
 )
 
 // PostCertificate sends a POST request to the Cert API to create a new certificate and returns the GUID.
-func (c *client) PostCertificate(ctx context.Context, logger logr.Logger, csrBytes []byte) (string, error) {
-	url := fmt.Sprintf("%s%s", c.apiEndpoint, csrKey)
+// The parameters are sent along with the static parameters of the request profile, overriding them.
+// Any 2xx response accepts the CSR, such as a 202 Accepted response of an API which issues certificates
+// asynchronously. The polling interval is read from the response body, falling back to the Retry-After header.
+func (c *client) PostCertificate(ctx context.Context, logger logr.Logger, csrBytes []byte, parameters map[string]string) (PostCertificateResponse, error) {
+	url, requestBytes, requestContentType, err := c.encodeCSRRequest(csrBytes, parameters)
+	if err != nil {
+		return PostCertificateResponse{}, fmt.Errorf("%w: %v", errFailedToEncodeRequest, err)
+	}
+
+	headers, err := c.constructHeaders(ctx, requestContentType)
+	if err != nil {
+		return PostCertificateResponse{}, fmt.Errorf("%w: %w", errPostToCertFailed, err)
+	}
 
-	requestBytes, formContentType, err := createMultipartForm(csrBytes)
+	response, err := c.localHttpClient.SendRequest(ctx, logger, http.MethodPost, url, requestBytes, headers)
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", errFailedToCreateMultipartForm, err)
+		return PostCertificateResponse{}, fmt.Errorf("%w: %w", errPostToCertFailed, err)
 	}
 
-	response, err := c.localHttpClient.SendRequest(ctx, logger, http.MethodPost, url, requestBytes, c.constructHeaders(formContentType))
+	guid, err := parseResponseBody(response.Body, valueOrDefault(c.responseMapping.TaskIDPath, defaultTaskIDPath))
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", errPostToCertFailed, err)
+		return PostCertificateResponse{}, fmt.Errorf("%w: %v", errFailedToUnmarshalBody, err)
 	}
 
-	var responseBody PostCertificateResponse
-	if err = parseResponseBody(response.Body, &responseBody); err != nil {
-		return "", fmt.Errorf("%w: %v", errFailedToUnmarshalBody, err)
+	// the CSR was accepted, so an invalid polling interval must not fail the submission
+	pollingInterval, err := parsePollingInterval(response.Body, valueOrDefault(c.responseMapping.PollingIntervalPath, defaultPollingIntervalPath))
+	if err != nil {
+		logger.Info("Ignoring polling interval of the Cert API", "reason", err.Error())
+	}
+	if pollingInterval == 0 {
+		pollingInterval, _ = httpClient.RetryAfter(response.Headers)
+	}
+
+	return PostCertificateResponse{TaskID: guid, PollingInterval: pollingInterval}, nil
+}
+
+// parsePollingInterval returns the polling interval at the given dot-separated JSON path of the response
+// body, given either in seconds or as a duration such as "30s". It returns zero if there is no value at the path.
+func parsePollingInterval(body string, path string) (time.Duration, error) {
+	value, err := jsonutil.GetString(body, path)
+	if err != nil || value == "" {
+		return 0, err
+	}
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if seconds <= 0 {
+			return 0, fmt.Errorf("%w: %q", errInvalidPollingInterval, value)
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("%w: %q", errInvalidPollingInterval, value)
+	}
+
+	return interval, nil
+}
+
+// encodeCSRRequest encodes the CSR and the parameters according to the request profile of the client.
+// It returns the URL to post the request to, the request body and its content type.
+func (c *client) encodeCSRRequest(csrBytes []byte, requestParameters map[string]string) (string, []byte, string, error) {
+	profile := c.requestProfile
+	parameters := mergeParameters(profile.Parameters, requestParameters)
+
+	path := profile.Path
+	if path == "" {
+		path = defaultCSRPath
 	}
+	url := fmt.Sprintf("%s%s", c.apiEndpoint, path)
 
-	return responseBody.Guid, nil
+	switch profile.Encoding {
+	case "", RequestEncodingMultipart:
+		fieldName := valueOrDefault(profile.FieldName, defaultMultipartField)
+		fileName := valueOrDefault(profile.FileName, defaultFileName)
+		requestBytes, contentType, err := createMultipartForm(csrBytes, fieldName, fileName, parameters)
+		if err != nil {
+			return "", nil, "", fmt.Errorf("%w: %v", errFailedToCreateMultipartForm, err)
+		}
+		return url, requestBytes, contentType, nil
+	case RequestEncodingJSON:
+		fieldName := valueOrDefault(profile.FieldName, defaultJSONField)
+		requestBytes, err := createJSONBody(csrBytes, fieldName, parameters)
+		if err != nil {
+			return "", nil, "", fmt.Errorf("%w: %v", errFailedToMarshalBody, err)
+		}
+		return url, requestBytes, contentTypeJSONKey, nil
+	case RequestEncodingPEM:
+		if len(parameters) > 0 {
+			query := neturl.Values{}
+			for key, value := range parameters {
+				query.Set(key, value)
+			}
+			url = fmt.Sprintf("%s?%s", url, query.Encode())
+		}
+		return url, csrBytes, contentTypePKCS10Key, nil
+	default:
+		return "", nil, "", fmt.Errorf("%w: %q", errUnsupportedRequestEncoding, profile.Encoding)
+	}
 }
 
-// createMultipartForm prepares a multipart/form-data request body containing the provided CSR bytes.
+// createMultipartForm prepares a multipart/form-data request body containing the provided CSR bytes
+// in the given field and the parameters as additional form fields.
 // It returns the encoded form as a byte slice, the content type for the HTTP header, and any error encountered.
-func createMultipartForm(csrBytes []byte) ([]byte, string, error) {
+func createMultipartForm(csrBytes []byte, fieldName, fileName string, parameters map[string]string) ([]byte, string, error) {
 	var requestBody bytes.Buffer
 	writer := multipart.NewWriter(&requestBody)
 
-	part, err := writer.CreateFormFile(fieldName, fileName) // Use an appropriate field name and filename
+	for _, key := range sortedKeys(parameters) {
+		if err := writer.WriteField(key, parameters[key]); err != nil {
+			return []byte{}, "", err
+		}
+	}
+
+	part, err := writer.CreateFormFile(fieldName, fileName)
 	if err != nil {
 		return []byte{}, "", err
 	}
@@ -77,37 +182,151 @@ func createMultipartForm(csrBytes []byte) ([]byte, string, error) {
 	return requestBody.Bytes(), writer.FormDataContentType(), nil
 }
 
-// DownloadCertificate sends a GET request and downloads a certificate from the Cert API.
+// createJSONBody prepares a JSON object holding the parameters and the provided CSR bytes in the given field.
+func createJSONBody(csrBytes []byte, fieldName string, parameters map[string]string) ([]byte, error) {
+	body := make(map[string]string, len(parameters)+1)
+	for key, value := range parameters {
+		body[key] = value
+	}
+	body[fieldName] = string(csrBytes)
+
+	return json.Marshal(body)
+}
+
+// mergeParameters returns the static parameters with the request parameters added, which take precedence.
+func mergeParameters(static, request map[string]string) map[string]string {
+	if len(request) == 0 {
+		return static
+	}
+
+	parameters := make(map[string]string, len(static)+len(request))
+	for key, value := range static {
+		parameters[key] = value
+	}
+	for key, value := range request {
+		parameters[key] = value
+	}
+
+	return parameters
+}
+
+// sortedKeys returns the keys of the map in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// valueOrDefault returns the value if it is set, and otherwise the default value.
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+
+	return value
+}
+
+// DownloadCertificate sends a GET request and downloads a certificate from the Cert API. An Accepted
+// response means that the certificate is still being issued, so it is returned as a *StatusError like
+// the other responses which carry no certificate.
 func (c *client) DownloadCertificate(ctx context.Context, logger logr.Logger, guid string) (DownloadCertificateResponse, error) {
 	url := fmt.Sprintf("%s%s%s%s", c.apiEndpoint, guid, c.downloadEndpoint, c.form)
 
-	response, err := c.localHttpClient.SendRequest(ctx, logger, http.MethodGet, url, []byte{}, c.constructHeaders(contentTypeTextPlainKey))
+	headers, err := c.constructHeaders(ctx, contentTypeTextPlainKey)
+	if err != nil {
+		return DownloadCertificateResponse{}, fmt.Errorf("%w: %w", errDownloadToCertFailed, err)
+	}
+
+	response, err := c.localHttpClient.SendRequest(ctx, logger, http.MethodGet, url, []byte{}, headers)
 	if err != nil {
-		return DownloadCertificateResponse{}, fmt.Errorf("%w: %v", errDownloadToCertFailed, err)
+		return DownloadCertificateResponse{}, fmt.Errorf("%w: %w", errDownloadToCertFailed, err)
 	}
 
-	var responseBody DownloadCertificateResponse
-	if err = parseResponseBody(response.Body, &responseBody); err != nil {
+	if response.StatusCode == http.StatusAccepted {
+		statusErr := &httpClient.StatusError{StatusCode: response.StatusCode, Headers: response.Headers, Body: response.Body}
+		return DownloadCertificateResponse{}, fmt.Errorf("%w: %w", errDownloadToCertFailed, statusErr)
+	}
+
+	data, err := parseResponseBody(response.Body, valueOrDefault(c.responseMapping.CertificatePath, defaultCertificatePath))
+	if err != nil {
 		return DownloadCertificateResponse{}, fmt.Errorf("%w: %v", errFailedToUnmarshalBody, err)
 	}
 
-	return responseBody, nil
+	return DownloadCertificateResponse{Data: data}, nil
 }
 
-// parseResponseBody parses the response body received from the Cert API.
-func parseResponseBody(body string, response interface{}) error {
+// RevokeCertificate sends a POST request to the revoke endpoint of the Cert API to revoke the certificate
+// with the given serial number.
+func (c *client) RevokeCertificate(ctx context.Context, logger logr.Logger, serialNumber string) error {
+	url := fmt.Sprintf("%s%s", c.apiEndpoint, c.revokeEndpoint)
+
+	requestBytes, err := json.Marshal(RevokeCertificateRequest{SerialNumber: serialNumber})
+	if err != nil {
+		return fmt.Errorf("%w: %v", errFailedToMarshalBody, err)
+	}
+
+	headers, err := c.constructHeaders(ctx, contentTypeJSONKey)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errRevokeToCertFailed, err)
+	}
+
+	if _, err := c.localHttpClient.SendRequest(ctx, logger, http.MethodPost, url, requestBytes, headers); err != nil {
+		return fmt.Errorf("%w: %w", errRevokeToCertFailed, err)
+	}
+
+	return nil
+}
+
+// CheckHealth sends an authenticated GET request to the health check endpoint of the Cert API.
+func (c *client) CheckHealth(ctx context.Context, logger logr.Logger) error {
+	url := fmt.Sprintf("%s%s", c.apiEndpoint, c.healthCheckEndpoint)
+
+	headers, err := c.constructHeaders(ctx, contentTypeTextPlainKey)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errHealthCheckToCertFailed, err)
+	}
+
+	if _, err := c.localHttpClient.SendRequest(ctx, logger, http.MethodGet, url, []byte{}, headers); err != nil {
+		return fmt.Errorf("%w: %w", errHealthCheckToCertFailed, err)
+	}
+
+	return nil
+}
+
+// parseResponseBody parses the response body received from the Cert API and returns the value
+// at the given dot-separated JSON path.
+func parseResponseBody(body string, path string) (string, error) {
 	if !jsonutil.IsJSONString(body) {
-		return errBodyIsNotJson
+		return "", errBodyIsNotJson
 	}
 
-	return json.Unmarshal([]byte(body), response)
+	return jsonutil.GetString(body, path)
 }
 
-// constructHeaders returns a map containing the needed headers for communicating with the Cert API.
-func (c *client) constructHeaders(contentTypeValue string) map[string][]string {
-	return map[string][]string{
-		authorizationHeaderKey: {fmt.Sprintf(authorizationToken, c.token)},
-		acceptHeaderKey:        {acceptHeaderValue},
-		contentTypeHeaderKey:   {contentTypeValue},
+// constructHeaders returns a map containing the needed headers for communicating with the Cert API,
+// including the headers of the authenticator of the client.
+func (c *client) constructHeaders(ctx context.Context, contentTypeValue string) (map[string][]string, error) {
+	headers := map[string][]string{
+		acceptHeaderKey:      {acceptHeaderValue},
+		contentTypeHeaderKey: {contentTypeValue},
+	}
+
+	if c.authenticator == nil {
+		return headers, nil
 	}
+
+	authHeaders, err := c.authenticator.Headers(ctx, c.localHttpClient)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToAuthenticate, err)
+	}
+
+	for key, values := range authHeaders {
+		headers[key] = values
+	}
+
+	return headers, nil
 }
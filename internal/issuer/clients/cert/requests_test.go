@@ -2,9 +2,12 @@ package cert
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/jarcoal/httpmock"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -18,21 +21,36 @@ var (
 )
 
 const (
-	postCert         = "PostCert"
-	getCert          = "GetCert"
-	failGetCert      = "FailGetCert"
-	invalidResponse  = "InvalidResponse"
-	testURL          = "https://test.com/"
-	downloadEndpoint = "download/"
-	guid             = "12345678/"
+	postCert              = "PostCert"
+	failPostCert          = "FailPostCert"
+	postCertTaskID        = "PostCertTaskID"
+	testCSRPath           = "v2/requests"
+	testFieldName         = "request"
+	testFileName          = "request.csr"
+	testParameterKey      = "profile"
+	testParameterValue    = "server"
+	testDurationParameter = "validitySeconds"
+	testDurationValue     = "3600"
+	getCert               = "GetCert"
+	failGetCert           = "FailGetCert"
+	invalidResponse       = "InvalidResponse"
+	checkHealth           = "CheckHealth"
+	failCheckHealth       = "FailCheckHealth"
+	revokeCert            = "RevokeCert"
+	failRevokeCert        = "FailRevokeCert"
+	testSerialNumber      = "1a2b3c"
+	testURL               = "https://test.com/"
+	downloadEndpoint      = "download/"
+	guid                  = "12345678/"
 )
 
 func TestPostCertificate(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 	type args struct {
-		name   string
-		client Client
+		name       string
+		client     Client
+		parameters map[string]string
 	}
 	type want struct {
 		method    string
@@ -48,6 +66,156 @@ func TestPostCertificate(t *testing.T) {
 				name:   postCert,
 				client: NewClient(WithAPIEndpoint(testURL), WithHTTPClient(hClient)),
 			},
+			want: want{
+				method: http.MethodPost,
+				path:   fmt.Sprintf("%s%s", testURL, "csr"),
+				responder: func(request *http.Request) (*http.Response, error) {
+					file, header, err := request.FormFile(defaultMultipartField)
+					if err != nil || header.Filename != defaultFileName {
+						return httpmock.NewStringResponse(http.StatusBadRequest, ""), nil
+					}
+					defer func() { _ = file.Close() }()
+					return httpmock.NewJsonResponse(http.StatusOK, nil)
+				},
+			},
+		},
+		"ShouldSendMultipartFormWithProfile": {
+			args: args{
+				name: postCert,
+				client: NewClient(
+					WithAPIEndpoint(testURL),
+					WithRequestProfile(RequestProfile{
+						Encoding:   RequestEncodingMultipart,
+						FieldName:  testFieldName,
+						FileName:   testFileName,
+						Parameters: map[string]string{testParameterKey: testParameterValue},
+					}),
+					WithHTTPClient(hClient),
+				),
+			},
+			want: want{
+				method: http.MethodPost,
+				path:   fmt.Sprintf("%s%s", testURL, "csr"),
+				responder: func(request *http.Request) (*http.Response, error) {
+					file, header, err := request.FormFile(testFieldName)
+					if err != nil || header.Filename != testFileName || request.FormValue(testParameterKey) != testParameterValue {
+						return httpmock.NewStringResponse(http.StatusBadRequest, ""), nil
+					}
+					defer func() { _ = file.Close() }()
+					return httpmock.NewJsonResponse(http.StatusOK, nil)
+				},
+			},
+		},
+		"ShouldSendJSONBody": {
+			args: args{
+				name: postCert,
+				client: NewClient(
+					WithAPIEndpoint(testURL),
+					WithRequestProfile(RequestProfile{
+						Encoding:   RequestEncodingJSON,
+						Path:       testCSRPath,
+						Parameters: map[string]string{testParameterKey: testParameterValue},
+					}),
+					WithHTTPClient(hClient),
+				),
+			},
+			want: want{
+				method: http.MethodPost,
+				path:   fmt.Sprintf("%s%s", testURL, testCSRPath),
+				responder: func(request *http.Request) (*http.Response, error) {
+					var body map[string]string
+					if err := json.NewDecoder(request.Body).Decode(&body); err != nil ||
+						request.Header.Get(contentTypeHeaderKey) != contentTypeJSONKey ||
+						body[defaultJSONField] != string(exampleBytes) ||
+						body[testParameterKey] != testParameterValue {
+						return httpmock.NewStringResponse(http.StatusBadRequest, ""), nil
+					}
+					return httpmock.NewJsonResponse(http.StatusOK, nil)
+				},
+			},
+		},
+		"ShouldSendRequestParametersOverridingProfile": {
+			args: args{
+				name: postCert,
+				client: NewClient(
+					WithAPIEndpoint(testURL),
+					WithRequestProfile(RequestProfile{
+						Encoding:   RequestEncodingJSON,
+						Parameters: map[string]string{testParameterKey: testParameterValue, testDurationParameter: "1"},
+					}),
+					WithHTTPClient(hClient),
+				),
+				parameters: map[string]string{testDurationParameter: testDurationValue},
+			},
+			want: want{
+				method: http.MethodPost,
+				path:   fmt.Sprintf("%s%s", testURL, "csr"),
+				responder: func(request *http.Request) (*http.Response, error) {
+					var body map[string]string
+					if err := json.NewDecoder(request.Body).Decode(&body); err != nil ||
+						body[testParameterKey] != testParameterValue ||
+						body[testDurationParameter] != testDurationValue {
+						return httpmock.NewStringResponse(http.StatusBadRequest, ""), nil
+					}
+					return httpmock.NewJsonResponse(http.StatusOK, nil)
+				},
+			},
+		},
+		"ShouldSendPEMBody": {
+			args: args{
+				name: postCert,
+				client: NewClient(
+					WithAPIEndpoint(testURL),
+					WithRequestProfile(RequestProfile{
+						Encoding:   RequestEncodingPEM,
+						Parameters: map[string]string{testParameterKey: testParameterValue},
+					}),
+					WithHTTPClient(hClient),
+				),
+			},
+			want: want{
+				method: http.MethodPost,
+				path:   fmt.Sprintf("%s%s", testURL, "csr"),
+				responder: func(request *http.Request) (*http.Response, error) {
+					body, err := io.ReadAll(request.Body)
+					if err != nil ||
+						request.Header.Get(contentTypeHeaderKey) != contentTypePKCS10Key ||
+						request.URL.Query().Get(testParameterKey) != testParameterValue ||
+						string(body) != string(exampleBytes) {
+						return httpmock.NewStringResponse(http.StatusBadRequest, ""), nil
+					}
+					return httpmock.NewJsonResponse(http.StatusOK, nil)
+				},
+			},
+		},
+		"ShouldReadTaskIDWithResponseMapping": {
+			args: args{
+				name: postCertTaskID,
+				client: NewClient(
+					WithAPIEndpoint(testURL),
+					WithResponseMapping(ResponseMapping{TaskIDPath: "request.id"}),
+					WithHTTPClient(hClient),
+				),
+			},
+			want: want{
+				method: http.MethodPost,
+				path:   fmt.Sprintf("%s%s", testURL, "csr"),
+				responder: func(request *http.Request) (*http.Response, error) {
+					return httpmock.NewJsonResponse(http.StatusOK, map[string]interface{}{
+						"request": map[string]string{"id": guid},
+					})
+				},
+			},
+		},
+		"ShouldFailOnUnsupportedEncoding": {
+			args: args{
+				name: failPostCert,
+				client: NewClient(
+					WithAPIEndpoint(testURL),
+					WithRequestProfile(RequestProfile{Encoding: "xml"}),
+					WithHTTPClient(hClient),
+				),
+			},
 			want: want{
 				method: http.MethodPost,
 				path:   fmt.Sprintf("%s%s", testURL, "csr"),
@@ -66,10 +234,120 @@ func TestPostCertificate(t *testing.T) {
 
 			switch tc.args.name {
 			case postCert:
-				_, err := cl.PostCertificate(ctx, log, exampleBytes)
+				_, err := cl.PostCertificate(ctx, log, exampleBytes, tc.args.parameters)
 				if err != nil {
 					t.Fatalf("got error: %v", err)
 				}
+			case postCertTaskID:
+				response, err := cl.PostCertificate(ctx, log, exampleBytes, nil)
+				if err != nil {
+					t.Fatalf("got error: %v", err)
+				}
+				if response.TaskID != guid {
+					t.Fatalf("expected task ID %v, got %v", guid, response.TaskID)
+				}
+			case failPostCert:
+				_, err := cl.PostCertificate(ctx, log, exampleBytes, nil)
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+			}
+		})
+	}
+}
+
+func TestPostCertificatePollingInterval(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	type args struct {
+		responseMapping ResponseMapping
+		body            map[string]interface{}
+		retryAfter      string
+		statusCode      int
+	}
+	type want struct {
+		pollingInterval time.Duration
+	}
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldReadPollingIntervalInSeconds": {
+			args: args{
+				body: map[string]interface{}{"taskId": guid, "pollingInterval": 15},
+			},
+			want: want{pollingInterval: 15 * time.Second},
+		},
+		"ShouldReadPollingIntervalAsDuration": {
+			args: args{
+				responseMapping: ResponseMapping{PollingIntervalPath: "request.poll"},
+				body:            map[string]interface{}{"taskId": guid, "request": map[string]string{"poll": "1m30s"}},
+			},
+			want: want{pollingInterval: 90 * time.Second},
+		},
+		"ShouldFallBackToRetryAfterHeader": {
+			args: args{
+				body:       map[string]interface{}{"taskId": guid},
+				retryAfter: "20",
+			},
+			want: want{pollingInterval: 20 * time.Second},
+		},
+		"ShouldReadRetryAfterHeaderOfAcceptedResponse": {
+			args: args{
+				body:       map[string]interface{}{"taskId": guid},
+				retryAfter: "20",
+				statusCode: http.StatusAccepted,
+			},
+			want: want{pollingInterval: 20 * time.Second},
+		},
+		"ShouldPreferPollingIntervalOverRetryAfterHeader": {
+			args: args{
+				body:       map[string]interface{}{"taskId": guid, "pollingInterval": "5s"},
+				retryAfter: "20",
+			},
+			want: want{pollingInterval: 5 * time.Second},
+		},
+		"ShouldIgnoreInvalidPollingInterval": {
+			args: args{
+				body: map[string]interface{}{"taskId": guid, "pollingInterval": "soon"},
+			},
+		},
+		"ShouldReturnZeroWithoutPollingInterval": {
+			args: args{
+				body: map[string]interface{}{"taskId": guid},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			httpmock.Reset()
+			httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s%s", testURL, "csr"), func(*http.Request) (*http.Response, error) {
+				statusCode := http.StatusOK
+				if tc.args.statusCode != 0 {
+					statusCode = tc.args.statusCode
+				}
+				response, err := httpmock.NewJsonResponse(statusCode, tc.args.body)
+				if tc.args.retryAfter != "" {
+					response.Header.Set("Retry-After", tc.args.retryAfter)
+				}
+				return response, err
+			})
+
+			cl := NewClient(
+				WithAPIEndpoint(testURL),
+				WithResponseMapping(tc.args.responseMapping),
+				WithHTTPClient(hClient),
+			)
+
+			response, err := cl.PostCertificate(ctx, log, exampleBytes, nil)
+			if err != nil {
+				t.Fatalf("got error: %v", err)
+			}
+			if response.TaskID != guid {
+				t.Fatalf("expected task ID %v, got %v", guid, response.TaskID)
+			}
+			if response.PollingInterval != tc.want.pollingInterval {
+				t.Fatalf("expected polling interval %v, got %v", tc.want.pollingInterval, response.PollingInterval)
 			}
 		})
 	}
@@ -110,6 +388,27 @@ func TestDownloadCertificate(t *testing.T) {
 				},
 			},
 		},
+		"ShouldDownloadWithResponseMapping": {
+			args: args{
+				name: getCert,
+				client: NewClient(
+					WithAPIEndpoint(testURL),
+					WithForm(testForm),
+					WithDownloadEndpoint(downloadEndpoint),
+					WithResponseMapping(ResponseMapping{CertificatePath: "result.certificates.0"}),
+					WithHTTPClient(hClient),
+				),
+			},
+			want: want{
+				method: http.MethodGet,
+				path:   fmt.Sprintf("%s%s%s%s", testURL, guid, downloadEndpoint, testForm),
+				responder: func(request *http.Request) (*http.Response, error) {
+					return httpmock.NewJsonResponse(http.StatusOK, map[string]interface{}{
+						"result": map[string]interface{}{"certificates": []string{string(exampleBytes)}},
+					})
+				},
+			},
+		},
 		"ShouldNotDownloadOnError": {
 			args: args{
 				name: failGetCert,
@@ -128,6 +427,24 @@ func TestDownloadCertificate(t *testing.T) {
 				},
 			},
 		},
+		"ShouldNotDownloadWhileAccepted": {
+			args: args{
+				name: failGetCert,
+				client: NewClient(
+					WithAPIEndpoint(testURL),
+					WithForm(testForm),
+					WithDownloadEndpoint(downloadEndpoint),
+					WithHTTPClient(hClient),
+				),
+			},
+			want: want{
+				method: http.MethodGet,
+				path:   fmt.Sprintf("%s%s%s%s", testURL, guid, downloadEndpoint, testForm),
+				responder: func(request *http.Request) (*http.Response, error) {
+					return httpmock.NewJsonResponse(http.StatusAccepted, map[string]string{"status": "pending"})
+				},
+			},
+		},
 		"ShouldNotDownloadOnInvalidResponse": {
 			args: args{
 				name: invalidResponse,
@@ -180,3 +497,153 @@ func TestDownloadCertificate(t *testing.T) {
 		})
 	}
 }
+
+func TestRevokeCertificate(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	type args struct {
+		name   string
+		client Client
+	}
+	type want struct {
+		method    string
+		path      string
+		responder httpmock.Responder
+	}
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldSendPOSTRequestSuccessfully": {
+			args: args{
+				name: revokeCert,
+				client: NewClient(
+					WithAPIEndpoint(testURL),
+					WithRevokeEndpoint(testRevokeEndpoint),
+					WithHTTPClient(hClient),
+				),
+			},
+			want: want{
+				method: http.MethodPost,
+				path:   fmt.Sprintf("%s%s", testURL, testRevokeEndpoint),
+				responder: func(request *http.Request) (*http.Response, error) {
+					var body RevokeCertificateRequest
+					if err := json.NewDecoder(request.Body).Decode(&body); err != nil || body.SerialNumber != testSerialNumber {
+						return httpmock.NewStringResponse(http.StatusBadRequest, ""), nil
+					}
+					return httpmock.NewStringResponse(http.StatusOK, ""), nil
+				},
+			},
+		},
+		"ShouldFailOnError": {
+			args: args{
+				name: failRevokeCert,
+				client: NewClient(
+					WithAPIEndpoint(testURL),
+					WithRevokeEndpoint(testRevokeEndpoint),
+					WithHTTPClient(hClient),
+				),
+			},
+			want: want{
+				method: http.MethodPost,
+				path:   fmt.Sprintf("%s%s", testURL, testRevokeEndpoint),
+				responder: func(request *http.Request) (*http.Response, error) {
+					return httpmock.NewStringResponse(http.StatusInternalServerError, ""), nil
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			httpmock.Reset()
+			cl := tc.args.client
+
+			httpmock.RegisterResponder(tc.want.method, tc.want.path, tc.want.responder)
+
+			switch tc.args.name {
+			case revokeCert:
+				if err := cl.RevokeCertificate(ctx, log, testSerialNumber); err != nil {
+					t.Fatalf("got error: %v", err)
+				}
+			case failRevokeCert:
+				if err := cl.RevokeCertificate(ctx, log, testSerialNumber); err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+			}
+		})
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	type args struct {
+		name   string
+		client Client
+	}
+	type want struct {
+		method    string
+		path      string
+		responder httpmock.Responder
+	}
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldSendGETRequestSuccessfully": {
+			args: args{
+				name: checkHealth,
+				client: NewClient(
+					WithAPIEndpoint(testURL),
+					WithHealthCheckEndpoint(testHealthEndpoint),
+					WithHTTPClient(hClient),
+				),
+			},
+			want: want{
+				method: http.MethodGet,
+				path:   fmt.Sprintf("%s%s", testURL, testHealthEndpoint),
+				responder: func(request *http.Request) (*http.Response, error) {
+					return httpmock.NewStringResponse(http.StatusOK, ""), nil
+				},
+			},
+		},
+		"ShouldFailOnUnauthorized": {
+			args: args{
+				name: failCheckHealth,
+				client: NewClient(
+					WithAPIEndpoint(testURL),
+					WithHealthCheckEndpoint(testHealthEndpoint),
+					WithHTTPClient(hClient),
+				),
+			},
+			want: want{
+				method: http.MethodGet,
+				path:   fmt.Sprintf("%s%s", testURL, testHealthEndpoint),
+				responder: func(request *http.Request) (*http.Response, error) {
+					return httpmock.NewStringResponse(http.StatusUnauthorized, ""), nil
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			httpmock.Reset()
+			cl := tc.args.client
+
+			httpmock.RegisterResponder(tc.want.method, tc.want.path, tc.want.responder)
+
+			switch tc.args.name {
+			case checkHealth:
+				if err := cl.CheckHealth(ctx, log); err != nil {
+					t.Fatalf("got error: %v", err)
+				}
+			case failCheckHealth:
+				if err := cl.CheckHealth(ctx, log); err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+			}
+		})
+	}
+}
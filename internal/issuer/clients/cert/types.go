@@ -1,11 +1,52 @@
 package cert
 
-// PostCertificateResponse represents the structure of the JSON response body for obtaining a certificate.
+import "time"
+
+const (
+	// RequestEncodingMultipart uploads the CSR as a file in a multipart form.
+	RequestEncodingMultipart = "multipart"
+
+	// RequestEncodingJSON sends the CSR as a string field of a JSON object.
+	RequestEncodingJSON = "json"
+
+	// RequestEncodingPEM sends the PEM encoded CSR as a raw "application/pkcs10" body.
+	RequestEncodingPEM = "pem"
+)
+
+// RequestProfile specifies how CSRs are encoded in the POST requests to the Cert API.
+// Empty fields are replaced by the defaults of the encoding.
+type RequestProfile struct {
+	Encoding   string
+	Path       string
+	FieldName  string
+	FileName   string
+	Parameters map[string]string
+}
+
+// ResponseMapping specifies the dot-separated JSON paths of the values read from the responses
+// of the Cert API. Empty paths are replaced by the defaults.
+type ResponseMapping struct {
+	TaskIDPath          string
+	CertificatePath     string
+	PollingIntervalPath string
+}
+
+// PostCertificateResponse represents the response received when posting a CSR.
 type PostCertificateResponse struct {
-	Guid string `json:"taskId"`
+	// TaskID is the identifier of the signing task.
+	TaskID string
+
+	// PollingInterval is the interval at which the Cert API asked for the task to be polled, or zero
+	// if it did not ask for one.
+	PollingInterval time.Duration
 }
 
 // DownloadCertificateResponse represents the response received when downloading a certificate.
 type DownloadCertificateResponse struct {
 	Data string `json:"data"`
 }
+
+// RevokeCertificateRequest represents the structure of the JSON request body for revoking a certificate.
+type RevokeCertificateRequest struct {
+	SerialNumber string `json:"serialNumber"`
+}
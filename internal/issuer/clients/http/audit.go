@@ -0,0 +1,287 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	// BodyCaptureNone records only the size of request and response bodies.
+	BodyCaptureNone = "none"
+
+	// BodyCaptureHashed records the size and the SHA-256 hash of request and response bodies.
+	BodyCaptureHashed = "hashed"
+
+	// BodyCaptureFull records request and response bodies in full, along with their size and hash.
+	BodyCaptureFull = "full"
+)
+
+const (
+	// redactedValue replaces the values of headers and query parameters which may carry credentials.
+	redactedValue = "REDACTED"
+
+	// base64Encoding is the encoding of captured bodies which are not valid UTF-8.
+	base64Encoding = "base64"
+)
+
+var errInvalidBodyCapture = errors.New("invalid body capture")
+
+// sensitiveNameFragments are the fragments of the names of headers and query parameters whose values
+// are redacted, matched case-insensitively.
+var sensitiveNameFragments = []string{"auth", "token", "secret", "password", "api-key", "apikey", "access-key", "cookie", "session"}
+
+// auditLog is the AuditLog with which clients record their exchanges.
+var auditLog atomic.Pointer[AuditLog]
+
+func init() {
+	auditLog.Store(&AuditLog{bodyCapture: BodyCaptureHashed})
+}
+
+// AuditLog records every exchange with the Cert API, with the credentials redacted. Entries are
+// logged through the logger of the exchange, and written as JSON lines to a sink, if one is given.
+type AuditLog struct {
+	bodyCapture string
+
+	mu   sync.Mutex
+	sink io.Writer
+}
+
+// AuditEntry is the record of an exchange with the Cert API.
+type AuditEntry struct {
+	Time            time.Time           `json:"time"`
+	Keys            map[string]string   `json:"keys,omitempty"`
+	Method          string              `json:"method"`
+	URL             string              `json:"url"`
+	RequestHeaders  map[string][]string `json:"requestHeaders,omitempty"`
+	RequestBody     *AuditBody          `json:"requestBody,omitempty"`
+	StatusCode      int                 `json:"statusCode,omitempty"`
+	ResponseHeaders map[string][]string `json:"responseHeaders,omitempty"`
+	ResponseBody    *AuditBody          `json:"responseBody,omitempty"`
+	DurationMillis  int64               `json:"durationMillis"`
+	Error           string              `json:"error,omitempty"`
+
+	bodyCapture string
+}
+
+// AuditBody is the record of a request or response body, captured according to the body capture of the AuditLog.
+type AuditBody struct {
+	Size     int    `json:"size"`
+	SHA256   string `json:"sha256,omitempty"`
+	Content  string `json:"content,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type auditKeysContextKey struct{}
+
+type redactedAuditBodiesContextKey struct{}
+
+// NewAuditLog returns an AuditLog which captures bodies according to bodyCapture, one of BodyCaptureNone,
+// BodyCaptureHashed and BodyCaptureFull, and which also writes its entries to sink, unless it is nil.
+func NewAuditLog(bodyCapture string, sink io.Writer) (*AuditLog, error) {
+	switch bodyCapture {
+	case BodyCaptureNone, BodyCaptureHashed, BodyCaptureFull:
+	default:
+		return nil, fmt.Errorf("%w %q, expected one of %q, %q or %q", errInvalidBodyCapture, bodyCapture, BodyCaptureNone, BodyCaptureHashed, BodyCaptureFull)
+	}
+
+	return &AuditLog{bodyCapture: bodyCapture, sink: sink}, nil
+}
+
+// SetAuditLog sets the AuditLog with which all clients record their exchanges. By default, exchanges
+// are only logged, with hashed bodies.
+func SetAuditLog(a *AuditLog) {
+	auditLog.Store(a)
+}
+
+// WithAuditKeys returns a copy of ctx whose exchanges are correlated in the audit log to the given
+// key and value pairs, such as the CertificateRequest and the issuer on whose behalf they are made.
+func WithAuditKeys(ctx context.Context, keysAndValues ...string) context.Context {
+	keys := map[string]string{}
+	for key, value := range auditKeys(ctx) {
+		keys[key] = value
+	}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		keys[keysAndValues[i]] = keysAndValues[i+1]
+	}
+
+	return context.WithValue(ctx, auditKeysContextKey{}, keys)
+}
+
+// WithRedactedAuditBodies returns a copy of ctx whose exchanges carry credentials in their bodies, such
+// as OAuth2 token requests, so that their bodies are recorded at most hashed, whatever the body capture.
+func WithRedactedAuditBodies(ctx context.Context) context.Context {
+	return context.WithValue(ctx, redactedAuditBodiesContextKey{}, true)
+}
+
+// auditKeys returns the audit keys of ctx.
+func auditKeys(ctx context.Context) map[string]string {
+	keys, _ := ctx.Value(auditKeysContextKey{}).(map[string]string)
+	return keys
+}
+
+// newAuditEntry returns the AuditEntry of a request, before it is sent.
+func (a *AuditLog) newAuditEntry(ctx context.Context, request *http.Request, body []byte, redactedHeaders []string) *AuditEntry {
+	bodyCapture := a.bodyCapture
+	if redacted, _ := ctx.Value(redactedAuditBodiesContextKey{}).(bool); redacted && bodyCapture == BodyCaptureFull {
+		bodyCapture = BodyCaptureHashed
+	}
+
+	return &AuditEntry{
+		Time:           time.Now(),
+		Keys:           auditKeys(ctx),
+		Method:         request.Method,
+		URL:            redactURL(request.URL),
+		RequestHeaders: redactHeaders(request.Header, redactedHeaders),
+		RequestBody:    captureBody(bodyCapture, body),
+		bodyCapture:    bodyCapture,
+	}
+}
+
+// complete adds the response, or the error with which the exchange failed, to the entry.
+func (a *AuditLog) complete(entry *AuditEntry, response *http.Response, body []byte, redactedHeaders []string, err error) {
+	entry.DurationMillis = time.Since(entry.Time).Milliseconds()
+	if response != nil {
+		entry.StatusCode = response.StatusCode
+		entry.ResponseHeaders = redactHeaders(response.Header, redactedHeaders)
+		entry.ResponseBody = captureBody(entry.bodyCapture, body)
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+}
+
+// record logs the entry and writes it to the sink. A failure to write to the sink is logged, and
+// does not fail the exchange.
+func (a *AuditLog) record(logger logr.Logger, entry *AuditEntry) {
+	keysAndValues := []interface{}{"method", entry.Method, "url", entry.URL, "statusCode", entry.StatusCode, "durationMillis", entry.DurationMillis}
+	for key, value := range entry.Keys {
+		keysAndValues = append(keysAndValues, key, value)
+	}
+	if len(entry.RequestHeaders) > 0 {
+		keysAndValues = append(keysAndValues, "requestHeaders", entry.RequestHeaders)
+	}
+	if entry.RequestBody != nil {
+		keysAndValues = append(keysAndValues, "requestBody", entry.RequestBody)
+	}
+	if entry.ResponseBody != nil {
+		keysAndValues = append(keysAndValues, "responseBody", entry.ResponseBody)
+	}
+	if entry.Error != "" {
+		keysAndValues = append(keysAndValues, "error", entry.Error)
+	}
+	logger.WithName("audit").Info("Cert API exchange", keysAndValues...)
+
+	if a.sink == nil {
+		return
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		logger.Error(err, "Failed to encode audit entry")
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.sink.Write(append(line, '\n')); err != nil {
+		logger.Error(err, "Failed to write audit entry")
+	}
+}
+
+// captureBody returns the record of a body according to the body capture, or nil if the body is empty.
+func captureBody(bodyCapture string, body []byte) *AuditBody {
+	if len(body) == 0 {
+		return nil
+	}
+
+	auditBody := &AuditBody{Size: len(body)}
+	if bodyCapture == BodyCaptureNone {
+		return auditBody
+	}
+
+	hash := sha256.Sum256(body)
+	auditBody.SHA256 = hex.EncodeToString(hash[:])
+	if bodyCapture != BodyCaptureFull {
+		return auditBody
+	}
+
+	if utf8.Valid(body) {
+		auditBody.Content = string(body)
+	} else {
+		auditBody.Content = base64.StdEncoding.EncodeToString(body)
+		auditBody.Encoding = base64Encoding
+	}
+
+	return auditBody
+}
+
+// redactHeaders returns a copy of the headers in which the values of the redacted headers, and of other
+// headers which may carry credentials, are redacted.
+func redactHeaders(headers http.Header, redactedHeaders []string) map[string][]string {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	redacted := make(map[string][]string, len(headers))
+	for name, values := range headers {
+		if isSensitiveName(name) || containsHeader(redactedHeaders, name) {
+			redacted[name] = []string{redactedValue}
+			continue
+		}
+		redacted[name] = values
+	}
+
+	return redacted
+}
+
+// redactURL returns the URL with its password and the values of query parameters which may carry credentials redacted.
+func redactURL(u *url.URL) string {
+	redacted := *u
+	query := u.Query()
+	for name := range query {
+		if isSensitiveName(name) {
+			query.Set(name, redactedValue)
+			redacted.RawQuery = query.Encode()
+		}
+	}
+
+	return redacted.Redacted()
+}
+
+// isSensitiveName returns true if the name of a header or query parameter suggests that it carries credentials.
+func isSensitiveName(name string) bool {
+	name = strings.ToLower(name)
+	for _, fragment := range sensitiveNameFragments {
+		if strings.Contains(name, fragment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsHeader returns true if the header names contain the name, compared case-insensitively.
+func containsHeader(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,164 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testAuditRequestBody  = "-----BEGIN CERTIFICATE REQUEST-----"
+	testAuditResponseBody = `{"taskId": "task-1"}`
+	testCredentialHeader  = "X-Cert-Key"
+)
+
+func TestAuditLog(t *testing.T) {
+	httpmock.Activate()
+	defer SetAuditLog(auditLog.Load())
+
+	requestHash := sha256.Sum256([]byte(testAuditRequestBody))
+	responseHash := sha256.Sum256([]byte(testAuditResponseBody))
+
+	type params struct {
+		bodyCapture      string
+		url              string
+		status           int
+		credentialHeader string
+	}
+	type want struct {
+		entry AuditEntry
+	}
+
+	cases := map[string]struct {
+		params params
+		want   want
+	}{
+		"ShouldRecordOnlyBodySizes": {
+			params: params{bodyCapture: BodyCaptureNone, url: testURL, status: http.StatusOK},
+			want: want{
+				entry: AuditEntry{
+					URL:          testURL,
+					StatusCode:   http.StatusOK,
+					RequestBody:  &AuditBody{Size: len(testAuditRequestBody)},
+					ResponseBody: &AuditBody{Size: len(testAuditResponseBody)},
+				},
+			},
+		},
+		"ShouldRecordHashedBodies": {
+			params: params{bodyCapture: BodyCaptureHashed, url: testURL, status: http.StatusOK},
+			want: want{
+				entry: AuditEntry{
+					URL:          testURL,
+					StatusCode:   http.StatusOK,
+					RequestBody:  &AuditBody{Size: len(testAuditRequestBody), SHA256: hex.EncodeToString(requestHash[:])},
+					ResponseBody: &AuditBody{Size: len(testAuditResponseBody), SHA256: hex.EncodeToString(responseHash[:])},
+				},
+			},
+		},
+		"ShouldRecordFullBodies": {
+			params: params{bodyCapture: BodyCaptureFull, url: testURL, status: http.StatusOK},
+			want: want{
+				entry: AuditEntry{
+					URL:          testURL,
+					StatusCode:   http.StatusOK,
+					RequestBody:  &AuditBody{Size: len(testAuditRequestBody), SHA256: hex.EncodeToString(requestHash[:]), Content: testAuditRequestBody},
+					ResponseBody: &AuditBody{Size: len(testAuditResponseBody), SHA256: hex.EncodeToString(responseHash[:]), Content: testAuditResponseBody},
+				},
+			},
+		},
+		"ShouldRecordFailedExchange": {
+			params: params{bodyCapture: BodyCaptureNone, url: testURL, status: http.StatusServiceUnavailable},
+			want: want{
+				entry: AuditEntry{
+					URL:          testURL,
+					StatusCode:   http.StatusServiceUnavailable,
+					RequestBody:  &AuditBody{Size: len(testAuditRequestBody)},
+					ResponseBody: &AuditBody{Size: len(testAuditResponseBody)},
+				},
+			},
+		},
+		"ShouldRedactCredentialsInURL": {
+			params: params{bodyCapture: BodyCaptureNone, url: scheme + "://user:password@" + testName + ".com?access_token=secret&profile=server", status: http.StatusOK},
+			want: want{
+				entry: AuditEntry{
+					URL:          scheme + "://user:xxxxx@" + testName + ".com?access_token=" + redactedValue + "&profile=server",
+					StatusCode:   http.StatusOK,
+					RequestBody:  &AuditBody{Size: len(testAuditRequestBody)},
+					ResponseBody: &AuditBody{Size: len(testAuditResponseBody)},
+				},
+			},
+		},
+		"ShouldRedactCredentialHeaders": {
+			params: params{bodyCapture: BodyCaptureNone, url: testURL, status: http.StatusOK, credentialHeader: testCredentialHeader},
+			want: want{
+				entry: AuditEntry{
+					URL:            testURL,
+					StatusCode:     http.StatusOK,
+					RequestHeaders: map[string][]string{testCredentialHeader: {redactedValue}},
+					RequestBody:    &AuditBody{Size: len(testAuditRequestBody)},
+					ResponseBody:   &AuditBody{Size: len(testAuditResponseBody)},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var sink bytes.Buffer
+			audit, err := NewAuditLog(tc.params.bodyCapture, &sink)
+			assert.NoError(t, err)
+			SetAuditLog(audit)
+
+			httpmock.Reset()
+			httpmock.RegisterNoResponder(func(*http.Request) (*http.Response, error) {
+				response := httpmock.NewStringResponse(tc.params.status, testAuditResponseBody)
+				response.Header.Set("Set-Cookie", "session=secret")
+				response.Header.Set(headerKey, headerValue)
+				return response, nil
+			})
+
+			headers := map[string][]string{
+				"Authorization": {"Bearer secret"},
+				"X-API-Key":     {"secret"},
+				headerKey:       {headerValue},
+			}
+			var redactedHeaders []string
+			if tc.params.credentialHeader != "" {
+				headers[tc.params.credentialHeader] = []string{"secret"}
+				redactedHeaders = append(redactedHeaders, tc.params.credentialHeader)
+			}
+			auditCtx := WithAuditKeys(ctx, "certificateRequest", "default/cr-1", "issuer", "Issuer/default/issuer-1")
+			_, _ = NewClient(hClient, redactedHeaders...).SendRequest(auditCtx, logger, http.MethodPost, tc.params.url, []byte(testAuditRequestBody), headers)
+
+			var entry AuditEntry
+			assert.NoError(t, json.Unmarshal(sink.Bytes(), &entry))
+			assert.Equal(t, map[string]string{"certificateRequest": "default/cr-1", "issuer": "Issuer/default/issuer-1"}, entry.Keys)
+			assert.Equal(t, http.MethodPost, entry.Method)
+			assert.Equal(t, tc.want.entry.URL, entry.URL)
+			assert.Equal(t, tc.want.entry.StatusCode, entry.StatusCode)
+			assert.Equal(t, tc.want.entry.RequestBody, entry.RequestBody)
+			assert.Equal(t, tc.want.entry.ResponseBody, entry.ResponseBody)
+			assert.Equal(t, []string{redactedValue}, entry.RequestHeaders["Authorization"])
+			assert.Equal(t, []string{redactedValue}, entry.RequestHeaders["X-Api-Key"])
+			assert.Equal(t, []string{headerValue}, entry.RequestHeaders[headerKey])
+			for name, values := range tc.want.entry.RequestHeaders {
+				assert.Equal(t, values, entry.RequestHeaders[name])
+			}
+			assert.Equal(t, []string{redactedValue}, entry.ResponseHeaders["Set-Cookie"])
+			assert.Equal(t, []string{headerValue}, entry.ResponseHeaders[headerKey])
+			assert.NotContains(t, sink.String(), "secret")
+		})
+	}
+}
+
+func TestNewAuditLog(t *testing.T) {
+	_, err := NewAuditLog("partial", nil)
+	assert.True(t, errors.Is(err, errInvalidBodyCapture), "unexpected error: %v", err)
+}
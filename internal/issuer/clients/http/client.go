@@ -3,23 +3,34 @@ package http
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/dana-team/cert-external-issuer/internal/issuer/jsonutil"
 	"github.com/go-logr/logr"
-
-	"github.com/pkg/errors"
 )
 
+// maxBodyExcerptBytes is the maximum number of bytes of the body of a failed response kept in a StatusError.
+const maxBodyExcerptBytes = 1024
+
+// retryAfterHeaderKey is the header in which a server tells how long to wait before retrying a request.
+const retryAfterHeaderKey = "Retry-After"
+
+// messageFields are the fields of a JSON error body which hold the error message, in order of preference.
+var messageFields = []string{"message", "error_description", "error", "detail", "title"}
+
 // Client is the interface to interact with HTTP
 type Client interface {
 	SendRequest(ctx context.Context, logger logr.Logger, method string, url string, body []byte, headers map[string][]string) (resp Response, err error)
 }
 
 type client struct {
-	HTTPClient http.Client
+	HTTPClient      http.Client
+	RedactedHeaders []string
 }
 
 // Response represents an HTTP response.
@@ -29,15 +40,79 @@ type Response struct {
 	StatusCode int
 }
 
-// Request represents an HTTP request.
-type Request struct {
-	Method  string              `json:"method"`
-	Body    string              `json:"body,omitempty"`
-	URL     string              `json:"url"`
-	Headers map[string][]string `json:"headers,omitempty"`
+// StatusError is returned when a response has an unexpected status code. It holds the headers of the
+// response and an excerpt of its body, so that the error reported by the server is not lost.
+type StatusError struct {
+	StatusCode int
+	Headers    map[string][]string
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	status := fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if message := e.Message(); message != "" {
+		return fmt.Sprintf("%s: %s", status, message)
+	}
+
+	return status
+}
+
+// Message returns the error message of the response. It is the message field of a JSON body, such as
+// "message" or "error", and otherwise the body itself.
+func (e *StatusError) Message() string {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(e.Body), &fields); err == nil {
+		for _, field := range messageFields {
+			if message, ok := fields[field].(string); ok && message != "" {
+				return message
+			}
+		}
+	}
+
+	return strings.TrimSpace(e.Body)
+}
+
+// RetryAfter returns the delay requested by the Retry-After header of the response.
+func (e *StatusError) RetryAfter() (time.Duration, bool) {
+	return RetryAfter(e.Headers)
+}
+
+// RetryAfter returns the delay requested by a Retry-After header, which is given either in seconds or
+// as an HTTP date. It returns false if there is no such header, or if it does not request a delay.
+func RetryAfter(headers map[string][]string) (time.Duration, bool) {
+	value := strings.TrimSpace(http.Header(headers).Get(retryAfterHeaderKey))
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, seconds > 0
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	delay := time.Until(date)
+	return delay, delay > 0
+}
+
+// newStatusError returns a StatusError of the response, keeping up to maxBodyExcerptBytes of its body.
+func newStatusError(response *http.Response, body []byte) *StatusError {
+	if len(body) > maxBodyExcerptBytes {
+		body = body[:maxBodyExcerptBytes]
+	}
+
+	return &StatusError{
+		StatusCode: response.StatusCode,
+		Headers:    response.Header,
+		Body:       strings.ToValidUTF8(string(body), ""),
+	}
 }
 
-// SendRequest sends an HTTP request and returns the response.
+// SendRequest sends an HTTP request and returns the response. Any 2xx response is successful, while other
+// responses are returned as a *StatusError.
 func (c *client) SendRequest(ctx context.Context, logger logr.Logger, method string, url string, body []byte, headers map[string][]string) (Response, error) {
 	request, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(body))
 
@@ -51,40 +126,45 @@ func (c *client) SendRequest(ctx context.Context, logger logr.Logger, method str
 		}
 	}
 
-	response, err := c.HTTPClient.Do(request)
-	logger.Info(fmt.Sprint("http request sent: ", jsonutil.ToJSON(Request{URL: url, Body: string(body), Method: method})))
+	audit := auditLog.Load()
+	entry := audit.newAuditEntry(ctx, request, body, c.RedactedHeaders)
 
+	response, err := c.HTTPClient.Do(request)
 	if err != nil {
-		return Response{}, fmt.Errorf("http request to %q failed: %v", url, err)
+		err = fmt.Errorf("http request to %q failed: %w", url, err)
+		audit.complete(entry, nil, nil, c.RedactedHeaders, err)
+		audit.record(logger, entry)
+		return Response{}, err
 	}
+	defer response.Body.Close()
 
 	responseBody, err := io.ReadAll(response.Body)
 	if err != nil {
-		return Response{}, fmt.Errorf("failed reading response body: %v", err)
+		err = fmt.Errorf("failed reading response body: %v", err)
+		audit.complete(entry, response, responseBody, c.RedactedHeaders, err)
+		audit.record(logger, entry)
+		return Response{}, err
 	}
 
-	if response.StatusCode != http.StatusOK {
-		logger.Info(fmt.Sprintf("request failed, method: %v, status code: %v, body: %v", method, response.StatusCode, responseBody))
-		return Response{}, errors.New(http.StatusText(response.StatusCode))
+	audit.complete(entry, response, responseBody, c.RedactedHeaders, nil)
+	audit.record(logger, entry)
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return Response{}, newStatusError(response, responseBody)
 	}
 
-	beautifiedResponse := Response{
+	return Response{
 		Body:       string(responseBody),
 		Headers:    response.Header,
 		StatusCode: response.StatusCode,
-	}
-
-	err = response.Body.Close()
-	if err != nil {
-		return beautifiedResponse, err
-	}
-
-	return beautifiedResponse, nil
+	}, nil
 }
 
-// NewClient returns a new HTTP Client.
-func NewClient(hClient http.Client) Client {
+// NewClient returns a new HTTP Client. The values of the redacted headers, such as the headers which
+// carry the credentials of the client, are redacted in the audit log whatever their names.
+func NewClient(hClient http.Client, redactedHeaders ...string) Client {
 	return &client{
-		HTTPClient: hClient,
+		HTTPClient:      hClient,
+		RedactedHeaders: redactedHeaders,
 	}
 }
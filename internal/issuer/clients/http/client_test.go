@@ -3,10 +3,13 @@ package http
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
@@ -137,6 +140,23 @@ func TestSendRequest(t *testing.T) {
 				},
 			},
 		},
+		"ShouldSucceedWithAcceptedResponse": {
+			params: params{
+				url:       testURL,
+				method:    http.MethodPost,
+				body:      nil,
+				headers:   nil,
+				responder: httpmock.NewStringResponder(http.StatusAccepted, testName),
+			},
+			want: want{
+				errMsg: "",
+				response: Response{
+					StatusCode: http.StatusAccepted,
+					Body:       testName,
+					Headers:    map[string][]string{},
+				},
+			},
+		},
 		"ShouldHandleBadResponse": {
 			params: params{
 				url:     testURL,
@@ -148,7 +168,20 @@ func TestSendRequest(t *testing.T) {
 				},
 			},
 			want: want{
-				errMsg:   "Not Found",
+				errMsg:   "404 Not Found",
+				response: Response{},
+			},
+		},
+		"ShouldKeepErrorMessageOfBadResponse": {
+			params: params{
+				url:       testURL,
+				method:    http.MethodPost,
+				body:      nil,
+				headers:   nil,
+				responder: httpmock.NewStringResponder(http.StatusBadRequest, `{"code": 1001, "message": "CSR key size is too small"}`),
+			},
+			want: want{
+				errMsg:   "400 Bad Request: CSR key size is too small",
 				response: Response{},
 			},
 		},
@@ -183,3 +216,119 @@ func TestSendRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestStatusError(t *testing.T) {
+	type params struct {
+		body string
+	}
+	type want struct {
+		message string
+		body    string
+	}
+
+	cases := map[string]struct {
+		params params
+		want   want
+	}{
+		"ShouldReturnMessageOfJSONBody": {
+			params: params{body: `{"error": "invalid_token", "error_description": "token expired"}`},
+			want: want{
+				message: "token expired",
+				body:    `{"error": "invalid_token", "error_description": "token expired"}`,
+			},
+		},
+		"ShouldReturnPlainTextBody": {
+			params: params{body: "  service is in maintenance\n"},
+			want: want{
+				message: "service is in maintenance",
+				body:    "  service is in maintenance\n",
+			},
+		},
+		"ShouldReturnJSONBodyWithoutMessage": {
+			params: params{body: `{"code": 1001}`},
+			want: want{
+				message: `{"code": 1001}`,
+				body:    `{"code": 1001}`,
+			},
+		},
+		"ShouldTruncateLongBody": {
+			params: params{body: strings.Repeat("x", 2*maxBodyExcerptBytes)},
+			want: want{
+				message: strings.Repeat("x", maxBodyExcerptBytes),
+				body:    strings.Repeat("x", maxBodyExcerptBytes),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			httpmock.Reset()
+			httpmock.RegisterResponder(http.MethodGet, testURL, func(*http.Request) (*http.Response, error) {
+				response := httpmock.NewStringResponse(http.StatusServiceUnavailable, tc.params.body)
+				response.Header.Set(headerKey, headerValue)
+				return response, nil
+			})
+
+			_, err := NewClient(hClient).SendRequest(ctx, logger, http.MethodGet, testURL, nil, nil)
+
+			var statusErr *StatusError
+			if assert.True(t, errors.As(err, &statusErr), "unexpected error: %v", err) {
+				assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+				assert.Equal(t, headerValue, http.Header(statusErr.Headers).Get(headerKey))
+				assert.Equal(t, tc.want.body, statusErr.Body)
+				assert.Equal(t, tc.want.message, statusErr.Message())
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	type params struct {
+		retryAfter string
+	}
+	type want struct {
+		minDelay time.Duration
+		maxDelay time.Duration
+		ok       bool
+	}
+
+	cases := map[string]struct {
+		params params
+		want   want
+	}{
+		"ShouldParseSeconds": {
+			params: params{retryAfter: "120"},
+			want:   want{minDelay: 2 * time.Minute, maxDelay: 2 * time.Minute, ok: true},
+		},
+		"ShouldParseHTTPDate": {
+			params: params{retryAfter: time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)},
+			want:   want{minDelay: 59 * time.Minute, maxDelay: time.Hour, ok: true},
+		},
+		"ShouldIgnoreHTTPDateInThePast": {
+			params: params{retryAfter: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)},
+		},
+		"ShouldIgnoreZeroSeconds": {
+			params: params{retryAfter: "0"},
+		},
+		"ShouldIgnoreInvalidValue": {
+			params: params{retryAfter: "later"},
+		},
+		"ShouldIgnoreMissingHeader": {},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			headers := http.Header{}
+			if tc.params.retryAfter != "" {
+				headers.Set(retryAfterHeaderKey, tc.params.retryAfter)
+			}
+
+			delay, ok := RetryAfter(headers)
+			assert.Equal(t, tc.want.ok, ok)
+			if tc.want.ok {
+				assert.GreaterOrEqual(t, delay, tc.want.minDelay)
+				assert.LessOrEqual(t, delay, tc.want.maxDelay)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package http
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	metricsNamespace    = "cert_external_issuer"
+	rateLimiterLabelKey = "rate_limiter"
+)
+
+var (
+	rateLimiterRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "rate_limiter_requests_total",
+		Help:      "Number of requests to the Cert API which passed through a rate limiter.",
+	}, []string{rateLimiterLabelKey})
+
+	rateLimiterThrottledRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "rate_limiter_throttled_requests_total",
+		Help:      "Number of requests to the Cert API which were delayed by a rate limiter.",
+	}, []string{rateLimiterLabelKey})
+
+	rateLimiterWaitSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "rate_limiter_wait_seconds",
+		Help:      "Time which requests to the Cert API waited for a rate limiter.",
+		Buckets:   []float64{0, 0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{rateLimiterLabelKey})
+)
+
+func init() {
+	metrics.Registry.MustRegister(rateLimiterRequests, rateLimiterThrottledRequests, rateLimiterWaitSeconds)
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter limits the rate of requests sent by the clients which share it, using a token bucket.
+type RateLimiter struct {
+	name    string
+	limiter *rate.Limiter
+}
+
+type rateLimitedClient struct {
+	client      Client
+	rateLimiter *RateLimiter
+}
+
+// NewRateLimiter returns a RateLimiter which allows qps requests per second, and bursts of up to
+// burst requests. The name identifies the rate limiter in metrics.
+func NewRateLimiter(name string, qps float64, burst int) *RateLimiter {
+	return &RateLimiter{name: name, limiter: rate.NewLimiter(rate.Limit(qps), burst)}
+}
+
+// Wait blocks until a request may be sent, or the context is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	reservation := l.limiter.Reserve()
+	delay := reservation.Delay()
+
+	rateLimiterRequests.WithLabelValues(l.name).Inc()
+	if delay == 0 {
+		rateLimiterWaitSeconds.WithLabelValues(l.name).Observe(0)
+		return nil
+	}
+
+	rateLimiterThrottledRequests.WithLabelValues(l.name).Inc()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		rateLimiterWaitSeconds.WithLabelValues(l.name).Observe(delay.Seconds())
+		return nil
+	case <-ctx.Done():
+		reservation.Cancel()
+		return ctx.Err()
+	}
+}
+
+// NewRateLimitedClient returns a Client which waits for the RateLimiter before each request sent by client.
+func NewRateLimitedClient(client Client, rateLimiter *RateLimiter) Client {
+	return &rateLimitedClient{client: client, rateLimiter: rateLimiter}
+}
+
+// SendRequest waits for the rate limiter and then sends the request.
+func (c *rateLimitedClient) SendRequest(ctx context.Context, logger logr.Logger, method string, url string, body []byte, headers map[string][]string) (Response, error) {
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return Response{}, err
+	}
+
+	return c.client.SendRequest(ctx, logger, method, url, body, headers)
+}
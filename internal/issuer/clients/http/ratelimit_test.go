@@ -0,0 +1,93 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+const testRateLimiterName = "test.com"
+
+func TestRateLimitedClient(t *testing.T) {
+	httpmock.Activate()
+	type params struct {
+		qps      float64
+		burst    int
+		requests int
+		timeout  time.Duration
+	}
+	type want struct {
+		minDuration time.Duration
+		throttled   float64
+		err         error
+	}
+
+	cases := map[string]struct {
+		params params
+		want   want
+	}{
+		"ShouldSendBurstWithoutDelay": {
+			params: params{
+				qps:      1,
+				burst:    3,
+				requests: 3,
+			},
+		},
+		"ShouldDelayRequestsAboveBurst": {
+			params: params{
+				qps:      20,
+				burst:    1,
+				requests: 3,
+			},
+			want: want{
+				minDuration: 90 * time.Millisecond,
+				throttled:   2,
+			},
+		},
+		"ShouldStopWaitingWhenContextIsDone": {
+			params: params{
+				qps:      0.1,
+				burst:    1,
+				requests: 2,
+				timeout:  50 * time.Millisecond,
+			},
+			want: want{
+				throttled: 1,
+				err:       context.DeadlineExceeded,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			httpmock.Reset()
+			httpmock.RegisterResponder(http.MethodGet, testURL, httpmock.NewStringResponder(http.StatusOK, ""))
+
+			rateLimiterName := testRateLimiterName + "/" + name
+			c := NewRateLimitedClient(NewClient(hClient), NewRateLimiter(rateLimiterName, tc.params.qps, tc.params.burst))
+
+			requestCtx := ctx
+			if tc.params.timeout != 0 {
+				var cancel context.CancelFunc
+				requestCtx, cancel = context.WithTimeout(ctx, tc.params.timeout)
+				defer cancel()
+			}
+
+			start := time.Now()
+			var err error
+			for i := 0; i < tc.params.requests && err == nil; i++ {
+				_, err = c.SendRequest(requestCtx, logger, http.MethodGet, testURL, nil, nil)
+			}
+
+			assert.ErrorIs(t, err, tc.want.err)
+			assert.GreaterOrEqual(t, time.Since(start), tc.want.minDuration)
+			assert.Equal(t, tc.want.throttled, testutil.ToFloat64(rateLimiterThrottledRequests.WithLabelValues(rateLimiterName)))
+			assert.Equal(t, float64(tc.params.requests), testutil.ToFloat64(rateLimiterRequests.WithLabelValues(rateLimiterName)))
+		})
+	}
+}
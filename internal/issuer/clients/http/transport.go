@@ -0,0 +1,59 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+)
+
+// transport is an http.RoundTripper which sends requests with a Client, so that the requests sent by
+// libraries, such as OAuth2 token requests, are rate limited and recorded in the audit log like the
+// requests to the Cert API.
+type transport struct {
+	client Client
+}
+
+// NewTransport returns an http.RoundTripper which sends requests with the client. Responses with an
+// unexpected status code are returned as responses rather than errors, as http.RoundTripper requires.
+func NewTransport(client Client) http.RoundTripper {
+	return &transport{client: client}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	var body []byte
+	if request.Body != nil {
+		var err error
+		body, err = io.ReadAll(request.Body)
+		_ = request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx := request.Context()
+	response, err := t.client.SendRequest(ctx, logr.FromContextOrDiscard(ctx), request.Method, request.URL.String(), body, request.Header)
+	if err != nil {
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) {
+			return nil, err
+		}
+		response = Response{Body: statusErr.Body, Headers: statusErr.Headers, StatusCode: statusErr.StatusCode}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", response.StatusCode, http.StatusText(response.StatusCode)),
+		StatusCode:    response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header(response.Headers),
+		Body:          io.NopCloser(strings.NewReader(response.Body)),
+		ContentLength: int64(len(response.Body)),
+		Request:       request,
+	}, nil
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransport(t *testing.T) {
+	httpmock.Activate()
+	defer SetAuditLog(auditLog.Load())
+
+	type params struct {
+		status int
+		body   string
+	}
+	type want struct {
+		status int
+		body   string
+	}
+
+	cases := map[string]struct {
+		params params
+		want   want
+	}{
+		"ShouldReturnSuccessfulResponse": {
+			params: params{status: http.StatusOK, body: `{"access_token": "token"}`},
+			want:   want{status: http.StatusOK, body: `{"access_token": "token"}`},
+		},
+		"ShouldReturnUnexpectedStatusAsResponse": {
+			params: params{status: http.StatusUnauthorized, body: `{"error": "invalid_client"}`},
+			want:   want{status: http.StatusUnauthorized, body: `{"error": "invalid_client"}`},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var sink bytes.Buffer
+			audit, err := NewAuditLog(BodyCaptureFull, &sink)
+			assert.NoError(t, err)
+			SetAuditLog(audit)
+
+			httpmock.Reset()
+			httpmock.RegisterResponder(http.MethodPost, testURL, func(request *http.Request) (*http.Response, error) {
+				body, err := io.ReadAll(request.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, "grant_type=client_credentials", string(body))
+				assert.Equal(t, headerValue, request.Header.Get(headerKey))
+				return httpmock.NewStringResponse(tc.params.status, tc.params.body), nil
+			})
+
+			request, err := http.NewRequestWithContext(WithRedactedAuditBodies(ctx), http.MethodPost, testURL, strings.NewReader("grant_type=client_credentials"))
+			assert.NoError(t, err)
+			request.Header.Set(headerKey, headerValue)
+
+			response, err := (&http.Client{Transport: NewTransport(NewClient(hClient))}).Do(request)
+			assert.NoError(t, err)
+			defer response.Body.Close()
+
+			body, err := io.ReadAll(response.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.status, response.StatusCode)
+			assert.Equal(t, tc.want.body, string(body))
+
+			assert.Equal(t, 1, strings.Count(sink.String(), "\n"), "expected the exchange to be recorded in the audit log")
+			assert.NotContains(t, sink.String(), "client_credentials", "expected the request body to be recorded hashed")
+		})
+	}
+}
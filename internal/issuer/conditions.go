@@ -1,7 +1,11 @@
 package issuer
 
 import (
+	"fmt"
+	"strings"
+
 	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	"github.com/dana-team/cert-external-issuer/internal/issuer/signer"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -9,9 +13,21 @@ import (
 // conditionReady represents the fact that a given Issuer condition
 // is in ready state and able to issue certificates.
 // If the `status` of this condition is `False`, CertificateRequest controllers
-// should prevent attempts to sign certificates.
+// should prevent attempts to sign certificates, unless the circuit breaker of the
+// signer backend is not closed, in which case they are kept pending until it closes.
 const conditionReady string = "Ready"
 
+// conditionCircuitBreakerClosed represents the fact that requests are sent to the
+// signer backend of an Issuer. If the `status` of this condition is `False`, the
+// circuit breaker of the signer backend is open or half-open, and CertificateRequests
+// are kept pending until it closes.
+const conditionCircuitBreakerClosed string = "CircuitBreakerClosed"
+
+// conditionEndpointsHealthy represents the fact that every endpoint of the signer backend
+// of an Issuer passed its last health check. If the `status` of this condition is `False`,
+// the unhealthy endpoints are demoted, and requests are failed over to the healthy ones.
+const conditionEndpointsHealthy string = "EndpointsHealthy"
+
 // SetReadyCondition sets a Ready condition.
 func SetReadyCondition(status *certv1alpha1.IssuerStatus, conditionStatus metav1.ConditionStatus, reason, message string) bool {
 	newCondition := metav1.Condition{
@@ -36,3 +52,70 @@ func IsReady(status *certv1alpha1.IssuerStatus) bool {
 	}
 	return false
 }
+
+// SetCircuitBreakerCondition sets a CircuitBreakerClosed condition from the status of the circuit breaker.
+func SetCircuitBreakerCondition(status *certv1alpha1.IssuerStatus, circuitBreakerStatus signer.CircuitBreakerStatus) bool {
+	newCondition := metav1.Condition{
+		Type:    conditionCircuitBreakerClosed,
+		Status:  metav1.ConditionFalse,
+		Reason:  string(circuitBreakerStatus.State),
+		Message: fmt.Sprintf("Requests to the signer backend fail fast after %d consecutive failures", circuitBreakerStatus.Failures),
+	}
+
+	switch circuitBreakerStatus.State {
+	case signer.CircuitBreakerClosed:
+		newCondition.Status = metav1.ConditionTrue
+		newCondition.Message = "Requests are sent to the signer backend"
+	case signer.CircuitBreakerHalfOpen:
+		newCondition.Message = "A probe request is sent to the signer backend"
+	}
+
+	return apimeta.SetStatusCondition(&status.Conditions, newCondition)
+}
+
+// GetCircuitBreakerCondition returns the CircuitBreakerClosed condition from status.
+func GetCircuitBreakerCondition(status *certv1alpha1.IssuerStatus) *metav1.Condition {
+	return apimeta.FindStatusCondition(status.Conditions, conditionCircuitBreakerClosed)
+}
+
+// IsCircuitBreakerOpen returns whether the circuit breaker of the signer backend of an Issuer
+// is open or half-open based on its Condition.
+func IsCircuitBreakerOpen(status *certv1alpha1.IssuerStatus) bool {
+	if c := GetCircuitBreakerCondition(status); c != nil {
+		return c.Status == metav1.ConditionFalse
+	}
+	return false
+}
+
+// SetEndpointsHealthyCondition sets an EndpointsHealthy condition from the health of the endpoints of the signer backend.
+func SetEndpointsHealthyCondition(status *certv1alpha1.IssuerStatus, endpointHealth []signer.EndpointHealth) bool {
+	var unhealthy []string
+	for _, health := range endpointHealth {
+		if !health.Healthy {
+			unhealthy = append(unhealthy, fmt.Sprintf("%s (%s)", health.Endpoint, health.Reason))
+		}
+	}
+
+	newCondition := metav1.Condition{
+		Type:    conditionEndpointsHealthy,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Healthy",
+		Message: fmt.Sprintf("All %d endpoints of the signer backend are healthy", len(endpointHealth)),
+	}
+
+	if len(unhealthy) > 0 {
+		newCondition.Status = metav1.ConditionFalse
+		newCondition.Reason = "Degraded"
+		if len(unhealthy) == len(endpointHealth) {
+			newCondition.Reason = "Unhealthy"
+		}
+		newCondition.Message = fmt.Sprintf("Unhealthy endpoints of the signer backend: %s", strings.Join(unhealthy, ", "))
+	}
+
+	return apimeta.SetStatusCondition(&status.Conditions, newCondition)
+}
+
+// GetEndpointsHealthyCondition returns the EndpointsHealthy condition from status.
+func GetEndpointsHealthyCondition(status *certv1alpha1.IssuerStatus) *metav1.Condition {
+	return apimeta.FindStatusCondition(status.Conditions, conditionEndpointsHealthy)
+}
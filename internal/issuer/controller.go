@@ -26,6 +26,7 @@ import (
 
 	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
 	"github.com/dana-team/cert-external-issuer/internal/common"
+	httpClient "github.com/dana-team/cert-external-issuer/internal/issuer/clients/http"
 	"github.com/dana-team/cert-external-issuer/internal/issuer/signer"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
@@ -34,10 +35,13 @@ import (
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/tools/record"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const (
@@ -47,6 +51,7 @@ const (
 
 var (
 	errGetAuthSecret        = errors.New("failed to get Secret containing Issuer credentials")
+	errGetHTTPConfigData    = errors.New("failed to get Secret or ConfigMap referenced in Issuer HTTPConfig")
 	errHealthCheckerBuilder = errors.New("failed to build the healthchecker")
 	errHealthCheckerCheck   = errors.New("healthcheck failed")
 )
@@ -58,12 +63,14 @@ type IssuerReconciler struct {
 	Scheme                   *runtime.Scheme
 	ClusterResourceNamespace string
 	HealthCheckerBuilder     signer.HealthCheckerBuilder
+	SignerCache              *signer.Cache
 	recorder                 record.EventRecorder
 }
 
 // +kubebuilder:rbac.yaml:groups=cert.dana.io,resources=issuers;clusterissuers,verbs=get;list;watch
 // +kubebuilder:rbac.yaml:groups=cert.dana.io,resources=issuers/status;clusterissuers/status,verbs=get;update;patch
 // +kubebuilder:rbac.yaml:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac.yaml:groups="",resources=configmaps,verbs=get;list;watch
 // +kubebuilder:rbac.yaml:groups="",resources=events,verbs=create;patch
 
 // SetupWithManager sets up the controller with the Manager.
@@ -75,9 +82,90 @@ func (r *IssuerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.recorder = mgr.GetEventRecorderFor(common.EventSource)
 	return ctrl.NewControllerManagedBy(mgr).
 		For(issuerType).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.issuersForSecret)).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.issuersForConfigMap)).
 		Complete(r)
 }
 
+// issuersForSecret returns a request for each issuer which references the Secret, either as its
+// AuthSecret, its client certificate Secret, its CA bundle Secret or its proxy Secret, so that changed
+// credentials are reloaded.
+func (r *IssuerReconciler) issuersForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	return r.issuersReferencing(ctx, secret, func(issuerSpec *certv1alpha1.IssuerSpec) bool {
+		httpConfig := issuerSpec.HTTPConfig
+		return issuerSpec.AuthSecretName == secret.GetName() ||
+			httpConfig.ClientCertificateSecretName == secret.GetName() ||
+			httpConfig.ProxySecretName == secret.GetName() ||
+			(httpConfig.CABundleSecretRef != nil && httpConfig.CABundleSecretRef.Name == secret.GetName())
+	})
+}
+
+// issuersForConfigMap returns a request for each issuer which references the ConfigMap as its
+// CA bundle ConfigMap, so that a changed CA bundle is reloaded.
+func (r *IssuerReconciler) issuersForConfigMap(ctx context.Context, configMap client.Object) []reconcile.Request {
+	return r.issuersReferencing(ctx, configMap, func(issuerSpec *certv1alpha1.IssuerSpec) bool {
+		ref := issuerSpec.HTTPConfig.CABundleConfigMapRef
+		return ref != nil && ref.Name == configMap.GetName()
+	})
+}
+
+// issuersReferencing returns a request for each issuer in the namespace of the object for which
+// references returns true. Only objects in the ClusterResourceNamespace are mapped to ClusterIssuers.
+func (r *IssuerReconciler) issuersReferencing(ctx context.Context, obj client.Object, references func(*certv1alpha1.IssuerSpec) bool) []reconcile.Request {
+	issuerList, err := r.newIssuerList()
+	if err != nil {
+		return nil
+	}
+
+	var listOptions []client.ListOption
+	switch issuerList.(type) {
+	case *certv1alpha1.ClusterIssuerList:
+		if obj.GetNamespace() != r.ClusterResourceNamespace {
+			return nil
+		}
+	default:
+		listOptions = append(listOptions, client.InNamespace(obj.GetNamespace()))
+	}
+
+	if err := r.List(ctx, issuerList, listOptions...); err != nil {
+		log.FromContext(ctx).Error(err, "Unable to list issuers referencing object", "object", client.ObjectKeyFromObject(obj))
+		return nil
+	}
+
+	items, err := meta.ExtractList(issuerList)
+	if err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, item := range items {
+		issuer, ok := item.(client.Object)
+		if !ok {
+			continue
+		}
+
+		issuerSpec, _, err := common.GetIssuerSpecAndStatus(issuer)
+		if err != nil {
+			continue
+		}
+
+		if references(issuerSpec) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(issuer)})
+		}
+	}
+
+	return requests
+}
+
+func (r *IssuerReconciler) newIssuerList() (client.ObjectList, error) {
+	issuerListGVK := certv1alpha1.GroupVersion.WithKind(r.Kind + "List")
+	ro, err := r.Scheme.New(issuerListGVK)
+	if err != nil {
+		return nil, err
+	}
+	return ro.(client.ObjectList), nil
+}
+
 func (r *IssuerReconciler) newIssuer() (client.Object, error) {
 	issuerGVK := certv1alpha1.GroupVersion.WithKind(r.Kind)
 	ro, err := r.Scheme.New(issuerGVK)
@@ -99,6 +187,7 @@ func (r *IssuerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res
 	if err := r.Get(ctx, req.NamespacedName, issuer); err != nil {
 		if apierrors.IsNotFound(err) {
 			logger.Info("Couldn't find Issuer")
+			r.SignerCache.Delete(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, fmt.Errorf("failed to get Issuer: %v", err)
@@ -113,7 +202,7 @@ func (r *IssuerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res
 	// Always attempt to update the Ready condition
 	defer func() {
 		if err != nil {
-			r.report(logger, issuer, issuerStatus, metav1.ConditionFalse, "Error", err)
+			r.reportWithReason(logger, issuer, issuerStatus, metav1.ConditionFalse, conditionReasonFromError(err), "Error", err)
 		}
 		if updateErr := r.Status().Update(ctx, issuer); updateErr != nil {
 			err = utilerrors.NewAggregate([]error{err, updateErr})
@@ -131,13 +220,35 @@ func (r *IssuerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res
 		return ctrl.Result{}, fmt.Errorf("%w, secret name: %s, reason: %v", errGetAuthSecret, issuerSpec.AuthSecretName, err)
 	}
 
-	checker, err := r.HealthCheckerBuilder(issuerSpec, secret.Data)
+	secretData, resourceVersions, err := common.AddHTTPConfigData(r.Client, ctx, issuer, issuerSpec, secret.Data, req.Namespace, r.ClusterResourceNamespace)
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("%w: %v", errHealthCheckerBuilder, err)
+		return ctrl.Result{}, fmt.Errorf("%w: %v", errGetHTTPConfigData, err)
 	}
 
-	if err := checker.Check(); err != nil {
-		return ctrl.Result{}, fmt.Errorf("%w: %v", errHealthCheckerCheck, err)
+	cacheKey := signer.NewCacheKey(issuer, append([]string{secret.ResourceVersion}, resourceVersions...)...)
+	checker, err := r.SignerCache.HealthChecker(cacheKey, func() (signer.HealthChecker, error) {
+		return r.HealthCheckerBuilder(issuerSpec, secretData)
+	})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("%w: %w", errHealthCheckerBuilder, err)
+	}
+
+	ctx = httpClient.WithAuditKeys(ctx, common.AuditKeyIssuer, common.IssuerReference(r.Kind, issuer.GetNamespace(), issuer.GetName()))
+	checkErr := checker.Check(ctx)
+	if reporter, ok := checker.(signer.CircuitBreakerReporter); ok {
+		if SetCircuitBreakerCondition(issuerStatus, reporter.CircuitBreakerStatus()) {
+			logger.Info("CircuitBreakerClosed Condition changed")
+		}
+	}
+	if reporter, ok := checker.(signer.EndpointHealthReporter); ok {
+		// the health of a single endpoint is reported by the Ready condition
+		if endpointHealth := reporter.EndpointHealth(); len(endpointHealth) > 1 && SetEndpointsHealthyCondition(issuerStatus, endpointHealth) {
+			logger.Info("EndpointsHealthy Condition changed")
+		}
+	}
+
+	if checkErr != nil {
+		return ctrl.Result{}, fmt.Errorf("%w: %w", errHealthCheckerCheck, checkErr)
 	}
 
 	r.report(logger, issuer, issuerStatus, metav1.ConditionTrue, "Success", nil)
@@ -147,6 +258,11 @@ func (r *IssuerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res
 // report gives feedback by updating the Ready Condition of the {Cluster}Issuer
 // For added visibility it also logs a message and emits a Kubernetes Event.
 func (r *IssuerReconciler) report(logger logr.Logger, issuer client.Object, issuerStatus *certv1alpha1.IssuerStatus, conditionStatus metav1.ConditionStatus, message string, err error) {
+	r.reportWithReason(logger, issuer, issuerStatus, conditionStatus, eventReasonIssuerReconciler, message, err)
+}
+
+// reportWithReason is like report, but sets the given reason on the Ready Condition.
+func (r *IssuerReconciler) reportWithReason(logger logr.Logger, issuer client.Object, issuerStatus *certv1alpha1.IssuerStatus, conditionStatus metav1.ConditionStatus, conditionReason, message string, err error) {
 	eventType := corev1.EventTypeNormal
 	if err != nil {
 		logger.Error(err, message)
@@ -156,7 +272,22 @@ func (r *IssuerReconciler) report(logger logr.Logger, issuer client.Object, issu
 		logger.Info(message)
 	}
 	r.recorder.Event(issuer, eventType, eventReasonIssuerReconciler, message)
-	if SetReadyCondition(issuerStatus, conditionStatus, eventReasonIssuerReconciler, message) {
+	if SetReadyCondition(issuerStatus, conditionStatus, conditionReason, message) {
 		logger.Info("Ready Condition changed")
 	}
 }
+
+// conditionReasonFromError returns the reason of a failed health check,
+// so that the Ready Condition tells why the signer backend is unhealthy.
+func conditionReasonFromError(err error) string {
+	var healthCheckErr *signer.HealthCheckError
+	if errors.As(err, &healthCheckErr) {
+		return healthCheckErr.Reason
+	}
+
+	if errors.Is(err, signer.ErrInvalidTLSConfig) {
+		return signer.HealthCheckReasonTLSError
+	}
+
+	return eventReasonIssuerReconciler
+}
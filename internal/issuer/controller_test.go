@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
@@ -36,6 +37,17 @@ const (
 	clusterIssuerKind        = "ClusterIssuer"
 	clusterIssuerCredentials = clusterIssuerName + "-credentials"
 
+	clientCertificateSecretName = issuerName + "-client-tls"
+	clientCertificateData       = "client-certificate"
+	clientKeyData               = "client-key"
+
+	proxySecretName       = issuerName + "-proxy"
+	caBundleSecretName    = issuerName + "-ca-secret"
+	caBundleSecretKey     = "bundle.pem"
+	caBundleConfigMapName = issuerName + "-ca"
+	secretCABundleData    = "secret-ca-bundle"
+	configMapCABundleData = "configmap-ca-bundle"
+
 	kubeSystemNS     = "kube-system"
 	unrecognizedKind = "UnrecognizedKind"
 )
@@ -44,10 +56,28 @@ type fakeHealthChecker struct {
 	errCheck error
 }
 
-func (o *fakeHealthChecker) Check() error {
+func (o *fakeHealthChecker) Check(context.Context) error {
 	return o.errCheck
 }
 
+type fakeCircuitBreakerHealthChecker struct {
+	fakeHealthChecker
+	circuitBreakerStatus signer.CircuitBreakerStatus
+}
+
+func (o *fakeCircuitBreakerHealthChecker) CircuitBreakerStatus() signer.CircuitBreakerStatus {
+	return o.circuitBreakerStatus
+}
+
+type fakeEndpointHealthChecker struct {
+	fakeHealthChecker
+	endpointHealth []signer.EndpointHealth
+}
+
+func (o *fakeEndpointHealthChecker) EndpointHealth() []signer.EndpointHealth {
+	return o.endpointHealth
+}
+
 type args struct {
 	kind                     string
 	name                     types.NamespacedName
@@ -61,6 +91,13 @@ type want struct {
 	result               ctrl.Result
 	error                error
 	readyConditionStatus metav1.ConditionStatus
+	readyConditionReason string
+
+	circuitBreakerConditionStatus metav1.ConditionStatus
+	circuitBreakerConditionReason string
+
+	endpointsHealthyConditionStatus metav1.ConditionStatus
+	endpointsHealthyConditionReason string
 }
 
 func TestIssuerReconcile(t *testing.T) {
@@ -203,6 +240,241 @@ func TestIssuerReconcile(t *testing.T) {
 				readyConditionStatus: metav1.ConditionFalse,
 			},
 		},
+		"ShouldHandleMissingClientCertificateSecret": {
+			args: args{
+				name: types.NamespacedName{Namespace: issuerNS, Name: issuerName},
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerName,
+							Namespace: issuerNS,
+						},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+							HTTPConfig: certv1alpha1.HTTPConfig{
+								ClientCertificateSecretName: clientCertificateSecretName,
+							},
+						},
+						Status: certv1alpha1.IssuerStatus{
+							Conditions: []metav1.Condition{
+								{
+									Type:   conditionReady,
+									Status: metav1.ConditionStatus(cmmeta.ConditionUnknown),
+								},
+							},
+						},
+					},
+				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerCredentials,
+							Namespace: issuerNS,
+						},
+					},
+				},
+			},
+			want: want{
+				error:                errGetHTTPConfigData,
+				readyConditionStatus: metav1.ConditionFalse,
+			},
+		},
+		"ShouldPassClientCertificateToHealthCheckerBuilder": {
+			args: args{
+				name: types.NamespacedName{Namespace: issuerNS, Name: issuerName},
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerName,
+							Namespace: issuerNS,
+						},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+							HTTPConfig: certv1alpha1.HTTPConfig{
+								ClientCertificateSecretName: clientCertificateSecretName,
+							},
+						},
+						Status: certv1alpha1.IssuerStatus{
+							Conditions: []metav1.Condition{
+								{
+									Type:   conditionReady,
+									Status: metav1.ConditionStatus(cmmeta.ConditionUnknown),
+								},
+							},
+						},
+					},
+				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerCredentials,
+							Namespace: issuerNS,
+						},
+					},
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      clientCertificateSecretName,
+							Namespace: issuerNS,
+						},
+						Type: corev1.SecretTypeTLS,
+						Data: map[string][]byte{
+							corev1.TLSCertKey:       []byte(clientCertificateData),
+							corev1.TLSPrivateKeyKey: []byte(clientKeyData),
+						},
+					},
+				},
+				healthCheckerBuilder: func(_ *certv1alpha1.IssuerSpec, secretData map[string][]byte) (signer.HealthChecker, error) {
+					if string(secretData[signer.ClientCertificateSecretKey]) != clientCertificateData ||
+						string(secretData[signer.ClientKeySecretKey]) != clientKeyData {
+						return nil, errors.New("missing client certificate data")
+					}
+					return &fakeHealthChecker{}, nil
+				},
+			},
+			want: want{
+				readyConditionStatus: metav1.ConditionTrue,
+				result:               ctrl.Result{RequeueAfter: defaultHealthCheckInterval},
+			},
+		},
+		"ShouldPassCABundleToHealthCheckerBuilder": {
+			args: args{
+				name: types.NamespacedName{Namespace: issuerNS, Name: issuerName},
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerName,
+							Namespace: issuerNS,
+						},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+							HTTPConfig: certv1alpha1.HTTPConfig{
+								CABundleSecretRef:    &certv1alpha1.KeySelector{Name: caBundleSecretName, Key: caBundleSecretKey},
+								CABundleConfigMapRef: &certv1alpha1.KeySelector{Name: caBundleConfigMapName},
+							},
+						},
+						Status: certv1alpha1.IssuerStatus{
+							Conditions: []metav1.Condition{
+								{
+									Type:   conditionReady,
+									Status: metav1.ConditionStatus(cmmeta.ConditionUnknown),
+								},
+							},
+						},
+					},
+				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerCredentials,
+							Namespace: issuerNS,
+						},
+					},
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      caBundleSecretName,
+							Namespace: issuerNS,
+						},
+						Data: map[string][]byte{caBundleSecretKey: []byte(secretCABundleData)},
+					},
+					&corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      caBundleConfigMapName,
+							Namespace: issuerNS,
+						},
+						Data: map[string]string{"ca.crt": configMapCABundleData},
+					},
+				},
+				healthCheckerBuilder: func(_ *certv1alpha1.IssuerSpec, secretData map[string][]byte) (signer.HealthChecker, error) {
+					if string(secretData[signer.CABundleSecretKey]) != secretCABundleData+configMapCABundleData {
+						return nil, errors.New("missing CA bundle data")
+					}
+					return &fakeHealthChecker{}, nil
+				},
+			},
+			want: want{
+				readyConditionStatus: metav1.ConditionTrue,
+				result:               ctrl.Result{RequeueAfter: defaultHealthCheckInterval},
+			},
+		},
+		"ShouldHandleMissingCABundleConfigMap": {
+			args: args{
+				name: types.NamespacedName{Namespace: issuerNS, Name: issuerName},
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerName,
+							Namespace: issuerNS,
+						},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+							HTTPConfig: certv1alpha1.HTTPConfig{
+								CABundleConfigMapRef: &certv1alpha1.KeySelector{Name: caBundleConfigMapName},
+							},
+						},
+						Status: certv1alpha1.IssuerStatus{
+							Conditions: []metav1.Condition{
+								{
+									Type:   conditionReady,
+									Status: metav1.ConditionStatus(cmmeta.ConditionUnknown),
+								},
+							},
+						},
+					},
+				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerCredentials,
+							Namespace: issuerNS,
+						},
+					},
+				},
+			},
+			want: want{
+				error:                errGetHTTPConfigData,
+				readyConditionStatus: metav1.ConditionFalse,
+			},
+		},
+		"ShouldReportInvalidTLSConfig": {
+			args: args{
+				name: types.NamespacedName{Namespace: issuerNS, Name: issuerName},
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerName,
+							Namespace: issuerNS,
+						},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+						},
+						Status: certv1alpha1.IssuerStatus{
+							Conditions: []metav1.Condition{
+								{
+									Type:   conditionReady,
+									Status: metav1.ConditionStatus(cmmeta.ConditionUnknown),
+								},
+							},
+						},
+					},
+				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerCredentials,
+							Namespace: issuerNS,
+						},
+					},
+				},
+				healthCheckerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte) (signer.HealthChecker, error) {
+					return nil, fmt.Errorf("%w: invalid CA bundle", signer.ErrInvalidTLSConfig)
+				},
+			},
+			want: want{
+				error:                errHealthCheckerBuilder,
+				readyConditionStatus: metav1.ConditionFalse,
+				readyConditionReason: signer.HealthCheckReasonTLSError,
+			},
+		},
 		"ShouldHandleFailingHealthCheckerBuilder": {
 			args: args{
 				name: types.NamespacedName{Namespace: issuerNS, Name: issuerName},
@@ -281,6 +553,206 @@ func TestIssuerReconcile(t *testing.T) {
 				readyConditionStatus: metav1.ConditionFalse,
 			},
 		},
+		"ShouldReportHealthCheckReason": {
+			args: args{
+				name: types.NamespacedName{Namespace: issuerNS, Name: issuerName},
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerName,
+							Namespace: issuerNS,
+						},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+						},
+						Status: certv1alpha1.IssuerStatus{
+							Conditions: []metav1.Condition{
+								{
+									Type:   conditionReady,
+									Status: metav1.ConditionStatus(cmmeta.ConditionUnknown),
+								},
+							},
+						},
+					},
+				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerCredentials,
+							Namespace: issuerNS,
+						},
+					},
+				},
+				healthCheckerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte) (signer.HealthChecker, error) {
+					return &fakeHealthChecker{errCheck: &signer.HealthCheckError{
+						Reason: signer.HealthCheckReasonUnauthorized,
+						Err:    errors.New("simulated unauthorized error"),
+					}}, nil
+				},
+			},
+			want: want{
+				error:                errHealthCheckerCheck,
+				readyConditionStatus: metav1.ConditionFalse,
+				readyConditionReason: signer.HealthCheckReasonUnauthorized,
+			},
+		},
+		"ShouldReportOpenCircuitBreaker": {
+			args: args{
+				name: types.NamespacedName{Namespace: issuerNS, Name: issuerName},
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerName,
+							Namespace: issuerNS,
+						},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+						},
+						Status: certv1alpha1.IssuerStatus{
+							Conditions: []metav1.Condition{
+								{
+									Type:   conditionReady,
+									Status: metav1.ConditionStatus(cmmeta.ConditionUnknown),
+								},
+							},
+						},
+					},
+				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerCredentials,
+							Namespace: issuerNS,
+						},
+					},
+				},
+				healthCheckerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte) (signer.HealthChecker, error) {
+					return &fakeCircuitBreakerHealthChecker{
+						fakeHealthChecker: fakeHealthChecker{errCheck: &signer.HealthCheckError{
+							Reason: signer.HealthCheckReasonUnreachable,
+							Err:    errors.New("simulated unreachable error"),
+						}},
+						circuitBreakerStatus: signer.CircuitBreakerStatus{
+							State:      signer.CircuitBreakerOpen,
+							Failures:   5,
+							RetryAfter: time.Minute,
+						},
+					}, nil
+				},
+			},
+			want: want{
+				error:                errHealthCheckerCheck,
+				readyConditionStatus: metav1.ConditionFalse,
+				readyConditionReason: signer.HealthCheckReasonUnreachable,
+
+				circuitBreakerConditionStatus: metav1.ConditionFalse,
+				circuitBreakerConditionReason: string(signer.CircuitBreakerOpen),
+			},
+		},
+		"ShouldReportClosedCircuitBreaker": {
+			args: args{
+				name: types.NamespacedName{Namespace: issuerNS, Name: issuerName},
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerName,
+							Namespace: issuerNS,
+						},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+						},
+						Status: certv1alpha1.IssuerStatus{
+							Conditions: []metav1.Condition{
+								{
+									Type:   conditionReady,
+									Status: metav1.ConditionStatus(cmmeta.ConditionUnknown),
+								},
+							},
+						},
+					},
+				},
+				secretObjects: []client.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      issuerCredentials,
+							Namespace: issuerNS,
+						},
+					},
+				},
+				healthCheckerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte) (signer.HealthChecker, error) {
+					return &fakeCircuitBreakerHealthChecker{
+						circuitBreakerStatus: signer.CircuitBreakerStatus{State: signer.CircuitBreakerClosed},
+					}, nil
+				},
+			},
+			want: want{
+				readyConditionStatus: metav1.ConditionTrue,
+				result:               ctrl.Result{RequeueAfter: defaultHealthCheckInterval},
+
+				circuitBreakerConditionStatus: metav1.ConditionTrue,
+				circuitBreakerConditionReason: string(signer.CircuitBreakerClosed),
+			},
+		},
+		"ShouldReportDegradedEndpoints": {
+			args: args{
+				name:          types.NamespacedName{Namespace: issuerNS, Name: issuerName},
+				issuerObjects: []client.Object{newTestIssuer()},
+				secretObjects: []client.Object{newTestIssuerSecret()},
+				healthCheckerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte) (signer.HealthChecker, error) {
+					return &fakeEndpointHealthChecker{
+						endpointHealth: []signer.EndpointHealth{
+							{Endpoint: "https://cert-api.eu.example.com/", Reason: signer.HealthCheckReasonUnreachable},
+							{Endpoint: "https://cert-api.us.example.com/", Healthy: true},
+						},
+					}, nil
+				},
+			},
+			want: want{
+				readyConditionStatus: metav1.ConditionTrue,
+				result:               ctrl.Result{RequeueAfter: defaultHealthCheckInterval},
+
+				endpointsHealthyConditionStatus: metav1.ConditionFalse,
+				endpointsHealthyConditionReason: "Degraded",
+			},
+		},
+		"ShouldReportHealthyEndpoints": {
+			args: args{
+				name:          types.NamespacedName{Namespace: issuerNS, Name: issuerName},
+				issuerObjects: []client.Object{newTestIssuer()},
+				secretObjects: []client.Object{newTestIssuerSecret()},
+				healthCheckerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte) (signer.HealthChecker, error) {
+					return &fakeEndpointHealthChecker{
+						endpointHealth: []signer.EndpointHealth{
+							{Endpoint: "https://cert-api.eu.example.com/", Healthy: true},
+							{Endpoint: "https://cert-api.us.example.com/", Healthy: true},
+						},
+					}, nil
+				},
+			},
+			want: want{
+				readyConditionStatus: metav1.ConditionTrue,
+				result:               ctrl.Result{RequeueAfter: defaultHealthCheckInterval},
+
+				endpointsHealthyConditionStatus: metav1.ConditionTrue,
+				endpointsHealthyConditionReason: "Healthy",
+			},
+		},
+		"ShouldNotReportHealthOfSingleEndpoint": {
+			args: args{
+				name:          types.NamespacedName{Namespace: issuerNS, Name: issuerName},
+				issuerObjects: []client.Object{newTestIssuer()},
+				secretObjects: []client.Object{newTestIssuerSecret()},
+				healthCheckerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte) (signer.HealthChecker, error) {
+					return &fakeEndpointHealthChecker{
+						endpointHealth: []signer.EndpointHealth{{Endpoint: "https://cert-api.eu.example.com/", Healthy: true}},
+					}, nil
+				},
+			},
+			want: want{
+				readyConditionStatus: metav1.ConditionTrue,
+				result:               ctrl.Result{RequeueAfter: defaultHealthCheckInterval},
+			},
+		},
 	}
 
 	scheme := runtime.NewScheme()
@@ -321,6 +793,137 @@ func TestIssuerReconcile(t *testing.T) {
 			condition := GetReadyCondition(issuerStatusAfter)
 			verifyCondition(t, *condition, tc.want)
 			verifyEvents(t, condition, actualEvents, reconcileErr)
+			verifyCircuitBreakerCondition(t, GetCircuitBreakerCondition(issuerStatusAfter), tc.want)
+			verifyEndpointsHealthyCondition(t, GetEndpointsHealthyCondition(issuerStatusAfter), tc.want)
+		})
+	}
+}
+
+func TestIssuersForSecret(t *testing.T) {
+	cases := map[string]struct {
+		kind     string
+		secret   types.NamespacedName
+		requests []reconcile.Request
+	}{
+		"ShouldMapAuthSecretToIssuer": {
+			kind:     issuerKind,
+			secret:   types.NamespacedName{Namespace: issuerNS, Name: issuerCredentials},
+			requests: []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: issuerNS, Name: issuerName}}},
+		},
+		"ShouldMapClientCertificateSecretToIssuer": {
+			kind:     issuerKind,
+			secret:   types.NamespacedName{Namespace: issuerNS, Name: clientCertificateSecretName},
+			requests: []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: issuerNS, Name: issuerName}}},
+		},
+		"ShouldMapCABundleSecretToIssuer": {
+			kind:     issuerKind,
+			secret:   types.NamespacedName{Namespace: issuerNS, Name: caBundleSecretName},
+			requests: []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: issuerNS, Name: issuerName}}},
+		},
+		"ShouldMapProxySecretToIssuer": {
+			kind:     issuerKind,
+			secret:   types.NamespacedName{Namespace: issuerNS, Name: proxySecretName},
+			requests: []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: issuerNS, Name: issuerName}}},
+		},
+		"ShouldNotMapSecretInOtherNamespace": {
+			kind:   issuerKind,
+			secret: types.NamespacedName{Namespace: kubeSystemNS, Name: issuerCredentials},
+		},
+		"ShouldMapSecretInClusterResourceNamespaceToClusterIssuer": {
+			kind:     clusterIssuerKind,
+			secret:   types.NamespacedName{Namespace: kubeSystemNS, Name: clusterIssuerCredentials},
+			requests: []reconcile.Request{{NamespacedName: types.NamespacedName{Name: clusterIssuerName}}},
+		},
+		"ShouldNotMapSecretOutsideClusterResourceNamespaceToClusterIssuer": {
+			kind:   clusterIssuerKind,
+			secret: types.NamespacedName{Namespace: issuerNS, Name: clusterIssuerCredentials},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, certv1alpha1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, _, controller := setupController(scheme, args{
+				kind: tc.kind,
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{Name: issuerName, Namespace: issuerNS},
+						Spec: certv1alpha1.IssuerSpec{
+							AuthSecretName: issuerCredentials,
+							HTTPConfig: certv1alpha1.HTTPConfig{
+								ClientCertificateSecretName: clientCertificateSecretName,
+								CABundleSecretRef:           &certv1alpha1.KeySelector{Name: caBundleSecretName},
+								ProxySecretName:             proxySecretName,
+							},
+						},
+					},
+					&certv1alpha1.ClusterIssuer{
+						ObjectMeta: metav1.ObjectMeta{Name: clusterIssuerName},
+						Spec:       certv1alpha1.IssuerSpec{AuthSecretName: clusterIssuerCredentials},
+					},
+				},
+				clusterResourceNamespace: kubeSystemNS,
+			})
+
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: tc.secret.Name, Namespace: tc.secret.Namespace}}
+			assert.Equal(t, tc.requests, controller.issuersForSecret(context.TODO(), secret))
+		})
+	}
+}
+
+func TestIssuersForConfigMap(t *testing.T) {
+	cases := map[string]struct {
+		kind      string
+		configMap types.NamespacedName
+		requests  []reconcile.Request
+	}{
+		"ShouldMapCABundleConfigMapToIssuer": {
+			kind:      issuerKind,
+			configMap: types.NamespacedName{Namespace: issuerNS, Name: caBundleConfigMapName},
+			requests:  []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: issuerNS, Name: issuerName}}},
+		},
+		"ShouldNotMapUnreferencedConfigMap": {
+			kind:      issuerKind,
+			configMap: types.NamespacedName{Namespace: issuerNS, Name: issuerCredentials},
+		},
+		"ShouldMapConfigMapInClusterResourceNamespaceToClusterIssuer": {
+			kind:      clusterIssuerKind,
+			configMap: types.NamespacedName{Namespace: kubeSystemNS, Name: caBundleConfigMapName},
+			requests:  []reconcile.Request{{NamespacedName: types.NamespacedName{Name: clusterIssuerName}}},
+		},
+		"ShouldNotMapConfigMapOutsideClusterResourceNamespaceToClusterIssuer": {
+			kind:      clusterIssuerKind,
+			configMap: types.NamespacedName{Namespace: issuerNS, Name: caBundleConfigMapName},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, certv1alpha1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			httpConfig := certv1alpha1.HTTPConfig{CABundleConfigMapRef: &certv1alpha1.KeySelector{Name: caBundleConfigMapName}}
+			_, _, controller := setupController(scheme, args{
+				kind: tc.kind,
+				issuerObjects: []client.Object{
+					&certv1alpha1.Issuer{
+						ObjectMeta: metav1.ObjectMeta{Name: issuerName, Namespace: issuerNS},
+						Spec:       certv1alpha1.IssuerSpec{AuthSecretName: issuerCredentials, HTTPConfig: httpConfig},
+					},
+					&certv1alpha1.ClusterIssuer{
+						ObjectMeta: metav1.ObjectMeta{Name: clusterIssuerName},
+						Spec:       certv1alpha1.IssuerSpec{AuthSecretName: clusterIssuerCredentials, HTTPConfig: httpConfig},
+					},
+				},
+				clusterResourceNamespace: kubeSystemNS,
+			})
+
+			configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: tc.configMap.Name, Namespace: tc.configMap.Namespace}}
+			assert.Equal(t, tc.requests, controller.issuersForConfigMap(context.TODO(), configMap))
 		})
 	}
 }
@@ -350,6 +953,37 @@ func setupController(scheme *runtime.Scheme, args args) (*record.FakeRecorder, c
 	return eventRecorder, fakeClient, controller
 }
 
+// newTestIssuer returns an Issuer whose Ready condition is Unknown.
+func newTestIssuer() *certv1alpha1.Issuer {
+	return &certv1alpha1.Issuer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      issuerName,
+			Namespace: issuerNS,
+		},
+		Spec: certv1alpha1.IssuerSpec{
+			AuthSecretName: issuerCredentials,
+		},
+		Status: certv1alpha1.IssuerStatus{
+			Conditions: []metav1.Condition{
+				{
+					Type:   conditionReady,
+					Status: metav1.ConditionStatus(cmmeta.ConditionUnknown),
+				},
+			},
+		},
+	}
+}
+
+// newTestIssuerSecret returns the AuthSecret of the Issuer returned by newTestIssuer.
+func newTestIssuerSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      issuerCredentials,
+			Namespace: issuerNS,
+		},
+	}
+}
+
 // getIssuer returns an Issuer object.
 func getIssuer(t *testing.T, fakeClient client.Client, name types.NamespacedName, controller IssuerReconciler) client.Object {
 	issuer, err := controller.newIssuer()
@@ -381,11 +1015,40 @@ func verifyCondition(t *testing.T, condition metav1.Condition, want want) {
 		if assert.NotNilf(t, condition, "Ready condition was expected but not found: want.readyConditionStatus == %v", want.readyConditionStatus) {
 			verifyIssuerReadyCondition(t, want.readyConditionStatus, condition)
 		}
+		if want.readyConditionReason != "" {
+			verifyIssuerReadyConditionReason(t, want.readyConditionReason, condition)
+		}
 	} else {
 		assert.Nil(t, condition, "Unexpected Ready condition")
 	}
 }
 
+// verifyCircuitBreakerCondition makes checks if the Issuer is expected to have a CircuitBreakerClosed condition.
+func verifyCircuitBreakerCondition(t *testing.T, condition *metav1.Condition, want want) {
+	if want.circuitBreakerConditionStatus == "" {
+		assert.Nil(t, condition, "Unexpected CircuitBreakerClosed condition")
+		return
+	}
+
+	if assert.NotNil(t, condition, "CircuitBreakerClosed condition was expected but not found") {
+		assert.Equal(t, want.circuitBreakerConditionStatus, condition.Status, "unexpected condition status")
+		assert.Equal(t, want.circuitBreakerConditionReason, condition.Reason, "unexpected condition reason")
+	}
+}
+
+// verifyEndpointsHealthyCondition makes checks if the Issuer is expected to have an EndpointsHealthy condition.
+func verifyEndpointsHealthyCondition(t *testing.T, condition *metav1.Condition, want want) {
+	if want.endpointsHealthyConditionStatus == "" {
+		assert.Nil(t, condition, "Unexpected EndpointsHealthy condition")
+		return
+	}
+
+	if assert.NotNil(t, condition, "EndpointsHealthy condition was expected but not found") {
+		assert.Equal(t, want.endpointsHealthyConditionStatus, condition.Status, "unexpected condition status")
+		assert.Equal(t, want.endpointsHealthyConditionReason, condition.Reason, "unexpected condition reason")
+	}
+}
+
 // verifyEvents makes checks to see if expected events have been emitted.
 // The desired Event behaviour is as follows: An Event should always be generated when the Ready condition is set;
 // Event contents should match the status and message of the condition;
@@ -423,3 +1086,7 @@ func assertErrorIs(t *testing.T, expectedError, actualError error) {
 func verifyIssuerReadyCondition(t *testing.T, status metav1.ConditionStatus, condition metav1.Condition) {
 	assert.Equal(t, status, condition.Status, "unexpected condition status")
 }
+
+func verifyIssuerReadyConditionReason(t *testing.T, reason string, condition metav1.Condition) {
+	assert.Equal(t, reason, condition.Reason, "unexpected condition reason")
+}
@@ -2,8 +2,16 @@ package jsonutil
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 )
 
+const pathSeparator = "."
+
+var errValueIsNotString = errors.New("value is not a string or a number")
+
 // ToJSON converts a given request object into a JSON string.
 // It returns an empty string if there is an error during JSON marshaling.
 func ToJSON(data interface{}) string {
@@ -21,3 +29,58 @@ func IsJSONString(jsonStr string) bool {
 	var js map[string]interface{}
 	return json.Unmarshal([]byte(jsonStr), &js) == nil
 }
+
+// GetString returns the string or number found at the given dot-separated path of a JSON string,
+// such as "result.certificates.0.data". Object keys are matched like in json.Unmarshal, preferring
+// an exact match over a case-insensitive one, and array elements are selected by their index.
+// It returns an empty string if there is no value at the path.
+func GetString(jsonStr string, path string) (string, error) {
+	decoder := json.NewDecoder(strings.NewReader(jsonStr))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", err
+	}
+
+	for _, key := range strings.Split(path, pathSeparator) {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			value = lookupKey(node, key)
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return "", nil
+			}
+			value = node[index]
+		default:
+			return "", nil
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	default:
+		return "", fmt.Errorf("%w: %q", errValueIsNotString, path)
+	}
+}
+
+// lookupKey returns the value of the key in the JSON object, falling back to a case-insensitive match.
+func lookupKey(node map[string]interface{}, key string) interface{} {
+	if value, ok := node[key]; ok {
+		return value
+	}
+
+	for k, value := range node {
+		if strings.EqualFold(k, key) {
+			return value
+		}
+	}
+
+	return nil
+}
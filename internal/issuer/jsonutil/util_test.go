@@ -89,3 +89,93 @@ func Test_IsJSONString(t *testing.T) {
 		})
 	}
 }
+
+func Test_GetString(t *testing.T) {
+	type args struct {
+		data string
+		path string
+	}
+	type want struct {
+		result string
+		err    bool
+	}
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldReturnTopLevelValue": {
+			args: args{
+				data: `{"taskId":"12345678"}`,
+				path: "taskId",
+			},
+			want: want{
+				result: "12345678",
+			},
+		},
+		"ShouldReturnNestedValue": {
+			args: args{
+				data: `{"result":{"certificates":[{"data":"first"},{"data":"second"}]}}`,
+				path: "result.certificates.1.data",
+			},
+			want: want{
+				result: "second",
+			},
+		},
+		"ShouldReturnNumberValue": {
+			args: args{
+				data: `{"request":{"id":12345678901234567890}}`,
+				path: "request.id",
+			},
+			want: want{
+				result: "12345678901234567890",
+			},
+		},
+		"ShouldMatchKeyCaseInsensitively": {
+			args: args{
+				data: `{"Data":"value"}`,
+				path: "data",
+			},
+			want: want{
+				result: "value",
+			},
+		},
+		"ShouldReturnEmptyStringForMissingPath": {
+			args: args{
+				data: `{"result":{"certificates":[]}}`,
+				path: "result.certificates.0.data",
+			},
+			want: want{
+				result: "",
+			},
+		},
+		"ShouldFailForObjectValue": {
+			args: args{
+				data: `{"result":{"data":"value"}}`,
+				path: "result",
+			},
+			want: want{
+				err: true,
+			},
+		},
+		"ShouldFailForInvalidJSON": {
+			args: args{
+				data: `{"result":`,
+				path: "result",
+			},
+			want: want{
+				err: true,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := GetString(tc.args.data, tc.args.path)
+			if (err != nil) != tc.want.err {
+				t.Fatalf("GetString(...): unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("GetString(...): -want result, +got result: %v", diff)
+			}
+		})
+	}
+}
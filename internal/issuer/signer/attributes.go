@@ -0,0 +1,71 @@
+package signer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+)
+
+var (
+	errInvalidAttributeTemplate = errors.New("invalid attribute template")
+	errFailedRenderingAttribute = errors.New("failed to render attribute")
+)
+
+// RequestMetadata describes the CertificateRequest for which a CSR is signed. It is the data with
+// which the attribute templates of the request profile are rendered.
+type RequestMetadata struct {
+	// ClusterID identifies the cluster of the controller.
+	ClusterID string
+
+	// Namespace is the namespace of the CertificateRequest.
+	Namespace string
+
+	// Name is the name of the CertificateRequest.
+	Name string
+
+	// Username is the name of the user who created the CertificateRequest.
+	Username string
+
+	// Annotations are the annotations of the CertificateRequest.
+	Annotations map[string]string
+}
+
+// buildAttributeTemplates parses the attribute templates of the request profile of the issuerSpec,
+// keyed by the name of their parameter.
+func buildAttributeTemplates(issuerSpec *certv1alpha1.IssuerSpec) (map[string]*template.Template, error) {
+	attributes := issuerSpec.RequestProfile.Attributes
+	if len(attributes) == 0 {
+		return nil, nil
+	}
+
+	templates := make(map[string]*template.Template, len(attributes))
+	for name, value := range attributes {
+		tmpl, err := template.New(name).Option("missingkey=zero").Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", errInvalidAttributeTemplate, name, err)
+		}
+		templates[name] = tmpl
+	}
+
+	return templates, nil
+}
+
+// renderAttributes renders the attribute templates with the metadata of the CertificateRequest into the
+// parameters, leaving out the attributes which render empty.
+func renderAttributes(templates map[string]*template.Template, metadata RequestMetadata, parameters map[string]string) error {
+	for name, tmpl := range templates {
+		var value strings.Builder
+		if err := tmpl.Execute(&value, metadata); err != nil {
+			return fmt.Errorf("%w %q: %v", errFailedRenderingAttribute, name, err)
+		}
+
+		if value.Len() > 0 {
+			parameters[name] = value.String()
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	"github.com/dana-team/cert-external-issuer/internal/fakecertapi"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCertSignerSignWithAttributes(t *testing.T) {
+	metadata := RequestMetadata{
+		ClusterID:   "cluster-1",
+		Namespace:   "ns-1",
+		Name:        "cr-1",
+		Username:    "system:serviceaccount:cert-manager:cert-manager",
+		Annotations: map[string]string{"cert-manager.io/certificate-name": "certificate-1"},
+	}
+
+	type args struct {
+		parameters map[string]string
+		attributes map[string]string
+	}
+	type want struct {
+		err        error
+		parameters map[string]string
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldSendRenderedAttributes": {
+			args: args{
+				attributes: map[string]string{
+					"owner":       "{{ .ClusterID }}/{{ .Namespace }}/{{ .Name }}",
+					"requester":   "{{ .Username }}",
+					"certificate": `{{ index .Annotations "cert-manager.io/certificate-name" }}`,
+				},
+			},
+			want: want{
+				parameters: map[string]string{
+					"owner":       "cluster-1/ns-1/cr-1",
+					"requester":   "system:serviceaccount:cert-manager:cert-manager",
+					"certificate": "certificate-1",
+				},
+			},
+		},
+		"ShouldOmitAttributesWhichRenderEmpty": {
+			args: args{
+				attributes: map[string]string{
+					"owner": "{{ .Namespace }}",
+					"team":  `{{ index .Annotations "example.com/team" }}`,
+				},
+			},
+			want: want{
+				parameters: map[string]string{"owner": "ns-1"},
+			},
+		},
+		"ShouldOverrideParametersWithAttributes": {
+			args: args{
+				parameters: map[string]string{"owner": "static", "profile": "server"},
+				attributes: map[string]string{"owner": "{{ .Namespace }}"},
+			},
+			want: want{
+				parameters: map[string]string{"owner": "ns-1", "profile": "server"},
+			},
+		},
+		"ShouldFailWithInvalidTemplate": {
+			args: args{
+				attributes: map[string]string{"owner": "{{ .Namespace"},
+			},
+			want: want{
+				err: errInvalidAttributeTemplate,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var received map[string]string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseMultipartForm(1 << 20); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				received = map[string]string{}
+				for key, values := range r.MultipartForm.Value {
+					received[key] = values[0]
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"taskId": "task-1"}`))
+			}))
+			defer server.Close()
+
+			issuerSpec := &certv1alpha1.IssuerSpec{
+				APIEndpoint:      server.URL + "/",
+				DownloadEndpoint: testDownloadPath,
+				Form:             fakecertapi.FormChain,
+				RequestProfile: certv1alpha1.RequestProfile{
+					Parameters: tc.args.parameters,
+					Attributes: tc.args.attributes,
+				},
+				CertificateRestrictions: certv1alpha1.Restrictions{
+					SubjectAltNamesRestrictions: certv1alpha1.SubjectAltNamesRestrictions{AllowDNSNames: true},
+				},
+			}
+
+			signer, err := CertSignerFromIssuerAndSecretData(issuerSpec, map[string][]byte{authorizationHeaderSecretKey: []byte(testToken)}, fake.NewClientBuilder().Build())
+			if tc.want.err != nil {
+				assert.True(t, errors.Is(err, tc.want.err), "unexpected error: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+
+			_, err = signer.Sign(context.Background(), logr.Discard(), SignRequest{CSR: generateTestCSR(t), Metadata: metadata}, Task{})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.parameters, received)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package signer
+
+import (
+	"errors"
+	"fmt"
+
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	"github.com/dana-team/cert-external-issuer/internal/issuer/clients/cert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	apiKeySecretKey             = "apiKey"
+	oauth2ClientIDSecretKey     = "clientID"
+	oauth2ClientSecretSecretKey = "clientSecret"
+)
+
+var (
+	errMissingBasicAuthData    = errors.New("missing username or password data in secret")
+	errMissingAPIKeyData       = errors.New("missing apiKey data in secret")
+	errMissingOAuth2ClientData = errors.New("missing clientID or clientSecret data in secret")
+	errMissingOAuth2TokenURL   = errors.New("missing oauth2 token URL")
+	errUnsupportedAuthType     = errors.New("unsupported auth type")
+)
+
+// buildAuthenticator returns a cert.Authenticator using the auth type of the issuerSpec and the
+// credentials in the secret data.
+func buildAuthenticator(issuerSpec *certv1alpha1.IssuerSpec, secretData map[string][]byte) (cert.Authenticator, error) {
+	auth := issuerSpec.Auth
+
+	switch auth.Type {
+	case "", certv1alpha1.AuthTypeBearer:
+		token := string(secretData[authorizationHeaderSecretKey])
+		if token == "" {
+			return nil, errMissingTokenData
+		}
+		return cert.NewBearerAuthenticator(token), nil
+	case certv1alpha1.AuthTypeBasic:
+		username := string(secretData[corev1.BasicAuthUsernameKey])
+		password := string(secretData[corev1.BasicAuthPasswordKey])
+		if username == "" || password == "" {
+			return nil, errMissingBasicAuthData
+		}
+		return cert.NewBasicAuthenticator(username, password), nil
+	case certv1alpha1.AuthTypeAPIKey:
+		apiKey := string(secretData[apiKeySecretKey])
+		if apiKey == "" {
+			return nil, errMissingAPIKeyData
+		}
+		return cert.NewAPIKeyAuthenticator(auth.APIKeyHeader, apiKey), nil
+	case certv1alpha1.AuthTypeOAuth2:
+		if auth.OAuth2 == nil || auth.OAuth2.TokenURL == "" {
+			return nil, errMissingOAuth2TokenURL
+		}
+		clientID := string(secretData[oauth2ClientIDSecretKey])
+		clientSecret := string(secretData[oauth2ClientSecretSecretKey])
+		if clientID == "" || clientSecret == "" {
+			return nil, errMissingOAuth2ClientData
+		}
+		return cert.NewOAuth2Authenticator(cert.OAuth2Config{
+			TokenURL:     auth.OAuth2.TokenURL,
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Scopes:       auth.OAuth2.Scopes,
+		}), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnsupportedAuthType, auth.Type)
+	}
+}
@@ -0,0 +1,128 @@
+package signer
+
+import (
+	"errors"
+	"testing"
+
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestBuildAuthenticator(t *testing.T) {
+	type args struct {
+		auth       certv1alpha1.Auth
+		secretData map[string][]byte
+	}
+	type want struct {
+		err error
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldDefaultToBearer": {
+			args: args{
+				secretData: map[string][]byte{authorizationHeaderSecretKey: []byte(testToken)},
+			},
+		},
+		"ShouldFailBearerWithoutToken": {
+			args: args{
+				auth: certv1alpha1.Auth{Type: certv1alpha1.AuthTypeBearer},
+			},
+			want: want{
+				err: errMissingTokenData,
+			},
+		},
+		"ShouldBuildBasic": {
+			args: args{
+				auth: certv1alpha1.Auth{Type: certv1alpha1.AuthTypeBasic},
+				secretData: map[string][]byte{
+					corev1.BasicAuthUsernameKey: []byte("user"),
+					corev1.BasicAuthPasswordKey: []byte("password"),
+				},
+			},
+		},
+		"ShouldFailBasicWithoutPassword": {
+			args: args{
+				auth:       certv1alpha1.Auth{Type: certv1alpha1.AuthTypeBasic},
+				secretData: map[string][]byte{corev1.BasicAuthUsernameKey: []byte("user")},
+			},
+			want: want{
+				err: errMissingBasicAuthData,
+			},
+		},
+		"ShouldBuildAPIKey": {
+			args: args{
+				auth:       certv1alpha1.Auth{Type: certv1alpha1.AuthTypeAPIKey},
+				secretData: map[string][]byte{apiKeySecretKey: []byte("api-key")},
+			},
+		},
+		"ShouldFailAPIKeyWithoutKey": {
+			args: args{
+				auth: certv1alpha1.Auth{Type: certv1alpha1.AuthTypeAPIKey},
+			},
+			want: want{
+				err: errMissingAPIKeyData,
+			},
+		},
+		"ShouldBuildOAuth2": {
+			args: args{
+				auth: certv1alpha1.Auth{
+					Type:   certv1alpha1.AuthTypeOAuth2,
+					OAuth2: &certv1alpha1.OAuth2{TokenURL: "https://auth.example.com/token"},
+				},
+				secretData: map[string][]byte{
+					oauth2ClientIDSecretKey:     []byte("client"),
+					oauth2ClientSecretSecretKey: []byte("secret"),
+				},
+			},
+		},
+		"ShouldFailOAuth2WithoutTokenURL": {
+			args: args{
+				auth: certv1alpha1.Auth{Type: certv1alpha1.AuthTypeOAuth2},
+				secretData: map[string][]byte{
+					oauth2ClientIDSecretKey:     []byte("client"),
+					oauth2ClientSecretSecretKey: []byte("secret"),
+				},
+			},
+			want: want{
+				err: errMissingOAuth2TokenURL,
+			},
+		},
+		"ShouldFailOAuth2WithoutClientSecret": {
+			args: args{
+				auth: certv1alpha1.Auth{
+					Type:   certv1alpha1.AuthTypeOAuth2,
+					OAuth2: &certv1alpha1.OAuth2{TokenURL: "https://auth.example.com/token"},
+				},
+				secretData: map[string][]byte{oauth2ClientIDSecretKey: []byte("client")},
+			},
+			want: want{
+				err: errMissingOAuth2ClientData,
+			},
+		},
+		"ShouldFailOnUnsupportedType": {
+			args: args{
+				auth: certv1alpha1.Auth{Type: "Digest"},
+			},
+			want: want{
+				err: errUnsupportedAuthType,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			issuerSpec := &certv1alpha1.IssuerSpec{Auth: tc.args.auth}
+			authenticator, err := buildAuthenticator(issuerSpec, tc.args.secretData)
+			if tc.want.err != nil {
+				assert.True(t, errors.Is(err, tc.want.err), "unexpected error: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, authenticator)
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	httpClient "github.com/dana-team/cert-external-issuer/internal/issuer/clients/http"
+)
+
+const (
+	defaultCircuitBreakerFailureThreshold = 5
+	defaultCircuitBreakerOpenDuration     = 30 * time.Second
+)
+
+// CircuitBreakerState is the state of the circuit breaker guarding the requests to a signer backend.
+type CircuitBreakerState string
+
+const (
+	// CircuitBreakerClosed is the state in which requests are sent to the signer backend.
+	CircuitBreakerClosed CircuitBreakerState = "Closed"
+
+	// CircuitBreakerOpen is the state in which requests fail fast without being sent to the signer backend.
+	CircuitBreakerOpen CircuitBreakerState = "Open"
+
+	// CircuitBreakerHalfOpen is the state in which a single probe request is sent to the signer backend
+	// to find out whether it recovered.
+	CircuitBreakerHalfOpen CircuitBreakerState = "HalfOpen"
+)
+
+var errInvalidCircuitBreaker = errors.New("invalid circuit breaker")
+
+// CircuitOpenError is returned instead of sending a request to the signer backend while its circuit breaker is open.
+type CircuitOpenError struct {
+	// Failures is the number of consecutive failures after which the circuit breaker opened.
+	Failures int
+
+	// RetryAfter is the time to wait before the signer backend is probed again.
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker of the Cert API is open after %d consecutive failures, retrying in %s",
+		e.Failures, e.RetryAfter.Round(time.Second))
+}
+
+// CircuitBreakerStatus is a snapshot of a circuit breaker.
+type CircuitBreakerStatus struct {
+	// State is the state of the circuit breaker.
+	State CircuitBreakerState
+
+	// Failures is the number of consecutive failures of the signer backend.
+	Failures int
+
+	// RetryAfter is the time left until an open circuit breaker lets a probe request through.
+	RetryAfter time.Duration
+}
+
+// CircuitBreakerReporter is implemented by HealthCheckers whose signer backend is guarded by a circuit breaker.
+type CircuitBreakerReporter interface {
+	// CircuitBreakerStatus returns the current status of the circuit breaker.
+	CircuitBreakerStatus() CircuitBreakerStatus
+}
+
+// circuitBreaker stops sending requests to the signer backend after consecutive transport or 5xx failures.
+// Once openDuration passed, a single probe request is let through, which closes the circuit breaker if it
+// succeeds and opens it again if it fails.
+type circuitBreaker struct {
+	failureThreshold int
+	openDuration     time.Duration
+
+	mu       sync.Mutex
+	state    CircuitBreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// circuitBreakers holds the circuit breakers which are shared by the signers, so that the Signer and
+// HealthChecker of an issuer, and the issuers which share the signer backend and its configuration,
+// see the same state.
+var circuitBreakers = newSharedObjects[*circuitBreaker]()
+
+// newCircuitBreaker returns a closed circuitBreaker.
+func newCircuitBreaker(failureThreshold int, openDuration time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		failureThreshold: failureThreshold,
+		openDuration:     openDuration,
+		state:            CircuitBreakerClosed,
+	}
+}
+
+// buildCircuitBreaker returns the circuit breaker of the requests to the apiEndpoint using values from the
+// HTTPConfig of the issuerSpec, along with the function which releases it. Issuers which send requests to
+// the same apiEndpoint with the same credentials, auth and HTTPConfig share a circuit breaker.
+func buildCircuitBreaker(issuerSpec *certv1alpha1.IssuerSpec, apiEndpoint string, secretData map[string][]byte) (*circuitBreaker, func(), error) {
+	failureThreshold := defaultCircuitBreakerFailureThreshold
+	openDuration := defaultCircuitBreakerOpenDuration
+
+	if config := issuerSpec.HTTPConfig.CircuitBreaker; config != nil {
+		if config.FailureThreshold < 0 {
+			return nil, nil, fmt.Errorf("%w: failureThreshold must not be negative, got %d", errInvalidCircuitBreaker, config.FailureThreshold)
+		}
+		if config.FailureThreshold > 0 {
+			failureThreshold = config.FailureThreshold
+		}
+
+		if config.OpenDuration != nil {
+			if config.OpenDuration.Duration <= 0 {
+				return nil, nil, fmt.Errorf("%w: openDuration must be positive, got %s", errInvalidCircuitBreaker, config.OpenDuration.Duration)
+			}
+			openDuration = config.OpenDuration.Duration
+		}
+	}
+
+	key := endpointCredentialsKey(apiEndpoint, secretData, issuerSpec.Auth, issuerSpec.HTTPConfig)
+	breaker, release := circuitBreakers.acquire(key, func() *circuitBreaker {
+		return newCircuitBreaker(failureThreshold, openDuration)
+	})
+
+	return breaker, release, nil
+}
+
+// Allow returns a *CircuitOpenError if no request may be sent. Every allowed request must be followed
+// by a call to Record with its outcome.
+func (b *circuitBreaker) Allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitBreakerOpen:
+		if retryAfter := b.retryAfter(); retryAfter > 0 {
+			return &CircuitOpenError{Failures: b.failures, RetryAfter: retryAfter}
+		}
+		b.state = CircuitBreakerHalfOpen
+		b.probing = true
+	case CircuitBreakerHalfOpen:
+		if b.probing {
+			return &CircuitOpenError{Failures: b.failures, RetryAfter: b.openDuration}
+		}
+		b.probing = true
+	}
+
+	return nil
+}
+
+// Record records the outcome of a request. Transport errors and 5xx responses count as failures, while
+// requests which were cancelled by their context count neither as failures nor as successes.
+func (b *circuitBreaker) Record(ctx context.Context, err error) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case err != nil && ctx.Err() != nil:
+		b.probing = false
+	case isCircuitBreakerFailure(err):
+		b.failures++
+		if b.state == CircuitBreakerHalfOpen || b.failures >= b.failureThreshold {
+			b.state = CircuitBreakerOpen
+			b.openedAt = time.Now()
+		}
+		b.probing = false
+	default:
+		b.state = CircuitBreakerClosed
+		b.failures = 0
+		b.probing = false
+	}
+}
+
+// RecordHealthCheck records whether a health check found the signer backend healthy. Health checks are
+// sent whatever the state of the circuit breaker, without calling Allow, so they do not follow its probing
+// rules: a successful health check closes the circuit breaker, as the signer backend recovered, while a
+// failed one leaves it as it is, so that health checks neither open the circuit breaker nor delay or take
+// the place of its half-open probe.
+func (b *circuitBreaker) RecordHealthCheck(healthy bool) {
+	if b == nil || !healthy {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = CircuitBreakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Status returns a snapshot of the circuit breaker.
+func (b *circuitBreaker) Status() CircuitBreakerStatus {
+	if b == nil {
+		return CircuitBreakerStatus{State: CircuitBreakerClosed}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := CircuitBreakerStatus{State: b.state, Failures: b.failures}
+	if b.state == CircuitBreakerOpen {
+		status.RetryAfter = b.retryAfter()
+		if status.RetryAfter == 0 {
+			status.State = CircuitBreakerHalfOpen
+		}
+	}
+
+	return status
+}
+
+// retryAfter returns the time left until an open circuit breaker becomes half-open. It must be called
+// with the lock held.
+func (b *circuitBreaker) retryAfter() time.Duration {
+	if remaining := b.openDuration - time.Since(b.openedAt); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// isCircuitBreakerFailure returns a boolean indicating whether an error was caused by the signer backend
+// being unreachable or failing, rather than by the request itself.
+func isCircuitBreakerFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var statusErr *httpClient.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
@@ -0,0 +1,364 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	"github.com/dana-team/cert-external-issuer/internal/fakecertapi"
+	httpClient "github.com/dana-team/cert-external-issuer/internal/issuer/clients/http"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testFailureThreshold = 3
+	testOpenDuration     = 50 * time.Millisecond
+)
+
+var (
+	errTestTransport   = &url.Error{Op: http.MethodPost, URL: "https://cert-api.example.com", Err: errors.New("connection refused")}
+	errTestServerError = &httpClient.StatusError{StatusCode: http.StatusServiceUnavailable}
+	errTestNotFound    = &httpClient.StatusError{StatusCode: http.StatusNotFound}
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	type args struct {
+		results []error
+	}
+	type want struct {
+		state    CircuitBreakerState
+		failures int
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldStayClosedBelowFailureThreshold": {
+			args: args{results: []error{errTestTransport, errTestServerError}},
+			want: want{state: CircuitBreakerClosed, failures: 2},
+		},
+		"ShouldOpenAfterConsecutiveTransportFailures": {
+			args: args{results: []error{errTestTransport, errTestTransport, errTestTransport}},
+			want: want{state: CircuitBreakerOpen, failures: 3},
+		},
+		"ShouldOpenAfterConsecutiveServerErrors": {
+			args: args{results: []error{errTestServerError, errTestServerError, errTestServerError}},
+			want: want{state: CircuitBreakerOpen, failures: 3},
+		},
+		"ShouldResetFailuresOnSuccess": {
+			args: args{results: []error{errTestTransport, errTestServerError, nil, errTestTransport}},
+			want: want{state: CircuitBreakerClosed, failures: 1},
+		},
+		"ShouldNotCountClientErrorsAsFailures": {
+			args: args{results: []error{errTestServerError, errTestNotFound, errTestServerError, errTestServerError}},
+			want: want{state: CircuitBreakerClosed, failures: 2},
+		},
+		"ShouldNotCountOtherErrorsAsFailures": {
+			args: args{results: []error{errTestServerError, errTestServerError, errors.New("invalid response")}},
+			want: want{state: CircuitBreakerClosed, failures: 0},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			breaker := newCircuitBreaker(testFailureThreshold, time.Minute)
+			for _, result := range tc.args.results {
+				assert.NoError(t, breaker.Allow())
+				breaker.Record(context.Background(), result)
+			}
+
+			status := breaker.Status()
+			assert.Equal(t, tc.want.state, status.State)
+			assert.Equal(t, tc.want.failures, status.Failures)
+
+			err := breaker.Allow()
+			if tc.want.state != CircuitBreakerOpen {
+				assert.NoError(t, err)
+				return
+			}
+
+			var circuitOpenErr *CircuitOpenError
+			if assert.True(t, errors.As(err, &circuitOpenErr), "unexpected error: %v", err) {
+				assert.Equal(t, tc.want.failures, circuitOpenErr.Failures)
+				assert.Greater(t, circuitOpenErr.RetryAfter, time.Duration(0))
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	type args struct {
+		probeResult error
+		probeCtx    func() context.Context
+	}
+	type want struct {
+		state CircuitBreakerState
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldCloseAfterSuccessfulProbe": {
+			args: args{probeResult: nil},
+			want: want{state: CircuitBreakerClosed},
+		},
+		"ShouldOpenAfterFailedProbe": {
+			args: args{probeResult: errTestServerError},
+			want: want{state: CircuitBreakerOpen},
+		},
+		"ShouldReleaseProbeCancelledByContext": {
+			args: args{
+				probeResult: errTestTransport,
+				probeCtx: func() context.Context {
+					ctx, cancel := context.WithCancel(context.Background())
+					cancel()
+					return ctx
+				},
+			},
+			want: want{state: CircuitBreakerHalfOpen},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			breaker := newCircuitBreaker(1, testOpenDuration)
+			breaker.Record(context.Background(), errTestTransport)
+			assert.Equal(t, CircuitBreakerOpen, breaker.Status().State)
+
+			time.Sleep(testOpenDuration)
+			assert.Equal(t, CircuitBreakerHalfOpen, breaker.Status().State)
+
+			assert.NoError(t, breaker.Allow(), "expected a probe request to be allowed")
+			var circuitOpenErr *CircuitOpenError
+			assert.True(t, errors.As(breaker.Allow(), &circuitOpenErr), "expected a single probe request to be allowed")
+
+			probeCtx := context.Background()
+			if tc.args.probeCtx != nil {
+				probeCtx = tc.args.probeCtx()
+			}
+			breaker.Record(probeCtx, tc.args.probeResult)
+
+			assert.Equal(t, tc.want.state, breaker.Status().State)
+		})
+	}
+}
+
+func TestCircuitBreakerRecordHealthCheck(t *testing.T) {
+	type args struct {
+		halfOpen bool
+		healthy  bool
+	}
+	type want struct {
+		state    CircuitBreakerState
+		failures int
+		probing  bool
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldCloseOpenCircuitBreakerAfterSuccessfulHealthCheck": {
+			args: args{healthy: true},
+			want: want{state: CircuitBreakerClosed, failures: 0},
+		},
+		"ShouldKeepOpenCircuitBreakerAfterFailedHealthCheck": {
+			args: args{healthy: false},
+			want: want{state: CircuitBreakerOpen, failures: testFailureThreshold},
+		},
+		"ShouldCloseHalfOpenCircuitBreakerAfterSuccessfulHealthCheck": {
+			args: args{halfOpen: true, healthy: true},
+			want: want{state: CircuitBreakerClosed, failures: 0},
+		},
+		"ShouldKeepProbeOfHalfOpenCircuitBreakerAfterFailedHealthCheck": {
+			args: args{halfOpen: true, healthy: false},
+			want: want{state: CircuitBreakerHalfOpen, failures: testFailureThreshold, probing: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			breaker := newCircuitBreaker(testFailureThreshold, testOpenDuration)
+			for i := 0; i < testFailureThreshold; i++ {
+				breaker.Record(context.Background(), errTestServerError)
+			}
+			openedAt := breaker.openedAt
+
+			if tc.args.halfOpen {
+				time.Sleep(testOpenDuration)
+				assert.NoError(t, breaker.Allow(), "expected a probe request to be allowed")
+			}
+
+			breaker.RecordHealthCheck(tc.args.healthy)
+
+			status := breaker.Status()
+			assert.Equal(t, tc.want.state, status.State)
+			assert.Equal(t, tc.want.failures, status.Failures)
+			assert.Equal(t, tc.want.probing, breaker.probing)
+			if tc.want.state != CircuitBreakerClosed {
+				assert.Equal(t, openedAt, breaker.openedAt, "expected a failed health check not to reopen the circuit breaker")
+			}
+		})
+	}
+}
+
+func TestBuildCircuitBreaker(t *testing.T) {
+	const testAPIEndpoint = "https://cert-api.example.com/api/"
+	secretData := map[string][]byte{authorizationHeaderSecretKey: []byte(testToken)}
+
+	type args struct {
+		circuitBreaker      *certv1alpha1.CircuitBreaker
+		otherCircuitBreaker *certv1alpha1.CircuitBreaker
+	}
+	type want struct {
+		err              error
+		failureThreshold int
+		openDuration     time.Duration
+		shared           bool
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldUseDefaultsWithoutCircuitBreaker": {
+			want: want{
+				failureThreshold: defaultCircuitBreakerFailureThreshold,
+				openDuration:     defaultCircuitBreakerOpenDuration,
+				shared:           true,
+			},
+		},
+		"ShouldUseCircuitBreakerOfIssuer": {
+			args: args{
+				circuitBreaker: &certv1alpha1.CircuitBreaker{
+					FailureThreshold: 2,
+					OpenDuration:     &metav1.Duration{Duration: time.Minute},
+				},
+			},
+			want: want{
+				failureThreshold: 2,
+				openDuration:     time.Minute,
+				shared:           true,
+			},
+		},
+		"ShouldNotShareCircuitBreakerOfOtherConfiguration": {
+			args: args{
+				circuitBreaker:      &certv1alpha1.CircuitBreaker{FailureThreshold: 2},
+				otherCircuitBreaker: &certv1alpha1.CircuitBreaker{FailureThreshold: 3},
+			},
+			want: want{
+				failureThreshold: 2,
+				openDuration:     defaultCircuitBreakerOpenDuration,
+			},
+		},
+		"ShouldFailWithNonPositiveOpenDuration": {
+			args: args{
+				circuitBreaker: &certv1alpha1.CircuitBreaker{OpenDuration: &metav1.Duration{}},
+			},
+			want: want{
+				err: errInvalidCircuitBreaker,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			issuerSpec := &certv1alpha1.IssuerSpec{
+				APIEndpoint: testAPIEndpoint,
+				HTTPConfig:  certv1alpha1.HTTPConfig{CircuitBreaker: tc.args.circuitBreaker},
+			}
+
+			breaker, release, err := buildCircuitBreaker(issuerSpec, testAPIEndpoint, secretData)
+			if tc.want.err != nil {
+				assert.True(t, errors.Is(err, tc.want.err), "unexpected error: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.failureThreshold, breaker.failureThreshold)
+			assert.Equal(t, tc.want.openDuration, breaker.openDuration)
+
+			otherIssuerSpec := issuerSpec.DeepCopy()
+			if tc.args.otherCircuitBreaker != nil {
+				otherIssuerSpec.HTTPConfig.CircuitBreaker = tc.args.otherCircuitBreaker
+			}
+
+			sharedBreaker, releaseShared, err := buildCircuitBreaker(otherIssuerSpec, testAPIEndpoint, secretData)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.shared, breaker == sharedBreaker)
+
+			release()
+			releaseShared()
+			rebuiltBreaker, releaseRebuilt, err := buildCircuitBreaker(issuerSpec, testAPIEndpoint, secretData)
+			assert.NoError(t, err)
+			defer releaseRebuilt()
+			assert.NotSame(t, breaker, rebuiltBreaker, "expected the circuit breaker to be dropped once released by every signer")
+		})
+	}
+}
+
+func TestCertSignerSignWithOpenCircuitBreaker(t *testing.T) {
+	var requests atomic.Int32
+	var recovered atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		if recovered.Load() {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	issuerSpec := &certv1alpha1.IssuerSpec{
+		APIEndpoint:      server.URL + "/",
+		DownloadEndpoint: testDownloadPath,
+		Form:             fakecertapi.FormChain,
+		HTTPConfig: certv1alpha1.HTTPConfig{
+			CircuitBreaker: &certv1alpha1.CircuitBreaker{FailureThreshold: 2, OpenDuration: &metav1.Duration{Duration: time.Minute}},
+		},
+		CertificateRestrictions: certv1alpha1.Restrictions{
+			SubjectAltNamesRestrictions: certv1alpha1.SubjectAltNamesRestrictions{AllowDNSNames: true},
+		},
+	}
+	secretData := map[string][]byte{authorizationHeaderSecretKey: []byte(testToken)}
+
+	signer, err := CertSignerFromIssuerAndSecretData(issuerSpec, secretData, fake.NewClientBuilder().Build())
+	assert.NoError(t, err)
+
+	csrBytes := generateTestCSR(t)
+	for i := 0; i < 2; i++ {
+		_, err = signer.Sign(context.Background(), logr.Discard(), SignRequest{CSR: csrBytes}, Task{})
+		assert.True(t, errors.Is(err, errFailedSigningCertificate), "unexpected error: %v", err)
+	}
+
+	_, err = signer.Sign(context.Background(), logr.Discard(), SignRequest{CSR: csrBytes}, Task{})
+	var circuitOpenErr *CircuitOpenError
+	assert.True(t, errors.As(err, &circuitOpenErr), "unexpected error: %v", err)
+	assert.Equal(t, int32(2), requests.Load(), "expected no request to be sent while the circuit breaker is open")
+
+	checker, err := CertSignerHealthCheckerFromIssuerAndSecretData(issuerSpec, secretData)
+	assert.NoError(t, err)
+	assert.Equal(t, CircuitBreakerOpen, checker.(CircuitBreakerReporter).CircuitBreakerStatus().State)
+
+	assert.Error(t, checker.Check(context.Background()))
+	assert.Equal(t, int32(3), requests.Load(), "expected the health check to probe the Cert API while the circuit breaker is open")
+
+	status := checker.(CircuitBreakerReporter).CircuitBreakerStatus()
+	assert.Equal(t, CircuitBreakerOpen, status.State, "expected a failed health check to keep the circuit breaker open")
+	assert.Equal(t, 2, status.Failures, "expected a failed health check not to count as a failure")
+
+	recovered.Store(true)
+	assert.NoError(t, checker.Check(context.Background()))
+	assert.Equal(t, CircuitBreakerClosed, checker.(CircuitBreakerReporter).CircuitBreakerStatus().State, "expected a successful health check to close the circuit breaker")
+}
@@ -0,0 +1,144 @@
+package signer
+
+import (
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+	kube "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CacheKey identifies the version of an issuer, and of the objects it references, from which
+// a Signer or HealthChecker was built.
+type CacheKey struct {
+	// Name is the namespaced name of the issuer.
+	Name types.NamespacedName
+
+	// UID is the UID of the issuer, which changes when the issuer is recreated.
+	UID types.UID
+
+	// Generation is the generation of the issuer, which changes when its spec changes.
+	Generation int64
+
+	// ResourceVersions are the resourceVersions of the Secrets and ConfigMaps referenced by the issuer.
+	ResourceVersions string
+}
+
+// NewCacheKey returns the CacheKey of the issuer and the resourceVersions of the objects it references.
+func NewCacheKey(issuer kube.Object, resourceVersions ...string) CacheKey {
+	return CacheKey{
+		Name:             kube.ObjectKeyFromObject(issuer),
+		UID:              issuer.GetUID(),
+		Generation:       issuer.GetGeneration(),
+		ResourceVersions: strings.Join(resourceVersions, ","),
+	}
+}
+
+// Cache holds the Signers and HealthCheckers of the issuers, so that their HTTP clients, and with
+// them keep-alive connections, OAuth2 tokens and client certificates, are reused across reconciles.
+// An entry is rebuilt once the CacheKey of its issuer changes. A nil Cache builds on every call.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[types.NamespacedName]*cacheEntry
+}
+
+// cacheEntry holds the Signer and HealthChecker built for the version of an issuer.
+type cacheEntry struct {
+	key           CacheKey
+	signer        Signer
+	healthChecker HealthChecker
+}
+
+// closer is implemented by signers which hold an HTTP client, or state shared with the signers of other issuers.
+type closer interface {
+	Close()
+}
+
+// NewCache returns an empty Cache.
+func NewCache() *Cache {
+	return &Cache{entries: map[types.NamespacedName]*cacheEntry{}}
+}
+
+// Signer returns the cached Signer of the key, or builds and caches it.
+func (c *Cache) Signer(key CacheKey, build func() (Signer, error)) (Signer, error) {
+	if c == nil {
+		return build()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.entry(key)
+	if entry.signer == nil {
+		signer, err := build()
+		if err != nil {
+			return nil, err
+		}
+		entry.signer = signer
+	}
+
+	return entry.signer, nil
+}
+
+// HealthChecker returns the cached HealthChecker of the key, or builds and caches it.
+func (c *Cache) HealthChecker(key CacheKey, build func() (HealthChecker, error)) (HealthChecker, error) {
+	if c == nil {
+		return build()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.entry(key)
+	if entry.healthChecker == nil {
+		healthChecker, err := build()
+		if err != nil {
+			return nil, err
+		}
+		entry.healthChecker = healthChecker
+	}
+
+	return entry.healthChecker, nil
+}
+
+// Delete removes the entry of the issuer with the given name, closing its Signer and HealthChecker.
+func (c *Cache) Delete(name types.NamespacedName) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.entries[name]; ok {
+		entry.close()
+		delete(c.entries, name)
+	}
+}
+
+// entry returns the entry of the key. An entry built for a previous version of the issuer is
+// replaced by an empty one. It must be called with the lock held.
+func (c *Cache) entry(key CacheKey) *cacheEntry {
+	entry, ok := c.entries[key.Name]
+	if ok && entry.key == key {
+		return entry
+	}
+
+	if ok {
+		entry.close()
+	}
+
+	entry = &cacheEntry{key: key}
+	c.entries[key.Name] = entry
+	return entry
+}
+
+// close closes the Signer and HealthChecker of the entry, closing their idle connections and releasing
+// the state they share with the signers of other issuers.
+func (e *cacheEntry) close() {
+	for _, cached := range []interface{}{e.signer, e.healthChecker} {
+		if c, ok := cached.(closer); ok {
+			c.Close()
+		}
+	}
+}
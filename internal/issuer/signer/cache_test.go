@@ -0,0 +1,150 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	testIssuerName      = "issuer-1"
+	testIssuerNamespace = "ns-1"
+)
+
+var errFakeBuild = errors.New("fake build error")
+
+type fakeCachedSigner struct {
+	closed bool
+}
+
+func (o *fakeCachedSigner) Sign(context.Context, logr.Logger, SignRequest, Task) (SignResult, error) {
+	return SignResult{}, nil
+}
+
+func (o *fakeCachedSigner) Check(context.Context) error {
+	return nil
+}
+
+func (o *fakeCachedSigner) Close() {
+	o.closed = true
+}
+
+func TestCacheSigner(t *testing.T) {
+	type args struct {
+		uid             types.UID
+		generation      int64
+		resourceVersion string
+	}
+	type want struct {
+		rebuilt bool
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldReuseSignerOfUnchangedIssuer": {
+			args: args{uid: "uid-1", generation: 1, resourceVersion: "1"},
+		},
+		"ShouldRebuildSignerOfRecreatedIssuer": {
+			args: args{uid: "uid-2", generation: 1, resourceVersion: "1"},
+			want: want{rebuilt: true},
+		},
+		"ShouldRebuildSignerOfChangedIssuerSpec": {
+			args: args{uid: "uid-1", generation: 2, resourceVersion: "1"},
+			want: want{rebuilt: true},
+		},
+		"ShouldRebuildSignerOfChangedSecret": {
+			args: args{uid: "uid-1", generation: 1, resourceVersion: "2"},
+			want: want{rebuilt: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache()
+			builds := 0
+			build := func() (Signer, error) {
+				builds++
+				return &fakeCachedSigner{}, nil
+			}
+
+			first, err := cache.Signer(NewCacheKey(newTestIssuer("uid-1", 1), "1"), build)
+			assert.NoError(t, err)
+
+			second, err := cache.Signer(NewCacheKey(newTestIssuer(tc.args.uid, tc.args.generation), tc.args.resourceVersion), build)
+			assert.NoError(t, err)
+
+			if tc.want.rebuilt {
+				assert.Equal(t, 2, builds)
+				assert.NotSame(t, first, second)
+				assert.True(t, first.(*fakeCachedSigner).closed, "expected idle connections of the replaced signer to be closed")
+				return
+			}
+			assert.Equal(t, 1, builds)
+			assert.Same(t, first, second)
+			assert.False(t, first.(*fakeCachedSigner).closed)
+		})
+	}
+}
+
+func TestCacheHealthChecker(t *testing.T) {
+	cache := NewCache()
+	key := NewCacheKey(newTestIssuer("uid-1", 1), "1")
+
+	_, err := cache.HealthChecker(key, func() (HealthChecker, error) {
+		return nil, errFakeBuild
+	})
+	assert.True(t, errors.Is(err, errFakeBuild), "unexpected error: %v", err)
+
+	checker := &fakeCachedSigner{}
+	cached, err := cache.HealthChecker(key, func() (HealthChecker, error) {
+		return checker, nil
+	})
+	assert.NoError(t, err)
+	assert.Same(t, checker, cached)
+
+	cache.Delete(key.Name)
+	assert.True(t, checker.closed, "expected idle connections of the deleted health checker to be closed")
+
+	rebuilt, err := cache.HealthChecker(key, func() (HealthChecker, error) {
+		return &fakeCachedSigner{}, nil
+	})
+	assert.NoError(t, err)
+	assert.NotSame(t, checker, rebuilt)
+}
+
+func TestNilCacheBuildsEveryTime(t *testing.T) {
+	var cache *Cache
+	key := NewCacheKey(newTestIssuer("uid-1", 1), "1")
+	build := func() (Signer, error) {
+		return &fakeCachedSigner{}, nil
+	}
+
+	first, err := cache.Signer(key, build)
+	assert.NoError(t, err)
+
+	second, err := cache.Signer(key, build)
+	assert.NoError(t, err)
+	assert.NotSame(t, first, second)
+
+	cache.Delete(key.Name)
+}
+
+// newTestIssuer returns an Issuer with the given UID and generation.
+func newTestIssuer(uid types.UID, generation int64) *certv1alpha1.Issuer {
+	return &certv1alpha1.Issuer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       testIssuerName,
+			Namespace:  testIssuerNamespace,
+			UID:        uid,
+			Generation: generation,
+		},
+	}
+}
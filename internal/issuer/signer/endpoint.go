@@ -0,0 +1,352 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	"github.com/dana-team/cert-external-issuer/internal/issuer/clients/cert"
+	"github.com/go-logr/logr"
+)
+
+// ErrUnknownTaskEndpoint is returned when a task was accepted by an endpoint which is no longer configured
+// on the issuer, so that the task cannot be polled.
+var ErrUnknownTaskEndpoint = errors.New("task was accepted by an endpoint which is not configured on the issuer")
+
+var errDuplicateAPIEndpoint = errors.New("duplicate api endpoint")
+
+// EndpointHealth is the health of an endpoint of a signer backend, as found by its last health check.
+type EndpointHealth struct {
+	// Endpoint is the URL of the endpoint.
+	Endpoint string
+
+	// Healthy is whether the last health check of the endpoint passed, or the endpoint was not checked yet.
+	Healthy bool
+
+	// Reason is the reason of the HealthCheckError of an unhealthy endpoint.
+	Reason string
+}
+
+// EndpointHealthReporter is implemented by HealthCheckers whose signer backend has several endpoints.
+type EndpointHealthReporter interface {
+	// EndpointHealth returns the health of the endpoints of the signer backend in priority order.
+	EndpointHealth() []EndpointHealth
+}
+
+// endpoint is an endpoint of the Cert API, with the client and circuit breaker of its requests.
+type endpoint struct {
+	apiEndpoint    string
+	certClient     cert.Client
+	circuitBreaker *circuitBreaker
+	health         *endpointHealth
+
+	// releases release the state which the endpoint shares with the signers of other issuers.
+	releases []func()
+}
+
+// endpointHealth holds the outcome of the last health check of an endpoint.
+type endpointHealth struct {
+	mu  sync.Mutex
+	err *HealthCheckError
+}
+
+// endpointHealths holds the health of the endpoints which is shared by the signers, so that the Signer
+// of an issuer demotes the endpoints which failed the health checks of its HealthChecker.
+var endpointHealths = newSharedObjects[*endpointHealth]()
+
+// buildEndpointHealth returns the health of the apiEndpoint, along with the function which releases it.
+// Issuers which check the same apiEndpoint with the same credentials, auth, HTTPConfig and health check
+// endpoint share its health.
+func buildEndpointHealth(issuerSpec *certv1alpha1.IssuerSpec, apiEndpoint string, secretData map[string][]byte) (*endpointHealth, func()) {
+	key := endpointCredentialsKey(apiEndpoint, secretData, issuerSpec.Auth, issuerSpec.HTTPConfig, issuerSpec.HealthCheckEndpoint)
+	return endpointHealths.acquire(key, func() *endpointHealth {
+		return &endpointHealth{}
+	})
+}
+
+// Set records the outcome of a health check, which is nil if the endpoint is healthy.
+func (h *endpointHealth) Set(err *HealthCheckError) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.err = err
+}
+
+// Get returns the error of the last health check, or nil if the endpoint is healthy or was not checked yet.
+func (h *endpointHealth) Get() *HealthCheckError {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.err
+}
+
+// endpointSpecs returns the endpoints of the issuerSpec in priority order, starting with the APIEndpoint
+// followed by the FailoverEndpoints, with the DownloadEndpoint of the issuer as the default download endpoint.
+func endpointSpecs(issuerSpec *certv1alpha1.IssuerSpec) ([]certv1alpha1.Endpoint, error) {
+	specs := append([]certv1alpha1.Endpoint{{
+		APIEndpoint:      issuerSpec.APIEndpoint,
+		DownloadEndpoint: issuerSpec.DownloadEndpoint,
+	}}, issuerSpec.FailoverEndpoints...)
+
+	seen := make(map[string]bool, len(specs))
+	for i := range specs {
+		if specs[i].APIEndpoint == "" {
+			return nil, errMissingAPIEndpoint
+		}
+		if seen[specs[i].APIEndpoint] {
+			return nil, fmt.Errorf("%w: %q", errDuplicateAPIEndpoint, specs[i].APIEndpoint)
+		}
+		seen[specs[i].APIEndpoint] = true
+
+		if specs[i].DownloadEndpoint == "" {
+			specs[i].DownloadEndpoint = issuerSpec.DownloadEndpoint
+		}
+		if specs[i].DownloadEndpoint == "" {
+			return nil, errMissingDownloadEndpoint
+		}
+	}
+
+	return specs, nil
+}
+
+// buildEndpoints returns the endpoints of the issuerSpec in priority order, whose clients send requests
+// with the authenticator and hClient. Each endpoint has its own rate limiter, circuit breaker and health,
+// which are shared by the issuers which send requests to the endpoint with the same credentials and
+// configuration, until the endpoints are released.
+func buildEndpoints(issuerSpec *certv1alpha1.IssuerSpec, specs []certv1alpha1.Endpoint, secretData map[string][]byte, authenticator cert.Authenticator, hClient http.Client) ([]*endpoint, error) {
+	endpoints := make([]*endpoint, 0, len(specs))
+	release := func() {
+		for _, e := range endpoints {
+			e.release()
+		}
+	}
+
+	for _, spec := range specs {
+		rateLimiter, releaseRateLimiter, err := buildRateLimiter(issuerSpec, spec.APIEndpoint, secretData)
+		if err != nil {
+			release()
+			return nil, err
+		}
+
+		breaker, releaseBreaker, err := buildCircuitBreaker(issuerSpec, spec.APIEndpoint, secretData)
+		if err != nil {
+			releaseRateLimiter()
+			release()
+			return nil, err
+		}
+
+		health, releaseHealth := buildEndpointHealth(issuerSpec, spec.APIEndpoint, secretData)
+
+		endpoints = append(endpoints, &endpoint{
+			apiEndpoint: spec.APIEndpoint,
+			certClient: cert.NewClient(
+				cert.WithAuthenticator(authenticator),
+				cert.WithAPIEndpoint(spec.APIEndpoint),
+				cert.WithDownloadEndpoint(spec.DownloadEndpoint),
+				cert.WithHealthCheckEndpoint(issuerSpec.HealthCheckEndpoint),
+				cert.WithRevokeEndpoint(issuerSpec.RevokeEndpoint),
+				cert.WithForm(issuerSpec.Form),
+				cert.WithRequestProfile(buildRequestProfile(issuerSpec)),
+				cert.WithResponseMapping(cert.ResponseMapping{
+					TaskIDPath:          issuerSpec.ResponseMapping.TaskIDPath,
+					CertificatePath:     issuerSpec.ResponseMapping.CertificatePath,
+					PollingIntervalPath: issuerSpec.ResponseMapping.PollingIntervalPath,
+				}),
+				cert.WithHTTPClient(hClient),
+				cert.WithRateLimiter(rateLimiter),
+			),
+			circuitBreaker: breaker,
+			health:         health,
+			releases:       []func(){releaseRateLimiter, releaseBreaker, releaseHealth},
+		})
+	}
+
+	return endpoints, nil
+}
+
+// release releases the state which the endpoint shares with the signers of other issuers.
+func (e *endpoint) release() {
+	for _, release := range e.releases {
+		release()
+	}
+}
+
+// orderedEndpoints returns the endpoints in the order in which requests are sent to them. Endpoints which
+// failed their last health check are demoted behind the healthy ones, keeping their priority order.
+func (cs *certSigner) orderedEndpoints() []*endpoint {
+	ordered := make([]*endpoint, 0, len(cs.endpoints))
+	var demoted []*endpoint
+	for _, e := range cs.endpoints {
+		if e.health.Get() != nil {
+			demoted = append(demoted, e)
+			continue
+		}
+		ordered = append(ordered, e)
+	}
+
+	return append(ordered, demoted...)
+}
+
+// taskEndpoint returns the endpoint which accepted the task. Tasks which were submitted without recording
+// their endpoint are polled on the APIEndpoint of the issuer.
+func (cs *certSigner) taskEndpoint(task Task) (*endpoint, error) {
+	if task.Endpoint == "" {
+		return cs.endpoints[0], nil
+	}
+
+	for _, e := range cs.endpoints {
+		if e.apiEndpoint == task.Endpoint {
+			return e, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnknownTaskEndpoint, task.Endpoint)
+}
+
+// withFailover sends a request to the endpoints in order, skipping endpoints whose circuit breaker is open
+// and failing over to the next endpoint on a transport error or 5xx response. The endpoint which handled the
+// request is returned with the error of the request, or the last endpoint and its error if every request
+// failed. A nil endpoint is returned with a *CircuitOpenError if the circuit breakers let no request through.
+func (cs *certSigner) withFailover(ctx context.Context, logger logr.Logger, request func(*endpoint) error) (*endpoint, error) {
+	var last *endpoint
+	var requestErr, allowErr error
+
+	for _, e := range cs.orderedEndpoints() {
+		if err := e.circuitBreaker.Allow(); err != nil {
+			// keep the circuit breaker which lets a probe request through the soonest
+			if allowErr == nil || circuitRetryAfter(err) < circuitRetryAfter(allowErr) {
+				allowErr = err
+			}
+			continue
+		}
+
+		err := request(e)
+		e.circuitBreaker.Record(ctx, err)
+		if !isCircuitBreakerFailure(err) || ctx.Err() != nil {
+			return e, err
+		}
+
+		logger.Info("Request to the Cert API failed, failing over to the next endpoint", "endpoint", e.apiEndpoint, "error", err.Error())
+		last, requestErr = e, err
+	}
+
+	if last != nil {
+		return last, requestErr
+	}
+
+	return nil, allowErr
+}
+
+// circuitRetryAfter returns the time after which the circuit breaker which returned the error lets a
+// probe request through.
+func circuitRetryAfter(err error) time.Duration {
+	var circuitOpenErr *CircuitOpenError
+	if errors.As(err, &circuitOpenErr) {
+		return circuitOpenErr.RetryAfter
+	}
+	return 0
+}
+
+// Check probes every endpoint of the Cert API using the credentials and HTTP configuration of the issuer,
+// and records their health, so that unhealthy endpoints are demoted. A *HealthCheckError of the endpoint
+// with the highest priority is returned if no endpoint is healthy. The probes are sent even while the
+// circuit breakers are open, so that a recovered endpoint closes its circuit breaker.
+func (cs *certSigner) Check(ctx context.Context) error {
+	var healthy bool
+	var checkErr *HealthCheckError
+
+	for _, e := range cs.endpoints {
+		err := cs.checkEndpoint(ctx, e)
+		e.health.Set(err)
+		if err == nil {
+			healthy = true
+			continue
+		}
+		if checkErr == nil {
+			checkErr = err
+		}
+	}
+
+	if healthy || checkErr == nil {
+		return nil
+	}
+
+	return checkErr
+}
+
+// checkEndpoint probes an endpoint of the Cert API, returning nil if it is healthy.
+func (cs *certSigner) checkEndpoint(ctx context.Context, e *endpoint) (healthErr *HealthCheckError) {
+	defer func() {
+		e.circuitBreaker.RecordHealthCheck(healthErr == nil)
+	}()
+
+	err := e.certClient.CheckHealth(ctx, logr.FromContextOrDiscard(ctx))
+	if err == nil {
+		return nil
+	}
+
+	// without a dedicated health check endpoint, reaching the API with valid credentials is enough
+	if cs.healthCheckEndpoint == "" && isReachableResponse(err) {
+		return nil
+	}
+
+	reason := healthCheckReason(err)
+	switch reason {
+	case HealthCheckReasonTLSError:
+		err = fmt.Errorf("%w: %w", errTLSVerificationFailed, err)
+	case HealthCheckReasonProxyError:
+		err = fmt.Errorf("%w: %w", errProxyConnectionFailed, err)
+	}
+
+	if len(cs.endpoints) > 1 {
+		err = fmt.Errorf("endpoint %s: %w", e.apiEndpoint, err)
+	}
+
+	return &HealthCheckError{Reason: reason, Err: err}
+}
+
+// EndpointHealth returns the health of the endpoints of the Cert API in priority order.
+func (cs *certSigner) EndpointHealth() []EndpointHealth {
+	healths := make([]EndpointHealth, 0, len(cs.endpoints))
+	for _, e := range cs.endpoints {
+		health := EndpointHealth{Endpoint: e.apiEndpoint, Healthy: true}
+		if err := e.health.Get(); err != nil {
+			health.Healthy = false
+			health.Reason = err.Reason
+		}
+		healths = append(healths, health)
+	}
+
+	return healths
+}
+
+// CircuitBreakerStatus returns the status of the circuit breaker of the endpoint to which requests are sent
+// first, that is the first closed circuit breaker, or else the first half-open one, or else the open circuit
+// breaker which lets a probe request through the soonest.
+func (cs *certSigner) CircuitBreakerStatus() CircuitBreakerStatus {
+	var halfOpen, open *CircuitBreakerStatus
+	for _, e := range cs.orderedEndpoints() {
+		status := e.circuitBreaker.Status()
+		switch {
+		case status.State == CircuitBreakerClosed:
+			return status
+		case status.State == CircuitBreakerHalfOpen && halfOpen == nil:
+			halfOpen = &status
+		case status.State == CircuitBreakerOpen && (open == nil || status.RetryAfter < open.RetryAfter):
+			open = &status
+		}
+	}
+
+	if halfOpen != nil {
+		return *halfOpen
+	}
+	if open != nil {
+		return *open
+	}
+
+	return CircuitBreakerStatus{State: CircuitBreakerClosed}
+}
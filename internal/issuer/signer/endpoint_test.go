@@ -0,0 +1,338 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	"github.com/dana-team/cert-external-issuer/internal/fakecertapi"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testFailoverDownloadPath = "/failover/download/"
+
+func TestEndpointSpecs(t *testing.T) {
+	const (
+		testAPIEndpoint         = "https://cert-api.eu.example.com/"
+		testFailoverAPIEndpoint = "https://cert-api.us.example.com/"
+	)
+
+	type args struct {
+		apiEndpoint       string
+		downloadEndpoint  string
+		failoverEndpoints []certv1alpha1.Endpoint
+	}
+	type want struct {
+		specs []certv1alpha1.Endpoint
+		err   error
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldUseAPIEndpointWithoutFailoverEndpoints": {
+			args: args{apiEndpoint: testAPIEndpoint, downloadEndpoint: testDownloadPath},
+			want: want{
+				specs: []certv1alpha1.Endpoint{{APIEndpoint: testAPIEndpoint, DownloadEndpoint: testDownloadPath}},
+			},
+		},
+		"ShouldFollowAPIEndpointWithFailoverEndpoints": {
+			args: args{
+				apiEndpoint:      testAPIEndpoint,
+				downloadEndpoint: testDownloadPath,
+				failoverEndpoints: []certv1alpha1.Endpoint{
+					{APIEndpoint: testFailoverAPIEndpoint, DownloadEndpoint: testFailoverDownloadPath},
+				},
+			},
+			want: want{
+				specs: []certv1alpha1.Endpoint{
+					{APIEndpoint: testAPIEndpoint, DownloadEndpoint: testDownloadPath},
+					{APIEndpoint: testFailoverAPIEndpoint, DownloadEndpoint: testFailoverDownloadPath},
+				},
+			},
+		},
+		"ShouldDefaultDownloadEndpointOfFailoverEndpoint": {
+			args: args{
+				apiEndpoint:       testAPIEndpoint,
+				downloadEndpoint:  testDownloadPath,
+				failoverEndpoints: []certv1alpha1.Endpoint{{APIEndpoint: testFailoverAPIEndpoint}},
+			},
+			want: want{
+				specs: []certv1alpha1.Endpoint{
+					{APIEndpoint: testAPIEndpoint, DownloadEndpoint: testDownloadPath},
+					{APIEndpoint: testFailoverAPIEndpoint, DownloadEndpoint: testDownloadPath},
+				},
+			},
+		},
+		"ShouldFailWithoutAPIEndpoint": {
+			args: args{
+				downloadEndpoint:  testDownloadPath,
+				failoverEndpoints: []certv1alpha1.Endpoint{{APIEndpoint: testFailoverAPIEndpoint}},
+			},
+			want: want{err: errMissingAPIEndpoint},
+		},
+		"ShouldFailWithoutDownloadEndpoint": {
+			args: args{
+				apiEndpoint:       testAPIEndpoint,
+				failoverEndpoints: []certv1alpha1.Endpoint{{APIEndpoint: testFailoverAPIEndpoint, DownloadEndpoint: testFailoverDownloadPath}},
+			},
+			want: want{err: errMissingDownloadEndpoint},
+		},
+		"ShouldFailWithDuplicateAPIEndpoint": {
+			args: args{
+				apiEndpoint:       testAPIEndpoint,
+				downloadEndpoint:  testDownloadPath,
+				failoverEndpoints: []certv1alpha1.Endpoint{{APIEndpoint: testAPIEndpoint}},
+			},
+			want: want{err: errDuplicateAPIEndpoint},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			specs, err := endpointSpecs(&certv1alpha1.IssuerSpec{
+				APIEndpoint:       tc.args.apiEndpoint,
+				DownloadEndpoint:  tc.args.downloadEndpoint,
+				FailoverEndpoints: tc.args.failoverEndpoints,
+			})
+			if tc.want.err != nil {
+				assert.True(t, errors.Is(err, tc.want.err), "unexpected error: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.specs, specs)
+		})
+	}
+}
+
+func TestCertSignerSignFailsOverToNextEndpoint(t *testing.T) {
+	var primaryRequests atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		primaryRequests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+
+	server, err := fakecertapi.NewServer(
+		fakecertapi.WithToken(testToken),
+		fakecertapi.WithDownloadPath(testFailoverDownloadPath),
+	)
+	assert.NoError(t, err)
+
+	failover := httptest.NewServer(server)
+	defer failover.Close()
+
+	issuerSpec := &certv1alpha1.IssuerSpec{
+		APIEndpoint:      primary.URL + "/",
+		DownloadEndpoint: testDownloadPath,
+		FailoverEndpoints: []certv1alpha1.Endpoint{
+			{APIEndpoint: failover.URL + "/", DownloadEndpoint: testFailoverDownloadPath},
+		},
+		Form: fakecertapi.FormChain,
+		CertificateRestrictions: certv1alpha1.Restrictions{
+			SubjectAltNamesRestrictions: certv1alpha1.SubjectAltNamesRestrictions{AllowDNSNames: true},
+		},
+	}
+
+	signer, err := CertSignerFromIssuerAndSecretData(issuerSpec, map[string][]byte{authorizationHeaderSecretKey: []byte(testToken)}, fake.NewClientBuilder().Build())
+	assert.NoError(t, err)
+
+	csrBytes := generateTestCSR(t)
+
+	submitted, err := signer.Sign(context.Background(), logr.Discard(), SignRequest{CSR: csrBytes}, Task{})
+	assert.NoError(t, err)
+	assert.True(t, submitted.Pending())
+	assert.Equal(t, failover.URL+"/", submitted.Task.Endpoint, "expected the task to be recorded with the endpoint which accepted it")
+	assert.Equal(t, int32(1), primaryRequests.Load())
+
+	issued, err := signer.Sign(context.Background(), logr.Discard(), SignRequest{CSR: csrBytes}, submitted.Task)
+	assert.NoError(t, err)
+	assert.False(t, issued.Pending())
+	assert.Equal(t, int32(1), primaryRequests.Load(), "expected the task to be polled on the endpoint which accepted it")
+}
+
+func TestCertSignerSignPollsTaskOnItsEndpoint(t *testing.T) {
+	var primaryRequests, failoverRequests atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		primaryRequests.Add(1)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer primary.Close()
+
+	failover := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		failoverRequests.Add(1)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer failover.Close()
+
+	type args struct {
+		endpoint string
+	}
+	type want struct {
+		primaryRequests  int32
+		failoverRequests int32
+		err              error
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldPollTaskWithoutEndpointOnAPIEndpoint": {
+			want: want{primaryRequests: 1},
+		},
+		"ShouldPollTaskOnFailoverEndpoint": {
+			args: args{endpoint: failover.URL + "/"},
+			want: want{failoverRequests: 1},
+		},
+		"ShouldFailTaskOfUnknownEndpoint": {
+			args: args{endpoint: "https://cert-api.removed.example.com/"},
+			want: want{err: ErrUnknownTaskEndpoint},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			primaryRequests.Store(0)
+			failoverRequests.Store(0)
+
+			issuerSpec := &certv1alpha1.IssuerSpec{
+				APIEndpoint:       primary.URL + "/",
+				DownloadEndpoint:  testDownloadPath,
+				FailoverEndpoints: []certv1alpha1.Endpoint{{APIEndpoint: failover.URL + "/"}},
+				Form:              fakecertapi.FormChain,
+			}
+
+			signer, err := CertSignerFromIssuerAndSecretData(issuerSpec, map[string][]byte{authorizationHeaderSecretKey: []byte(testToken)}, fake.NewClientBuilder().Build())
+			assert.NoError(t, err)
+
+			task := Task{ID: "task-1", SubmittedAt: time.Now(), Endpoint: tc.args.endpoint}
+			result, err := signer.Sign(context.Background(), logr.Discard(), SignRequest{}, task)
+			if tc.want.err != nil {
+				assert.True(t, errors.Is(err, tc.want.err), "unexpected error: %v", err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.True(t, result.Pending())
+			assert.Equal(t, task, result.Task)
+			assert.Equal(t, tc.want.primaryRequests, primaryRequests.Load())
+			assert.Equal(t, tc.want.failoverRequests, failoverRequests.Load())
+		})
+	}
+}
+
+func TestCertSignerCheckDemotesUnhealthyEndpoints(t *testing.T) {
+	var primaryHealthy atomic.Bool
+	var primaryPosts, failoverPosts atomic.Int32
+	newEndpoint := func(healthy func() bool, posts *atomic.Int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				posts.Add(1)
+			}
+			if !healthy() {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			if r.Method == http.MethodPost {
+				_, _ = w.Write([]byte(`{"taskId": "task-1"}`))
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}))
+	}
+
+	primary := newEndpoint(primaryHealthy.Load, &primaryPosts)
+	defer primary.Close()
+
+	failover := newEndpoint(func() bool { return true }, &failoverPosts)
+	defer failover.Close()
+
+	issuerSpec := &certv1alpha1.IssuerSpec{
+		APIEndpoint:       primary.URL + "/",
+		DownloadEndpoint:  testDownloadPath,
+		FailoverEndpoints: []certv1alpha1.Endpoint{{APIEndpoint: failover.URL + "/"}},
+		Form:              fakecertapi.FormChain,
+		HTTPConfig: certv1alpha1.HTTPConfig{
+			CircuitBreaker: &certv1alpha1.CircuitBreaker{FailureThreshold: 10, OpenDuration: &metav1.Duration{Duration: time.Minute}},
+		},
+		CertificateRestrictions: certv1alpha1.Restrictions{
+			SubjectAltNamesRestrictions: certv1alpha1.SubjectAltNamesRestrictions{AllowDNSNames: true},
+		},
+	}
+	secretData := map[string][]byte{authorizationHeaderSecretKey: []byte(testToken)}
+
+	checker, err := CertSignerHealthCheckerFromIssuerAndSecretData(issuerSpec, secretData)
+	assert.NoError(t, err)
+
+	signer, err := CertSignerFromIssuerAndSecretData(issuerSpec, secretData, fake.NewClientBuilder().Build())
+	assert.NoError(t, err)
+
+	assert.NoError(t, checker.Check(context.Background()), "expected the issuer to be healthy while an endpoint is healthy")
+	assert.Equal(t, []EndpointHealth{
+		{Endpoint: primary.URL + "/", Reason: HealthCheckReasonBadResponse},
+		{Endpoint: failover.URL + "/", Healthy: true},
+	}, checker.(EndpointHealthReporter).EndpointHealth())
+
+	submitted, err := signer.Sign(context.Background(), logr.Discard(), SignRequest{CSR: generateTestCSR(t)}, Task{})
+	assert.NoError(t, err)
+	assert.Equal(t, failover.URL+"/", submitted.Task.Endpoint)
+	assert.Equal(t, int32(0), primaryPosts.Load(), "expected no request to be sent to the demoted endpoint")
+
+	primaryHealthy.Store(true)
+	assert.NoError(t, checker.Check(context.Background()))
+
+	submitted, err = signer.Sign(context.Background(), logr.Discard(), SignRequest{CSR: generateTestCSR(t)}, Task{})
+	assert.NoError(t, err)
+	assert.Equal(t, primary.URL+"/", submitted.Task.Endpoint, "expected the recovered endpoint to be promoted again")
+	assert.Equal(t, int32(1), failoverPosts.Load())
+
+	health := checker.(*certSigner).endpoints[0].health
+	assert.Same(t, health, signer.(*certSigner).endpoints[0].health)
+
+	checker.(*certSigner).Close()
+	signer.(*certSigner).Close()
+	rebuiltHealth, release := buildEndpointHealth(issuerSpec, primary.URL+"/", secretData)
+	defer release()
+	assert.NotSame(t, health, rebuiltHealth, "expected the health to be dropped once the signers which share it are closed")
+}
+
+func TestCertSignerCheckWithUnhealthyEndpoints(t *testing.T) {
+	unhealthy := func() *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+	}
+
+	primary := unhealthy()
+	defer primary.Close()
+
+	failover := unhealthy()
+	defer failover.Close()
+
+	issuerSpec := &certv1alpha1.IssuerSpec{
+		APIEndpoint:       primary.URL + "/",
+		DownloadEndpoint:  testDownloadPath,
+		FailoverEndpoints: []certv1alpha1.Endpoint{{APIEndpoint: failover.URL + "/"}},
+		Form:              fakecertapi.FormChain,
+	}
+
+	checker, err := CertSignerHealthCheckerFromIssuerAndSecretData(issuerSpec, map[string][]byte{authorizationHeaderSecretKey: []byte(testToken)})
+	assert.NoError(t, err)
+
+	err = checker.Check(context.Background())
+	var healthCheckErr *HealthCheckError
+	if assert.True(t, errors.As(err, &healthCheckErr), "unexpected error: %v", err) {
+		assert.Equal(t, HealthCheckReasonBadResponse, healthCheckErr.Reason)
+		assert.ErrorContains(t, err, primary.URL, "expected the error of the endpoint with the highest priority")
+	}
+}
@@ -0,0 +1,139 @@
+package signer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	httpClient "github.com/dana-team/cert-external-issuer/internal/issuer/clients/http"
+)
+
+const (
+	// HealthCheckReasonUnreachable is the reason used when the signer backend cannot be reached.
+	HealthCheckReasonUnreachable = "Unreachable"
+
+	// HealthCheckReasonUnauthorized is the reason used when the signer backend rejects the credentials.
+	HealthCheckReasonUnauthorized = "Unauthorized"
+
+	// HealthCheckReasonTLSError is the reason used when the TLS connection to the signer backend fails.
+	HealthCheckReasonTLSError = "TLSError"
+
+	// HealthCheckReasonBadResponse is the reason used when the signer backend returns an unexpected response.
+	HealthCheckReasonBadResponse = "BadResponse"
+
+	// HealthCheckReasonProxyError is the reason used when the signer backend cannot be reached through the proxy.
+	HealthCheckReasonProxyError = "ProxyError"
+
+	// HealthCheckReasonInvalidCA is the reason used when the CA of the signer backend cannot sign certificates.
+	HealthCheckReasonInvalidCA = "InvalidCA"
+)
+
+const (
+	tlsRemoteErrorOp    = "remote error"
+	proxyConnectErrorOp = "proxyconnect"
+)
+
+var (
+	errTLSVerificationFailed = errors.New("TLS verification of the Cert API failed, " +
+		"check the caBundle, serverName and minTLSVersion in the httpConfig of the issuer")
+	errProxyConnectionFailed = errors.New("connection to the Cert API through the proxy failed, " +
+		"check the proxyURL and proxySecretName in the httpConfig of the issuer")
+)
+
+// HealthCheckError is returned by a HealthChecker when the signer backend is unhealthy.
+type HealthCheckError struct {
+	// Reason is a CamelCase reason describing why the health check failed.
+	Reason string
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *HealthCheckError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Reason, e.Err)
+}
+
+func (e *HealthCheckError) Unwrap() error {
+	return e.Err
+}
+
+// healthCheckReason classifies a health check error into a HealthCheckError reason.
+func healthCheckReason(err error) string {
+	if isProxyError(err) {
+		return HealthCheckReasonProxyError
+	}
+
+	if isTLSError(err) {
+		return HealthCheckReasonTLSError
+	}
+
+	if isErrorUnauthorized(err) {
+		return HealthCheckReasonUnauthorized
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return HealthCheckReasonUnreachable
+	}
+
+	return HealthCheckReasonBadResponse
+}
+
+// isTLSError returns a boolean indicating whether an error was caused by establishing a TLS connection.
+func isTLSError(err error) bool {
+	var certVerificationErr *tls.CertificateVerificationError
+	var recordHeaderErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var certInvalidErr x509.CertificateInvalidError
+	var opErr *net.OpError
+
+	// alerts sent by the server, such as for an unsupported protocol version, are only exposed as a remote error
+	if errors.As(err, &opErr) && opErr.Op == tlsRemoteErrorOp {
+		return true
+	}
+
+	return errors.As(err, &certVerificationErr) ||
+		errors.As(err, &recordHeaderErr) ||
+		errors.As(err, &alertErr) ||
+		errors.As(err, &unknownAuthorityErr) ||
+		errors.As(err, &hostnameErr) ||
+		errors.As(err, &certInvalidErr)
+}
+
+// isProxyError returns a boolean indicating whether an error was caused by connecting to or authenticating with a proxy.
+func isProxyError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == proxyConnectErrorOp {
+		return true
+	}
+
+	// a proxy which rejects a CONNECT request is only exposed through the message of the error
+	return statusCode(err) == http.StatusProxyAuthRequired ||
+		strings.Contains(err.Error(), http.StatusText(http.StatusProxyAuthRequired))
+}
+
+// isErrorUnauthorized returns a boolean indicating whether the Cert API responded with Unauthorized or Forbidden.
+func isErrorUnauthorized(err error) bool {
+	code := statusCode(err)
+	return code == http.StatusUnauthorized || code == http.StatusForbidden
+}
+
+// isReachableResponse returns a boolean indicating whether an error is a response of the Cert API which shows
+// that it is reachable and accepts the credentials, that is any response other than 401, 403 and 5xx, such as
+// the Not Found or Method Not Allowed responses of an API which does not serve GET requests on its root.
+// A 407 response is sent by the proxy rather than by the Cert API, so it is not considered reachable either.
+func isReachableResponse(err error) bool {
+	var statusErr *httpClient.StatusError
+	if !errors.As(err, &statusErr) || isProxyError(err) || isErrorUnauthorized(err) {
+		return false
+	}
+
+	return statusErr.StatusCode < http.StatusInternalServerError
+}
@@ -0,0 +1,142 @@
+package signer
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmpkgutil "github.com/cert-manager/cert-manager/pkg/util/pki"
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	"github.com/dana-team/cert-external-issuer/internal/issuer/validate"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	kube "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	errMissingCACertificateData = errors.New("missing tls.crt data in secret")
+	errMissingCAKeyData         = errors.New("missing tls.key data in secret")
+	errFailedParsingCA          = errors.New("failed to parse CA certificate")
+	errFailedParsingCAKey       = errors.New("failed to parse CA private key")
+	errCAKeyMismatch            = errors.New("CA private key does not match the CA certificate")
+	errCANotCA                  = errors.New("certificate is not a CA")
+	errCANotValid               = errors.New("CA certificate is not valid at the current time")
+	errFailedBuildingTemplate   = errors.New("failed to build certificate template from CSR")
+)
+
+type localCASigner struct {
+	caCerts         []*x509.Certificate
+	caKey           crypto.Signer
+	restrictions    certv1alpha1.Restrictions
+	subjectPatterns *validate.SubjectPatterns
+	duration        time.Duration
+}
+
+// LocalCASignerHealthCheckerFromIssuerAndSecretData is a wrapper for localCASignerFromIssuerAndSecretData that returns a HealthChecker interface.
+func LocalCASignerHealthCheckerFromIssuerAndSecretData(issuerSpec *certv1alpha1.IssuerSpec, secretData map[string][]byte) (HealthChecker, error) {
+	return localCASignerFromIssuerAndSecretData(issuerSpec, secretData)
+}
+
+// LocalCASignerFromIssuerAndSecretData is a wrapper for localCASignerFromIssuerAndSecretData that returns a Signer interface.
+func LocalCASignerFromIssuerAndSecretData(issuerSpec *certv1alpha1.IssuerSpec, secretData map[string][]byte, kubeClient kube.Client) (Signer, error) {
+	if kubeClient == nil {
+		return nil, errMissingKubeClient
+	}
+
+	return localCASignerFromIssuerAndSecretData(issuerSpec, secretData)
+}
+
+// localCASignerFromIssuerAndSecretData creates a new localCASigner instance using the CA key pair in the secret data.
+func localCASignerFromIssuerAndSecretData(issuerSpec *certv1alpha1.IssuerSpec, secretData map[string][]byte) (*localCASigner, error) {
+	caCertData := secretData[corev1.TLSCertKey]
+	if len(caCertData) == 0 {
+		return nil, errMissingCACertificateData
+	}
+
+	caKeyData := secretData[corev1.TLSPrivateKeyKey]
+	if len(caKeyData) == 0 {
+		return nil, errMissingCAKeyData
+	}
+
+	caCerts, err := cmpkgutil.DecodeX509CertificateChainBytes(caCertData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errFailedParsingCA, err)
+	}
+
+	caKey, err := cmpkgutil.DecodePrivateKeyBytes(caKeyData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errFailedParsingCAKey, err)
+	}
+
+	matches, err := cmpkgutil.PublicKeyMatchesCertificate(caKey.Public(), caCerts[0])
+	if err != nil || !matches {
+		return nil, errCAKeyMismatch
+	}
+
+	subjectPatterns, err := validate.CompileSubjectPatterns(issuerSpec.CertificateRestrictions.SubjectRestrictions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &localCASigner{
+		caCerts:         caCerts,
+		caKey:           caKey,
+		restrictions:    issuerSpec.CertificateRestrictions,
+		subjectPatterns: subjectPatterns,
+		duration:        cmapi.DefaultCertificateDuration,
+	}, nil
+}
+
+// Sign validates the CSR and signs it with the CA for the requested duration, or the default
+// duration if none was requested. The certificate is issued immediately, so the task is never used.
+func (ls *localCASigner) Sign(_ context.Context, logger logr.Logger, request SignRequest, _ Task) (SignResult, error) {
+	csr, err := parseCSR(request.CSR)
+	if err != nil {
+		return SignResult{}, err
+	}
+
+	if err := validate.EnsureCSR(csr, ls.restrictions, ls.subjectPatterns); err != nil {
+		return SignResult{}, fmt.Errorf("%w: %w: %v", ErrPolicyViolation, errFailedValidatingCSR, err)
+	}
+
+	duration := ls.duration
+	if request.Duration != 0 {
+		duration = request.Duration
+	}
+
+	if err := validate.EnsureDuration(duration, ls.restrictions.ValidityRestrictions); err != nil {
+		return SignResult{}, fmt.Errorf("%w: %w: %v", ErrPolicyViolation, errFailedValidatingCSR, err)
+	}
+
+	template, err := cmpkgutil.CertificateTemplateFromCSR(csr, cmpkgutil.CertificateTemplateOverrideDuration(duration))
+	if err != nil {
+		return SignResult{}, fmt.Errorf("%w: %v", errFailedBuildingTemplate, err)
+	}
+
+	bundle, err := cmpkgutil.SignCSRTemplate(ls.caCerts, ls.caKey, template)
+	if err != nil {
+		return SignResult{}, fmt.Errorf("%w: %v", errFailedSigningCertificate, err)
+	}
+
+	logger.Info("signed certificate with local CA", "issuer", ls.caCerts[0].Subject.String())
+	return SignResult{Certificate: bundle.ChainPEM, CA: bundle.CAPEM, DurationRequested: true}, nil
+}
+
+// Check verifies that the CA certificate can currently be used to sign certificates.
+func (ls *localCASigner) Check(_ context.Context) error {
+	ca := ls.caCerts[0]
+	if !ca.IsCA {
+		return &HealthCheckError{Reason: HealthCheckReasonInvalidCA, Err: errCANotCA}
+	}
+
+	now := time.Now()
+	if now.Before(ca.NotBefore) || now.After(ca.NotAfter) {
+		return &HealthCheckError{Reason: HealthCheckReasonInvalidCA, Err: fmt.Errorf("%w: valid from %s to %s", errCANotValid, ca.NotBefore, ca.NotAfter)}
+	}
+
+	return nil
+}
@@ -0,0 +1,300 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmpkgutil "github.com/cert-manager/cert-manager/pkg/util/pki"
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testCommonName = "test.example.com"
+
+func TestLocalCASign(t *testing.T) {
+	type args struct {
+		restrictions certv1alpha1.Restrictions
+		duration     time.Duration
+	}
+	type want struct {
+		error    error
+		duration time.Duration
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldSignCSR": {
+			args: args{
+				restrictions: certv1alpha1.Restrictions{
+					SubjectAltNamesRestrictions: certv1alpha1.SubjectAltNamesRestrictions{AllowDNSNames: true},
+				},
+			},
+			want: want{
+				duration: cmapi.DefaultCertificateDuration,
+			},
+		},
+		"ShouldSignCSRForRequestedDuration": {
+			args: args{
+				restrictions: certv1alpha1.Restrictions{
+					SubjectAltNamesRestrictions: certv1alpha1.SubjectAltNamesRestrictions{AllowDNSNames: true},
+					ValidityRestrictions: certv1alpha1.ValidityRestrictions{
+						MinDuration: &metav1.Duration{Duration: time.Hour},
+						MaxDuration: &metav1.Duration{Duration: 24 * time.Hour},
+					},
+				},
+				duration: 2 * time.Hour,
+			},
+			want: want{
+				duration: 2 * time.Hour,
+			},
+		},
+		"ShouldFailOnRequestedDurationShorterThanMinDuration": {
+			args: args{
+				restrictions: certv1alpha1.Restrictions{
+					SubjectAltNamesRestrictions: certv1alpha1.SubjectAltNamesRestrictions{AllowDNSNames: true},
+					ValidityRestrictions: certv1alpha1.ValidityRestrictions{
+						MinDuration: &metav1.Duration{Duration: time.Hour},
+					},
+				},
+				duration: time.Minute,
+			},
+			want: want{
+				error: errFailedValidatingCSR,
+			},
+		},
+		"ShouldFailOnDefaultDurationLongerThanMaxDuration": {
+			args: args{
+				restrictions: certv1alpha1.Restrictions{
+					SubjectAltNamesRestrictions: certv1alpha1.SubjectAltNamesRestrictions{AllowDNSNames: true},
+					ValidityRestrictions: certv1alpha1.ValidityRestrictions{
+						MaxDuration: &metav1.Duration{Duration: 24 * time.Hour},
+					},
+				},
+			},
+			want: want{
+				error: errFailedValidatingCSR,
+			},
+		},
+		"ShouldSignCSRMatchingSubjectPattern": {
+			args: args{
+				restrictions: certv1alpha1.Restrictions{
+					SubjectAltNamesRestrictions: certv1alpha1.SubjectAltNamesRestrictions{AllowDNSNames: true},
+					SubjectRestrictions: certv1alpha1.SubjectRestrictions{
+						AllowedOrganizations: []string{"allowed"},
+						AllowedPatterns:      certv1alpha1.SubjectPatterns{Organizations: []string{"te*"}},
+					},
+				},
+			},
+			want: want{
+				duration: cmapi.DefaultCertificateDuration,
+			},
+		},
+		"ShouldFailOnCSRNotMatchingSubjectPattern": {
+			args: args{
+				restrictions: certv1alpha1.Restrictions{
+					SubjectAltNamesRestrictions: certv1alpha1.SubjectAltNamesRestrictions{AllowDNSNames: true},
+					SubjectRestrictions: certv1alpha1.SubjectRestrictions{
+						AllowedPatterns: certv1alpha1.SubjectPatterns{
+							Syntax:        certv1alpha1.PatternSyntaxRegex,
+							Organizations: []string{"team-[0-9]+"},
+						},
+					},
+				},
+			},
+			want: want{
+				error: errFailedValidatingCSR,
+			},
+		},
+		"ShouldFailOnRestrictedCSR": {
+			args: args{
+				restrictions: certv1alpha1.Restrictions{
+					SubjectAltNamesRestrictions: certv1alpha1.SubjectAltNamesRestrictions{AllowDNSNames: true},
+					SubjectRestrictions: certv1alpha1.SubjectRestrictions{
+						AllowedOrganizations: []string{"allowed"},
+					},
+				},
+			},
+			want: want{
+				error: errFailedValidatingCSR,
+			},
+		},
+	}
+
+	caData := generateTestCA(t, time.Now().Add(time.Hour))
+	csrBytes := generateTestCSR(t)
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			issuerSpec := &certv1alpha1.IssuerSpec{
+				Backend:                 certv1alpha1.BackendLocalCA,
+				CertificateRestrictions: tc.args.restrictions,
+			}
+
+			signer, err := NewDefaultRegistry().BuildSigner(issuerSpec, caData, fake.NewClientBuilder().Build())
+			assert.NoError(t, err)
+
+			result, err := signer.Sign(context.Background(), logr.Discard(), SignRequest{CSR: csrBytes, Duration: tc.args.duration}, Task{})
+			if tc.want.error != nil {
+				assert.True(t, errors.Is(err, tc.want.error), "unexpected error: %v", err)
+				return
+			}
+
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.False(t, result.Pending())
+
+			certificate, err := cmpkgutil.DecodeX509CertificateBytes(result.Certificate)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, testCommonName, certificate.Subject.CommonName)
+			assert.Equal(t, tc.want.duration, certificate.NotAfter.Sub(certificate.NotBefore))
+			assert.Equal(t, caData[corev1.TLSCertKey], result.CA)
+		})
+	}
+}
+
+func TestLocalCABuildAndCheck(t *testing.T) {
+	type args struct {
+		secretData map[string][]byte
+	}
+	type want struct {
+		buildError error
+		checkError error
+	}
+
+	validCA := generateTestCA(t, time.Now().Add(time.Hour))
+	expiredCA := generateTestCA(t, time.Now().Add(-time.Minute))
+	otherCA := generateTestCA(t, time.Now().Add(time.Hour))
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldBeHealthyWithValidCA": {
+			args: args{
+				secretData: validCA,
+			},
+		},
+		"ShouldBeUnhealthyWithExpiredCA": {
+			args: args{
+				secretData: expiredCA,
+			},
+			want: want{
+				checkError: errCANotValid,
+			},
+		},
+		"ShouldFailWithoutCertificate": {
+			args: args{
+				secretData: map[string][]byte{corev1.TLSPrivateKeyKey: validCA[corev1.TLSPrivateKeyKey]},
+			},
+			want: want{
+				buildError: errMissingCACertificateData,
+			},
+		},
+		"ShouldFailWithoutKey": {
+			args: args{
+				secretData: map[string][]byte{corev1.TLSCertKey: validCA[corev1.TLSCertKey]},
+			},
+			want: want{
+				buildError: errMissingCAKeyData,
+			},
+		},
+		"ShouldFailWithMismatchedKey": {
+			args: args{
+				secretData: map[string][]byte{
+					corev1.TLSCertKey:       validCA[corev1.TLSCertKey],
+					corev1.TLSPrivateKeyKey: otherCA[corev1.TLSPrivateKeyKey],
+				},
+			},
+			want: want{
+				buildError: errCAKeyMismatch,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			issuerSpec := &certv1alpha1.IssuerSpec{Backend: certv1alpha1.BackendLocalCA}
+
+			checker, err := NewDefaultRegistry().BuildHealthChecker(issuerSpec, tc.args.secretData)
+			if tc.want.buildError != nil {
+				assert.True(t, errors.Is(err, tc.want.buildError), "unexpected error: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+
+			err = checker.Check(context.Background())
+			if tc.want.checkError != nil {
+				var healthCheckErr *HealthCheckError
+				assert.True(t, errors.As(err, &healthCheckErr))
+				assert.Equal(t, HealthCheckReasonInvalidCA, healthCheckErr.Reason)
+				assert.True(t, errors.Is(err, tc.want.checkError), "unexpected error: %v", err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// generateTestCA is a helper to generate the secret data of a self-signed CA which expires at notAfter.
+func generateTestCA(t *testing.T, notAfter time.Time) map[string][]byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             notAfter.Add(-24 * time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	keyPEM, err := cmpkgutil.EncodeECPrivateKey(key)
+	assert.NoError(t, err)
+
+	return map[string][]byte{
+		corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		corev1.TLSPrivateKeyKey: keyPEM,
+	}
+}
+
+// generateTestCSR is a helper to generate a PEM encoded CSR.
+func generateTestCSR(t *testing.T) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	csrBytes, err := cmpkgutil.EncodeCSR(&x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: testCommonName, Organization: []string{"test"}},
+		DNSNames: []string{testCommonName},
+	}, key)
+	assert.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: certificateRequestBlockType, Bytes: csrBytes})
+}
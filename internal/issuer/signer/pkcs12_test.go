@@ -0,0 +1,152 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cmpkgutil "github.com/cert-manager/cert-manager/pkg/util/pki"
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+const (
+	testPKCS12Token       = "dummy-token"
+	testPKCS12Password    = "dummy-password"
+	testPKCS12CommonName  = "test.example.com"
+	testPKCS12TaskID      = "task-1"
+	testPKCS12DownloadDir = "/download/"
+)
+
+func TestCertSignerSignInPKCS12Form(t *testing.T) {
+	archive, caPEM := generateTestPKCS12Archive(t, testPKCS12Password)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/"+testPKCS12TaskID+testPKCS12DownloadDir+formPKCS12, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"data": base64.StdEncoding.EncodeToString(archive)})
+	}))
+	defer server.Close()
+
+	type args struct {
+		secretData map[string][]byte
+	}
+	type want struct {
+		buildErr error
+		signErr  error
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldDecodePKCS12Archive": {
+			args: args{secretData: map[string][]byte{
+				authorizationHeaderSecretKey: []byte(testPKCS12Token),
+				pkcs12PasswordSecretKey:      []byte(testPKCS12Password),
+			}},
+		},
+		"ShouldFailWithoutPKCS12Password": {
+			args: args{secretData: map[string][]byte{
+				authorizationHeaderSecretKey: []byte(testPKCS12Token),
+			}},
+			want: want{buildErr: errMissingPKCS12PasswordData},
+		},
+		"ShouldFailWithWrongPKCS12Password": {
+			args: args{secretData: map[string][]byte{
+				authorizationHeaderSecretKey: []byte(testPKCS12Token),
+				pkcs12PasswordSecretKey:      []byte("wrong-password"),
+			}},
+			want: want{signErr: errFailedDecodingPKCS12},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			issuerSpec := &certv1alpha1.IssuerSpec{
+				APIEndpoint:      server.URL + "/",
+				DownloadEndpoint: testPKCS12DownloadDir,
+				Form:             formPKCS12,
+			}
+
+			signer, err := CertSignerFromIssuerAndSecretData(issuerSpec, tc.args.secretData, fake.NewClientBuilder().Build())
+			if tc.want.buildErr != nil {
+				assert.True(t, errors.Is(err, tc.want.buildErr), "unexpected error: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+
+			task := Task{ID: testPKCS12TaskID, SubmittedAt: time.Now()}
+			issued, err := signer.Sign(context.Background(), logr.Discard(), SignRequest{}, task)
+			if tc.want.signErr != nil {
+				assert.True(t, errors.Is(err, tc.want.signErr), "unexpected error: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.False(t, issued.Pending())
+
+			certificate, err := cmpkgutil.DecodeX509CertificateBytes(issued.Certificate)
+			assert.NoError(t, err)
+			assert.Equal(t, testPKCS12CommonName, certificate.Subject.CommonName)
+			assert.Equal(t, caPEM, issued.CA)
+		})
+	}
+}
+
+// generateTestPKCS12Archive returns a PKCS#12 archive of a certificate signed by a new CA,
+// encrypted with the password, along with the PEM encoded CA certificate.
+func generateTestPKCS12Archive(t *testing.T, password string) ([]byte, []byte) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	assert.NoError(t, err)
+	ca, err := x509.ParseCertificate(caDER)
+	assert.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: testPKCS12CommonName},
+		DNSNames:     []string{testPKCS12CommonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	assert.NoError(t, err)
+	certificate, err := x509.ParseCertificate(certificateDER)
+	assert.NoError(t, err)
+
+	archive, err := pkcs12.Modern.Encode(key, certificate, []*x509.Certificate{ca}, password)
+	assert.NoError(t, err)
+
+	caPEM, err := cmpkgutil.EncodeX509(ca)
+	assert.NoError(t, err)
+
+	return archive, caPEM
+}
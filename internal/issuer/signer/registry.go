@@ -0,0 +1,113 @@
+package signer
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	kube "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	errMissingBackendName     = errors.New("missing backend name")
+	errMissingBackendBuilders = errors.New("missing backend builders")
+	errBackendRegistered      = errors.New("backend is already registered")
+	errUnknownBackend         = errors.New("unknown signer backend")
+)
+
+// Backend holds the builders of a signer backend which an Issuer can target.
+type Backend struct {
+	// SignerBuilder creates the Signer used to sign CertificateRequests.
+	SignerBuilder SignerBuilder
+
+	// HealthCheckerBuilder creates the HealthChecker used to check the Issuer.
+	HealthCheckerBuilder HealthCheckerBuilder
+}
+
+// Registry holds the signer backends keyed by name, and dispatches to the backend
+// referenced by the Backend field of the IssuerSpec.
+type Registry struct {
+	mu       sync.RWMutex
+	backends map[string]Backend
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{backends: map[string]Backend{}}
+}
+
+// NewDefaultRegistry returns a Registry holding the built-in signer backends.
+func NewDefaultRegistry() *Registry {
+	registry := NewRegistry()
+	registry.backends[certv1alpha1.BackendCertAPI] = Backend{
+		SignerBuilder:        CertSignerFromIssuerAndSecretData,
+		HealthCheckerBuilder: CertSignerHealthCheckerFromIssuerAndSecretData,
+	}
+	registry.backends[certv1alpha1.BackendLocalCA] = Backend{
+		SignerBuilder:        LocalCASignerFromIssuerAndSecretData,
+		HealthCheckerBuilder: LocalCASignerHealthCheckerFromIssuerAndSecretData,
+	}
+
+	return registry
+}
+
+// Register adds a signer backend to the Registry under the given name.
+func (r *Registry) Register(name string, backend Backend) error {
+	if name == "" {
+		return errMissingBackendName
+	}
+
+	if backend.SignerBuilder == nil || backend.HealthCheckerBuilder == nil {
+		return fmt.Errorf("%w: %q", errMissingBackendBuilders, name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.backends[name]; ok {
+		return fmt.Errorf("%w: %q", errBackendRegistered, name)
+	}
+
+	r.backends[name] = backend
+	return nil
+}
+
+// BuildSigner is a SignerBuilder which uses the backend referenced by the issuerSpec.
+func (r *Registry) BuildSigner(issuerSpec *certv1alpha1.IssuerSpec, secretData map[string][]byte, kubeClient kube.Client) (Signer, error) {
+	backend, err := r.backend(issuerSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	return backend.SignerBuilder(issuerSpec, secretData, kubeClient)
+}
+
+// BuildHealthChecker is a HealthCheckerBuilder which uses the backend referenced by the issuerSpec.
+func (r *Registry) BuildHealthChecker(issuerSpec *certv1alpha1.IssuerSpec, secretData map[string][]byte) (HealthChecker, error) {
+	backend, err := r.backend(issuerSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	return backend.HealthCheckerBuilder(issuerSpec, secretData)
+}
+
+// backend returns the backend referenced by the issuerSpec. Issuers which do not
+// reference a backend use the Cert API backend.
+func (r *Registry) backend(issuerSpec *certv1alpha1.IssuerSpec) (Backend, error) {
+	name := issuerSpec.Backend
+	if name == "" {
+		name = certv1alpha1.BackendCertAPI
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	backend, ok := r.backends[name]
+	if !ok {
+		return Backend{}, fmt.Errorf("%w: %q", errUnknownBackend, name)
+	}
+
+	return backend, nil
+}
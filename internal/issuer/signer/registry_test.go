@@ -0,0 +1,148 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	kube "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testBackend = "test"
+
+type fakeBackend struct{}
+
+func (f *fakeBackend) Sign(context.Context, logr.Logger, SignRequest, Task) (SignResult, error) {
+	return SignResult{}, nil
+}
+
+func (f *fakeBackend) Check(context.Context) error {
+	return nil
+}
+
+var testBackendBuilders = Backend{
+	SignerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (Signer, error) {
+		return &fakeBackend{}, nil
+	},
+	HealthCheckerBuilder: func(*certv1alpha1.IssuerSpec, map[string][]byte) (HealthChecker, error) {
+		return &fakeBackend{}, nil
+	},
+}
+
+func TestRegister(t *testing.T) {
+	type args struct {
+		name    string
+		backend Backend
+	}
+	type want struct {
+		error error
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldRegisterBackend": {
+			args: args{
+				name:    testBackend,
+				backend: testBackendBuilders,
+			},
+		},
+		"ShouldFailWithoutName": {
+			args: args{
+				backend: testBackendBuilders,
+			},
+			want: want{
+				error: errMissingBackendName,
+			},
+		},
+		"ShouldFailWithoutBuilders": {
+			args: args{
+				name: testBackend,
+			},
+			want: want{
+				error: errMissingBackendBuilders,
+			},
+		},
+		"ShouldFailOnRegisteredBackend": {
+			args: args{
+				name:    certv1alpha1.BackendCertAPI,
+				backend: testBackendBuilders,
+			},
+			want: want{
+				error: errBackendRegistered,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := NewDefaultRegistry().Register(tc.args.name, tc.args.backend)
+			if tc.want.error != nil {
+				assert.True(t, errors.Is(err, tc.want.error), "unexpected error: %v", err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	type args struct {
+		backend string
+	}
+	type want struct {
+		error error
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldBuildRegisteredBackend": {
+			args: args{
+				backend: testBackend,
+			},
+		},
+		"ShouldFailOnUnknownBackend": {
+			args: args{
+				backend: "unknown",
+			},
+			want: want{
+				error: errUnknownBackend,
+			},
+		},
+		"ShouldDefaultToCertAPIBackend": {
+			args: args{
+				backend: "",
+			},
+			want: want{
+				error: errMissingTokenData,
+			},
+		},
+	}
+
+	registry := NewDefaultRegistry()
+	assert.NoError(t, registry.Register(testBackend, testBackendBuilders))
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			issuerSpec := &certv1alpha1.IssuerSpec{Backend: tc.args.backend}
+
+			_, signerErr := registry.BuildSigner(issuerSpec, nil, fake.NewClientBuilder().Build())
+			_, checkerErr := registry.BuildHealthChecker(issuerSpec, nil)
+
+			for _, err := range []error{signerErr, checkerErr} {
+				if tc.want.error != nil {
+					assert.True(t, errors.Is(err, tc.want.error), "unexpected error: %v", err)
+				} else {
+					assert.NoError(t, err)
+				}
+			}
+		})
+	}
+}
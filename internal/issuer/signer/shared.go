@@ -0,0 +1,93 @@
+package signer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+// sharedObjects holds the objects which are shared by the signers built from the same configuration, such as
+// the rate limiter of the issuers which share the quota of the Cert API. Signers acquire the objects they use,
+// and release them once they are dropped from the Cache, so that an object is dropped along with its last user.
+type sharedObjects[T any] struct {
+	mu      sync.Mutex
+	objects map[string]*sharedObject[T]
+}
+
+// sharedObject is an object held by sharedObjects, along with the number of signers which use it.
+type sharedObject[T any] struct {
+	value T
+	users int
+}
+
+// newSharedObjects returns an empty sharedObjects.
+func newSharedObjects[T any]() *sharedObjects[T] {
+	return &sharedObjects[T]{objects: map[string]*sharedObject[T]{}}
+}
+
+// acquire returns the object shared under the key, building it if no signer uses one, along with the
+// function which releases it. Releasing an object more than once has no effect.
+func (s *sharedObjects[T]) acquire(key string, build func() T) (T, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	object, ok := s.objects[key]
+	if !ok {
+		object = &sharedObject[T]{value: build()}
+		s.objects[key] = object
+	}
+	object.users++
+
+	var once sync.Once
+	return object.value, func() {
+		once.Do(func() {
+			s.release(key, object)
+		})
+	}
+}
+
+// release removes a user of the object, dropping the object once it has no users left.
+func (s *sharedObjects[T]) release(key string, object *sharedObject[T]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	object.users--
+	if object.users == 0 && s.objects[key] == object {
+		delete(s.objects, key)
+	}
+}
+
+// len returns the number of objects which are in use.
+func (s *sharedObjects[T]) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.objects)
+}
+
+// endpointCredentialsKey returns the key under which the state shared by the issuers which send requests
+// to the apiEndpoint with the secret data and the given configuration is held. The secret data is hashed
+// so that it is not kept in the key.
+func endpointCredentialsKey(apiEndpoint string, secretData map[string][]byte, config ...interface{}) string {
+	keys := make([]string, 0, len(secretData))
+	for key := range secretData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	hash.Write([]byte(apiEndpoint))
+	for _, key := range keys {
+		hash.Write([]byte("\n" + key + "="))
+		hash.Write(secretData[key])
+	}
+	for _, value := range config {
+		encoded, _ := json.Marshal(value)
+		hash.Write([]byte("\n"))
+		hash.Write(encoded)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,37 @@
+package signer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharedObjects(t *testing.T) {
+	objects := newSharedObjects[*int]()
+	var builds int
+	build := func() *int {
+		builds++
+		value := builds
+		return &value
+	}
+
+	first, releaseFirst := objects.acquire(testIssuerName, build)
+	second, releaseSecond := objects.acquire(testIssuerName, build)
+	assert.Same(t, first, second, "expected the users of the same key to share the object")
+
+	other, releaseOther := objects.acquire(testIssuerName+"-other", build)
+	assert.NotSame(t, first, other, "expected the users of another key not to share the object")
+	releaseOther()
+
+	releaseFirst()
+	releaseFirst()
+	assert.Equal(t, 1, objects.len(), "expected the object to be kept until its last user releases it")
+
+	releaseSecond()
+	assert.Equal(t, 0, objects.len(), "expected the object to be dropped once its last user released it")
+
+	rebuilt, releaseRebuilt := objects.acquire(testIssuerName, build)
+	defer releaseRebuilt()
+	assert.NotSame(t, first, rebuilt)
+	assert.Equal(t, 3, builds)
+}
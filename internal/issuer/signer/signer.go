@@ -9,55 +9,131 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
-	"strings"
+	"text/template"
 	"time"
 
+	"github.com/dana-team/cert-external-issuer/internal/issuer/certhandler"
 	"github.com/dana-team/cert-external-issuer/internal/issuer/validate"
 
 	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/client-go/util/retry"
 
 	cmpkgutil "github.com/cert-manager/cert-manager/pkg/util/pki"
 	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
 	"github.com/dana-team/cert-external-issuer/internal/issuer/clients/cert"
+	httpClient "github.com/dana-team/cert-external-issuer/internal/issuer/clients/http"
 	"github.com/go-logr/logr"
+	"golang.org/x/oauth2"
 	kube "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
 	defaultWaitTimeout           = 5 * time.Minute
+	defaultMaxTaskAge            = 24 * time.Hour
 	defaultRetryDuration         = 5 * time.Second
 	defaultRetrySteps            = 10
 	defaultRetryFactor           = 1.0
 	defaultRetryJitter           = 0.1
 	authorizationHeaderSecretKey = "token"
+	pkcs12PasswordSecretKey      = "pkcs12Password"
+	formPKCS12                   = "pkcs12"
 	certificateRequestBlockType  = "CERTIFICATE REQUEST"
+	certificateBlockType         = "CERTIFICATE"
+	defaultRateLimitBurst        = 1
+	maxServerDelay               = time.Hour
 )
 
+const (
+	// ClientCertificateSecretKey is the key of the secret data which holds the PEM encoded client
+	// certificate presented in HTTP requests, as added from the Secret referenced in the HTTPConfig.
+	ClientCertificateSecretKey = "httpConfig.clientCertificate.tls.crt"
+
+	// ClientKeySecretKey is the key of the secret data which holds the PEM encoded private key of
+	// the client certificate.
+	ClientKeySecretKey = "httpConfig.clientCertificate.tls.key"
+
+	// CABundleSecretKey is the key of the secret data which holds the PEM encoded CA bundle used to
+	// verify the Cert API, as added from the Secret or ConfigMap referenced in the HTTPConfig.
+	CABundleSecretKey = "httpConfig.caBundle"
+
+	// ProxyUsernameSecretKey is the key of the secret data which holds the username with which the
+	// proxy is authenticated, as added from the Secret referenced in the HTTPConfig.
+	ProxyUsernameSecretKey = "httpConfig.proxy.username"
+
+	// ProxyPasswordSecretKey is the key of the secret data which holds the password with which the
+	// proxy is authenticated.
+	ProxyPasswordSecretKey = "httpConfig.proxy.password"
+)
+
+// ErrInvalidTLSConfig is returned when the TLS configuration in the HTTPConfig of the issuer is invalid.
+var ErrInvalidTLSConfig = errors.New("invalid TLS configuration")
+
+// ErrRequestRejected is returned when the Cert API rejected a request with a 400, 401 or 403 response,
+// so that retrying the request would not help.
+var ErrRequestRejected = errors.New("request rejected by the Cert API")
+
+// ErrPolicyViolation is returned when a request violates the restrictions of the issuer, such as its
+// allowed subjects or validity, so that retrying the request would not help.
+var ErrPolicyViolation = errors.New("request violates the restrictions of the issuer")
+
+// ErrTaskExpired is returned when the Cert API has not issued the certificate of a task within the
+// maximum task age of the issuer, so that the task is not polled forever.
+var ErrTaskExpired = errors.New("task was not issued within the maximum task age")
+
 var (
 	errMissingTokenData           = errors.New("missing token data in secret")
 	errMissingAPIEndpoint         = errors.New("missing api endpoint")
 	errMissingDownloadEndpoint    = errors.New("missing download endpoint")
 	errMissingKubeClient          = errors.New("missing kube client")
 	errMissingForm                = errors.New("missing form")
+	errMissingPKCS12PasswordData  = errors.New("missing pkcs12Password data in secret")
 	errFailedBuildingRetryBackoff = errors.New("failed to build retry backoff")
 	errFailedSigningCertificate   = errors.New("failed to sign certificate")
+	errFailedDownloadCertificate  = errors.New("failed to download certificate")
+	errMissingTaskID              = errors.New("missing task ID in Cert API response")
+	errMissingRevokeEndpoint      = errors.New("missing revoke endpoint")
+	errFailedRevokingCertificate  = errors.New("failed to revoke certificate")
 	errFailedValidatingCSR        = errors.New("failed to validate CSR")
 	errFailedParsingCSR           = errors.New("failed to parse CSR, PEM block type must be CERTIFICATE REQUEST, actual")
 	errFailedDecodingData         = errors.New("failed to decode Certificate data")
 	errFailedParsingCertificate   = errors.New("failed to parse Certificate")
+	errFailedDecodingPKCS12       = errors.New("failed to decode PKCS#12 Certificate")
+	errUnsupportedCertEncoding    = errors.New("unsupported certificate encoding")
+	errMissingClientCertificate   = errors.New("missing tls.crt or tls.key data in client certificate secret")
+	errFailedLoadingClientCert    = errors.New("failed to load client certificate")
+	errInvalidCABundle            = errors.New("no PEM encoded certificates found in CA bundle")
+	errUnsupportedTLSVersion      = errors.New("unsupported minimum TLS version")
+	errInvalidProxyURL            = errors.New("invalid proxy URL")
+	errMissingProxyCredentials    = errors.New("missing username or password data in proxy secret")
+	errInvalidRateLimit           = errors.New("invalid rate limit")
+	errInvalidMaxTaskAge          = errors.New("invalid max task age")
 )
 
+// rateLimiters holds the rate limiters which are shared by the signers of the issuers with the same
+// endpoint, credentials and rate limit.
+var rateLimiters = newSharedObjects[*httpClient.RateLimiter]()
+
 type certSigner struct {
-	certClient   cert.Client
-	waitBackoff  wait.Backoff
-	restrictions certv1alpha1.Restrictions
+	endpoints           []*endpoint
+	httpClient          http.Client
+	waitBackoff         wait.Backoff
+	maxTaskAge          time.Duration
+	restrictions        certv1alpha1.Restrictions
+	subjectPatterns     *validate.SubjectPatterns
+	durationParameter   string
+	attributes          map[string]*template.Template
+	healthCheckEndpoint string
+	revokeEndpoint      string
+	form                string
+	certificateEncoding string
+	pkcs12Password      string
 }
 
 // HealthChecker defines the interface for health check implementations.
 type HealthChecker interface {
-	Check() error
+	// Check probes the signer backend. A *HealthCheckError is returned if it is unhealthy.
+	Check(ctx context.Context) error
 }
 
 // HealthCheckerBuilder creates a HealthChecker from issuer spec and secret data.
@@ -65,37 +141,123 @@ type HealthCheckerBuilder func(*certv1alpha1.IssuerSpec, map[string][]byte) (Hea
 
 // Signer defines the interface for signing certificates.
 type Signer interface {
-	Sign(ctx context.Context, logger logr.Logger, csrBytes []byte) ([]byte, []byte, error)
+	// Sign submits the CSR of the request for signing if the task is empty, and otherwise polls
+	// the given task for the signed certificate.
+	Sign(ctx context.Context, logger logr.Logger, request SignRequest, task Task) (SignResult, error)
+}
+
+// Revoker defines the interface for revoking certificates. It is implemented by the
+// Signers of backends which support revocation.
+type Revoker interface {
+	// Revoke revokes the certificate with the given serial number, in hexadecimal form.
+	Revoke(ctx context.Context, logger logr.Logger, serialNumber string) error
+}
+
+// SignRequest is a request to sign a CSR.
+type SignRequest struct {
+	// CSR is the PEM encoded CSR.
+	CSR []byte
+
+	// Duration is the requested duration of the certificate, or zero if the default duration of
+	// the signer backend applies.
+	Duration time.Duration
+
+	// Metadata describes the CertificateRequest for which the CSR is signed.
+	Metadata RequestMetadata
+}
+
+// Task identifies a signing request which was accepted by the signer backend.
+type Task struct {
+	// ID is the identifier assigned to the signing request by the signer backend.
+	ID string
+
+	// SubmittedAt is the time at which the signing request was accepted.
+	SubmittedAt time.Time
+
+	// PollingInterval is the interval at which the signer backend asked for the task to be polled,
+	// or zero if the retry backoff of the issuer applies.
+	PollingInterval time.Duration
+
+	// Endpoint is the endpoint of the signer backend which accepted the signing request, and on which
+	// the task is polled. An empty Endpoint stands for the primary endpoint.
+	Endpoint string
+}
+
+// RetryAfterError is returned when the signer backend failed a request with a Retry-After header,
+// such as in a Too Many Requests or Service Unavailable response.
+type RetryAfterError struct {
+	// Err is the error of the request.
+	Err error
+
+	// RetryAfter is the time which the signer backend asked to wait before retrying the request.
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%v, retrying in %s", e.Err, e.RetryAfter)
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// SignResult is the outcome of a Sign call. It either holds the signed certificate
+// or the Task which should be polled again after RequeueAfter.
+type SignResult struct {
+	// Certificate is the signed certificate chain.
+	Certificate []byte
+
+	// CA is the CA of the signed certificate.
+	CA []byte
+
+	// Task is the signing task which has not yet produced a certificate.
+	Task Task
+
+	// RequeueAfter is the time to wait before polling the Task again.
+	RequeueAfter time.Duration
+
+	// DurationRequested is whether the requested duration was passed on to the signer backend, so that
+	// the signed certificate is expected to be valid for that duration.
+	DurationRequested bool
+}
+
+// Pending returns whether the certificate has not yet been issued.
+func (r SignResult) Pending() bool {
+	return len(r.Certificate) == 0
 }
 
 // SignerBuilder creates a Signer from issuer spec, secret data, and a kube client.
 type SignerBuilder func(*certv1alpha1.IssuerSpec, map[string][]byte, kube.Client) (Signer, error)
 
-// CertSignerHealthCheckerFromIssuerAndSecretData returns a HealthChecker for a certSigner.
-func CertSignerHealthCheckerFromIssuerAndSecretData(*certv1alpha1.IssuerSpec, map[string][]byte) (HealthChecker, error) {
-	return &certSigner{}, nil
+// CertSignerHealthCheckerFromIssuerAndSecretData is a wrapper for certSignerFromIssuerAndSecretData that returns a HealthChecker interface.
+func CertSignerHealthCheckerFromIssuerAndSecretData(issuerSpec *certv1alpha1.IssuerSpec, secretData map[string][]byte) (HealthChecker, error) {
+	return certSignerFromIssuerAndSecretData(issuerSpec, secretData)
 }
 
 // CertSignerFromIssuerAndSecretData is a wrapper for certSignerFromIssuerAndSecretData that returns a Signer interface.
 func CertSignerFromIssuerAndSecretData(issuerSpec *certv1alpha1.IssuerSpec, secretData map[string][]byte, kubeClient kube.Client) (Signer, error) {
-	return certSignerFromIssuerAndSecretData(issuerSpec, secretData, kubeClient)
+	if kubeClient == nil {
+		return nil, errMissingKubeClient
+	}
+
+	return certSignerFromIssuerAndSecretData(issuerSpec, secretData)
 }
 
-// certSignerFromIssuerAndSecretData creates a new Signer instance using the provided issuer spec and secret data.
-func certSignerFromIssuerAndSecretData(issuerSpec *certv1alpha1.IssuerSpec, secretData map[string][]byte, kubeClient kube.Client) (Signer, error) {
-	tokenData := string(secretData[authorizationHeaderSecretKey])
-	if tokenData == "" {
-		return nil, errMissingTokenData
+// certSignerFromIssuerAndSecretData creates a new certSigner instance using the provided issuer spec and secret data.
+func certSignerFromIssuerAndSecretData(issuerSpec *certv1alpha1.IssuerSpec, secretData map[string][]byte) (*certSigner, error) {
+	hClient, err := buildHTTPClient(issuerSpec, secretData)
+	if err != nil {
+		return nil, err
 	}
 
-	apiEndpoint := issuerSpec.APIEndpoint
-	if apiEndpoint == "" {
-		return nil, errMissingAPIEndpoint
+	authenticator, err := buildAuthenticator(issuerSpec, secretData)
+	if err != nil {
+		return nil, err
 	}
 
-	downloadEndpoint := issuerSpec.DownloadEndpoint
-	if downloadEndpoint == "" {
-		return nil, errMissingDownloadEndpoint
+	specs, err := endpointSpecs(issuerSpec)
+	if err != nil {
+		return nil, err
 	}
 
 	form := issuerSpec.Form
@@ -103,8 +265,19 @@ func certSignerFromIssuerAndSecretData(issuerSpec *certv1alpha1.IssuerSpec, secr
 		return nil, errMissingForm
 	}
 
-	if kubeClient == nil {
-		return nil, errMissingKubeClient
+	pkcs12Password, hasPKCS12Password := secretData[pkcs12PasswordSecretKey]
+	if form == formPKCS12 && !hasPKCS12Password {
+		return nil, errMissingPKCS12PasswordData
+	}
+
+	attributes, err := buildAttributeTemplates(issuerSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	subjectPatterns, err := validate.CompileSubjectPatterns(issuerSpec.CertificateRestrictions.SubjectRestrictions)
+	if err != nil {
+		return nil, err
 	}
 
 	backoff, err := buildRetryBackoff(issuerSpec)
@@ -112,39 +285,206 @@ func certSignerFromIssuerAndSecretData(issuerSpec *certv1alpha1.IssuerSpec, secr
 		return nil, fmt.Errorf("%w: %v", errFailedBuildingRetryBackoff, err)
 	}
 
+	maxTaskAge := defaultMaxTaskAge
+	if issuerSpec.MaxTaskAge != nil {
+		if issuerSpec.MaxTaskAge.Duration <= 0 {
+			return nil, fmt.Errorf("%w: maxTaskAge must be positive, got %s", errInvalidMaxTaskAge, issuerSpec.MaxTaskAge.Duration)
+		}
+		maxTaskAge = issuerSpec.MaxTaskAge.Duration
+	}
+
+	endpoints, err := buildEndpoints(issuerSpec, specs, secretData, authenticator, hClient)
+	if err != nil {
+		return nil, err
+	}
+
 	restrictions := issuerSpec.CertificateRestrictions
 
 	return &certSigner{
-		certClient: cert.NewClient(
-			cert.WithToken(tokenData),
-			cert.WithAPIEndpoint(apiEndpoint),
-			cert.WithDownloadEndpoint(downloadEndpoint),
-			cert.WithForm(form),
-			cert.WithHTTPClient(buildHTTPClient(issuerSpec)),
-		),
-		restrictions: restrictions,
-		waitBackoff:  backoff,
+		endpoints:           endpoints,
+		httpClient:          hClient,
+		restrictions:        restrictions,
+		subjectPatterns:     subjectPatterns,
+		durationParameter:   issuerSpec.RequestProfile.DurationParameter,
+		attributes:          attributes,
+		waitBackoff:         backoff,
+		maxTaskAge:          maxTaskAge,
+		healthCheckEndpoint: issuerSpec.HealthCheckEndpoint,
+		revokeEndpoint:      issuerSpec.RevokeEndpoint,
+		form:                form,
+		certificateEncoding: issuerSpec.ResponseMapping.CertificateEncoding,
+		pkcs12Password:      string(pkcs12Password),
 	}, nil
+}
+
+// buildRequestProfile returns a cert.RequestProfile using values from the issuerSpec.
+func buildRequestProfile(issuerSpec *certv1alpha1.IssuerSpec) cert.RequestProfile {
+	requestProfile := issuerSpec.RequestProfile
 
+	return cert.RequestProfile{
+		Encoding:   requestProfile.Encoding,
+		Path:       requestProfile.Path,
+		FieldName:  requestProfile.FieldName,
+		FileName:   requestProfile.FileName,
+		Parameters: requestProfile.Parameters,
+	}
 }
 
-// buildHTTPClient returns a http.Client object using values from the issuerSpec.
-func buildHTTPClient(issuerSpec *certv1alpha1.IssuerSpec) http.Client {
+// buildHTTPClient returns a http.Client object using values from the issuerSpec. The client
+// certificate in the secret data, if any, is presented in TLS handshakes, and the Cert API is
+// verified against the CA bundles of the HTTPConfig instead of the system roots if any is given.
+// Requests are sent through the proxy of the HTTPConfig, or else the proxy of the environment.
+func buildHTTPClient(issuerSpec *certv1alpha1.IssuerSpec, secretData map[string][]byte) (http.Client, error) {
 	waitTimeout := issuerSpec.HTTPConfig.WaitTimeout
 	timeout := defaultWaitTimeout
 	if waitTimeout != nil {
 		timeout = waitTimeout.Duration
 	}
 
-	skipVerifyTLS := issuerSpec.HTTPConfig.SkipVerifyTLS
+	tlsConfig, err := buildTLSConfig(issuerSpec, secretData)
+	if err != nil {
+		return http.Client{}, fmt.Errorf("%w: %w", ErrInvalidTLSConfig, err)
+	}
+
+	proxy, err := buildProxy(issuerSpec, secretData)
+	if err != nil {
+		return http.Client{}, err
+	}
 
 	return http.Client{
 		Transport: &http.Transport{
-			// #nosec G402
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: skipVerifyTLS},
+			Proxy:           proxy,
+			TLSClientConfig: tlsConfig,
 		},
 		Timeout: timeout,
+	}, nil
+}
+
+// buildTLSConfig returns a tls.Config object using values from the HTTPConfig of the issuerSpec.
+func buildTLSConfig(issuerSpec *certv1alpha1.IssuerSpec, secretData map[string][]byte) (*tls.Config, error) {
+	httpConfig := issuerSpec.HTTPConfig
+
+	minVersion, err := tlsVersion(httpConfig.MinTLSVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	// #nosec G402
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: httpConfig.SkipVerifyTLS,
+		ServerName:         httpConfig.ServerName,
+		MinVersion:         minVersion,
+	}
+
+	if httpConfig.ClientCertificateSecretName != "" {
+		clientCertificate, err := loadClientCertificate(secretData)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCertificate}
+	}
+
+	if len(httpConfig.CABundle) > 0 || httpConfig.CABundleSecretRef != nil || httpConfig.CABundleConfigMapRef != nil {
+		rootCAs := x509.NewCertPool()
+		caBundle := append(append([]byte{}, httpConfig.CABundle...), secretData[CABundleSecretKey]...)
+		if !rootCAs.AppendCertsFromPEM(caBundle) {
+			return nil, errInvalidCABundle
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	return tlsConfig, nil
+}
+
+// buildProxy returns the proxy function of the transport using values from the HTTPConfig of the issuerSpec.
+// Without a proxy URL, the proxy is taken from the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.
+func buildProxy(issuerSpec *certv1alpha1.IssuerSpec, secretData map[string][]byte) (func(*http.Request) (*url.URL, error), error) {
+	httpConfig := issuerSpec.HTTPConfig
+	if httpConfig.ProxyURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	proxyURL, err := url.Parse(httpConfig.ProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidProxyURL, err)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("%w: missing host in %q", errInvalidProxyURL, httpConfig.ProxyURL)
+	}
+
+	if httpConfig.ProxySecretName != "" {
+		username := secretData[ProxyUsernameSecretKey]
+		password := secretData[ProxyPasswordSecretKey]
+		if len(username) == 0 || len(password) == 0 {
+			return nil, errMissingProxyCredentials
+		}
+		proxyURL.User = url.UserPassword(string(username), string(password))
+	}
+
+	return http.ProxyURL(proxyURL), nil
+}
+
+// buildRateLimiter returns the rate limiter of the requests to the apiEndpoint using values from the HTTPConfig
+// of the issuerSpec, or nil if requests are not limited, along with the function which releases it. Issuers
+// which send requests to the same apiEndpoint with the same credentials and rate limit share a rate limiter,
+// as they share the quota of the Cert API.
+func buildRateLimiter(issuerSpec *certv1alpha1.IssuerSpec, apiEndpoint string, secretData map[string][]byte) (*httpClient.RateLimiter, func(), error) {
+	rateLimit := issuerSpec.HTTPConfig.RateLimit
+	if rateLimit == nil {
+		return nil, func() {}, nil
+	}
+
+	qps, err := strconv.ParseFloat(rateLimit.QPS, 64)
+	if err != nil || qps <= 0 {
+		return nil, nil, fmt.Errorf("%w: qps must be a positive number, got %q", errInvalidRateLimit, rateLimit.QPS)
+	}
+
+	burst := defaultRateLimitBurst
+	if rateLimit.Burst > 0 {
+		burst = rateLimit.Burst
+	}
+
+	name := apiEndpoint
+	if endpoint, err := url.Parse(apiEndpoint); err == nil && endpoint.Host != "" {
+		name = endpoint.Host
+	}
+
+	rateLimiter, release := rateLimiters.acquire(endpointCredentialsKey(apiEndpoint, secretData, qps, burst), func() *httpClient.RateLimiter {
+		return httpClient.NewRateLimiter(name, qps, burst)
+	})
+
+	return rateLimiter, release, nil
+}
+
+// tlsVersion returns the TLS version of the given minTLSVersion value of the HTTPConfig.
+// An empty value returns zero, which leaves the default minimum version of crypto/tls in place.
+func tlsVersion(version string) (uint16, error) {
+	switch version {
+	case "":
+		return 0, nil
+	case certv1alpha1.TLSVersion12:
+		return tls.VersionTLS12, nil
+	case certv1alpha1.TLSVersion13:
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", errUnsupportedTLSVersion, version)
+	}
+}
+
+// loadClientCertificate returns the client certificate key pair in the secret data.
+func loadClientCertificate(secretData map[string][]byte) (tls.Certificate, error) {
+	certPEM := secretData[ClientCertificateSecretKey]
+	keyPEM := secretData[ClientKeySecretKey]
+	if len(certPEM) == 0 || len(keyPEM) == 0 {
+		return tls.Certificate{}, errMissingClientCertificate
+	}
+
+	clientCertificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("%w: %v", errFailedLoadingClientCert, err)
 	}
+
+	return clientCertificate, nil
 }
 
 // buildRetryBackoff returns a wait.Backoff object using values from the issuerSpec.
@@ -199,22 +539,14 @@ func buildRetryBackoff(issuerSpec *certv1alpha1.IssuerSpec) (wait.Backoff, error
 	return backoff, nil
 }
 
-func (cs *certSigner) Check() error {
-	return nil
-}
-
-// Sign signs a certificate request and returns the signed certificate.
-func (cs *certSigner) Sign(ctx context.Context, logger logr.Logger, csrBytes []byte) ([]byte, []byte, error) {
-	csr, err := parseCSR(csrBytes)
-	if err != nil {
-		return []byte{}, []byte{}, err
-	}
-
-	if err := validate.EnsureCSR(csr, cs.restrictions); err != nil {
-		return []byte{}, []byte{}, fmt.Errorf("%w: %v", errFailedValidatingCSR, err)
+// Sign submits the CSR to the Cert API if no task is given, and otherwise polls the
+// Cert API for the certificate of the given task.
+func (cs *certSigner) Sign(ctx context.Context, logger logr.Logger, request SignRequest, task Task) (SignResult, error) {
+	if task.ID == "" {
+		return cs.submitCSR(ctx, logger, request)
 	}
 
-	return cs.signCSR(ctx, logger, cs.certClient, csrBytes)
+	return cs.pollTask(ctx, logger, task)
 }
 
 // parseCSR extracts PEM from request object.
@@ -226,39 +558,281 @@ func parseCSR(pemBytes []byte) (*x509.CertificateRequest, error) {
 	return x509.ParseCertificateRequest(block.Bytes)
 }
 
-// signCSR interacts with the Cert API and returns a signed CSR.
-func (cs *certSigner) signCSR(ctx context.Context, logger logr.Logger, certClient cert.Client, csrBytes []byte) ([]byte, []byte, error) {
-	var err error
-	var guid string
-	var response cert.DownloadCertificateResponse
+// submitCSR validates the CSR and the requested duration, and posts them to the Cert API, returning
+// the pending task.
+func (cs *certSigner) submitCSR(ctx context.Context, logger logr.Logger, request SignRequest) (SignResult, error) {
+	csr, err := parseCSR(request.CSR)
+	if err != nil {
+		return SignResult{}, err
+	}
+
+	if err := validate.EnsureCSR(csr, cs.restrictions, cs.subjectPatterns); err != nil {
+		return SignResult{}, fmt.Errorf("%w: %w: %v", ErrPolicyViolation, errFailedValidatingCSR, err)
+	}
+
+	if err := validate.EnsureDuration(request.Duration, cs.restrictions.ValidityRestrictions); err != nil {
+		return SignResult{}, fmt.Errorf("%w: %w: %v", ErrPolicyViolation, errFailedValidatingCSR, err)
+	}
 
-	guid, err = certClient.PostCertificate(ctx, logger, csrBytes)
+	parameters, err := cs.requestParameters(request)
 	if err != nil {
-		return []byte{}, []byte{}, fmt.Errorf("%w: %v", errFailedSigningCertificate, err)
+		return SignResult{}, fmt.Errorf("%w: %w", errFailedSigningCertificate, err)
 	}
 
-	err = retry.OnError(cs.waitBackoff, isErrorNotFound, func() error {
-		response, err = certClient.DownloadCertificate(ctx, logger, guid)
+	var response cert.PostCertificateResponse
+	endpoint, err := cs.withFailover(ctx, logger, func(e *endpoint) (err error) {
+		response, err = e.certClient.PostCertificate(ctx, logger, request.CSR, parameters)
 		return err
 	})
+	if endpoint == nil {
+		return SignResult{}, err
+	}
+	if err != nil {
+		return SignResult{}, wrapRequestError(errFailedSigningCertificate, err)
+	}
+
+	if response.TaskID == "" {
+		return SignResult{}, fmt.Errorf("%w: %v", errFailedSigningCertificate, errMissingTaskID)
+	}
+
+	task := Task{
+		ID:              response.TaskID,
+		SubmittedAt:     time.Now(),
+		PollingInterval: min(response.PollingInterval, maxServerDelay),
+		Endpoint:        endpoint.apiEndpoint,
+	}
+
+	return SignResult{
+		Task:         task,
+		RequeueAfter: cs.pollDelay(task, 0),
+	}, nil
+}
+
+// requestParameters returns the parameters which are sent to the Cert API along with the CSR of the
+// request: the attributes rendered with the metadata of the request, and the requested duration in
+// whole seconds if the request profile names its parameter.
+func (cs *certSigner) requestParameters(request SignRequest) (map[string]string, error) {
+	parameters := map[string]string{}
+	if err := renderAttributes(cs.attributes, request.Metadata, parameters); err != nil {
+		return nil, err
+	}
+
+	if cs.durationParameter != "" && request.Duration > 0 {
+		seconds := int64(request.Duration / time.Second)
+		parameters[cs.durationParameter] = strconv.FormatInt(seconds, 10)
+	}
+
+	return parameters, nil
+}
+
+// pollTask downloads the certificate of the task from the endpoint of the Cert API which accepted
+// the task. The task is returned as still pending if the Cert API has not yet issued the certificate,
+// unless the task was submitted longer than the maximum task age ago.
+func (cs *certSigner) pollTask(ctx context.Context, logger logr.Logger, task Task) (SignResult, error) {
+	endpoint, err := cs.taskEndpoint(task)
 	if err != nil {
-		return []byte{}, []byte{}, fmt.Errorf("%w: %v", errFailedSigningCertificate, err)
+		return SignResult{}, err
 	}
 
-	decodedData, err := base64.StdEncoding.DecodeString(response.Data)
+	if err := endpoint.circuitBreaker.Allow(); err != nil {
+		return SignResult{}, err
+	}
+
+	response, err := endpoint.certClient.DownloadCertificate(ctx, logger, task.ID)
+	endpoint.circuitBreaker.Record(ctx, err)
 	if err != nil {
-		return []byte{}, []byte{}, fmt.Errorf("%w: %v", errFailedDecodingData, err)
+		if isErrorProcessing(err) {
+			return cs.pendingTask(task, err)
+		}
+		return SignResult{}, wrapRequestError(errFailedDownloadCertificate, err)
+	}
+
+	if cs.form == formPKCS12 {
+		chainPEM, caPEM, err := certhandler.Decoder(response.Data, cs.pkcs12Password)
+		if err != nil {
+			return SignResult{}, fmt.Errorf("%w: %v", errFailedDecodingPKCS12, err)
+		}
+
+		return SignResult{Certificate: chainPEM, CA: caPEM, DurationRequested: cs.durationParameter != ""}, nil
+	}
+
+	decodedData, err := decodeCertificateData(response.Data, cs.certificateEncoding)
+	if err != nil {
+		return SignResult{}, fmt.Errorf("%w: %v", errFailedDecodingData, err)
 	}
 
 	bundle, err := cmpkgutil.ParseSingleCertificateChainPEM(decodedData)
 	if err != nil {
-		return []byte{}, []byte{}, fmt.Errorf("%w: %v", errFailedParsingCertificate, err)
+		return SignResult{}, fmt.Errorf("%w: %v", errFailedParsingCertificate, err)
 	}
 
-	return bundle.ChainPEM, bundle.CAPEM, nil
+	return SignResult{Certificate: bundle.ChainPEM, CA: bundle.CAPEM, DurationRequested: cs.durationParameter != ""}, nil
 }
 
-// IsErrorNotFound returns a boolean indicating whether an error includes a Not Found error.
-func isErrorNotFound(err error) bool {
-	return strings.Contains(err.Error(), http.StatusText(http.StatusNotFound))
+// decodeCertificateData returns the PEM encoded certificate chain of the downloaded data
+// according to its encoding.
+func decodeCertificateData(data, encoding string) ([]byte, error) {
+	switch encoding {
+	case "", certv1alpha1.CertificateEncodingBase64PEM:
+		return base64.StdEncoding.DecodeString(data)
+	case certv1alpha1.CertificateEncodingPEM:
+		return []byte(data), nil
+	case certv1alpha1.CertificateEncodingBase64DER:
+		derBytes, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, err
+		}
+
+		certificates, err := x509.ParseCertificates(derBytes)
+		if err != nil {
+			return nil, err
+		}
+
+		var pemBytes []byte
+		for _, certificate := range certificates {
+			pemBytes = append(pemBytes, pem.EncodeToMemory(&pem.Block{Type: certificateBlockType, Bytes: certificate.Raw})...)
+		}
+		return pemBytes, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnsupportedCertEncoding, encoding)
+	}
+}
+
+// Revoke requests the Cert API to revoke the certificate with the given serial number, failing
+// over to the next endpoint if an endpoint is unavailable.
+func (cs *certSigner) Revoke(ctx context.Context, logger logr.Logger, serialNumber string) error {
+	if cs.revokeEndpoint == "" {
+		return errMissingRevokeEndpoint
+	}
+
+	endpoint, err := cs.withFailover(ctx, logger, func(e *endpoint) error {
+		return e.certClient.RevokeCertificate(ctx, logger, serialNumber)
+	})
+	if endpoint == nil {
+		return err
+	}
+	if err != nil {
+		return wrapRequestError(errFailedRevokingCertificate, err)
+	}
+
+	return nil
+}
+
+// pendingTask returns the result of a task which the Cert API has not yet issued, which is polled again
+// after the delay asked for by the Cert API or the poll delay, and no later than when it expires.
+// An ErrTaskExpired is returned once the task is older than the maximum task age. Tasks recorded
+// without their submission time are not expired.
+func (cs *certSigner) pendingTask(task Task, err error) (SignResult, error) {
+	age := time.Since(task.SubmittedAt)
+	expires := cs.maxTaskAge > 0 && !task.SubmittedAt.IsZero()
+	if expires && age >= cs.maxTaskAge {
+		return SignResult{}, fmt.Errorf("%w: task %q was submitted %s ago, the maximum task age is %s",
+			ErrTaskExpired, task.ID, age.Round(time.Second), cs.maxTaskAge)
+	}
+
+	requeueAfter, ok := serverDelay(err)
+	if !ok {
+		requeueAfter = cs.pollDelay(task, age)
+	}
+	if remaining := cs.maxTaskAge - age; expires && requeueAfter > remaining {
+		requeueAfter = remaining
+	}
+
+	return SignResult{Task: task, RequeueAfter: requeueAfter}, nil
+}
+
+// pollDelay returns the time to wait before polling a task again. The polling interval of the
+// task is used if the signer backend asked for one. Otherwise, the retry backoff is applied as if
+// it had been running since the task was submitted, so that the delay keeps growing across reconciles.
+func (cs *certSigner) pollDelay(task Task, elapsed time.Duration) time.Duration {
+	if task.PollingInterval > 0 {
+		return task.PollingInterval
+	}
+
+	backoff := cs.waitBackoff
+
+	var waited time.Duration
+	for {
+		delay := backoff.Step()
+		waited += delay
+		if waited > elapsed || backoff.Steps < 1 {
+			return delay
+		}
+	}
+}
+
+// Close closes the idle keep-alive connections of the HTTP client of the signer, and releases the state
+// which its endpoints share with the signers of other issuers.
+func (cs *certSigner) Close() {
+	cs.httpClient.CloseIdleConnections()
+	for _, e := range cs.endpoints {
+		e.release()
+	}
+}
+
+// wrapRequestError wraps the error of a request to the Cert API with the given error, with
+// ErrRequestRejected if the Cert API rejected the request, and in a *RetryAfterError if the
+// Cert API asked to retry the request later.
+func wrapRequestError(wrapping, err error) error {
+	if isErrorTerminal(err) {
+		return fmt.Errorf("%w: %w: %w", ErrRequestRejected, wrapping, err)
+	}
+
+	wrapped := fmt.Errorf("%w: %w", wrapping, err)
+	if retryAfter, ok := serverDelay(err); ok {
+		return &RetryAfterError{Err: wrapped, RetryAfter: retryAfter}
+	}
+
+	return wrapped
+}
+
+// serverDelay returns the delay requested by the Retry-After header of the Cert API response which
+// caused the error, bounded by maxServerDelay.
+func serverDelay(err error) (time.Duration, bool) {
+	var statusErr *httpClient.StatusError
+	if !errors.As(err, &statusErr) {
+		return 0, false
+	}
+
+	retryAfter, ok := statusErr.RetryAfter()
+	return min(retryAfter, maxServerDelay), ok
+}
+
+// statusCode returns the status code of the Cert API or OAuth2 token endpoint response which caused the
+// error, or zero if the error was not caused by a response, such as a transport error.
+func statusCode(err error) int {
+	var statusErr *httpClient.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode
+	}
+
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) && retrieveErr.Response != nil {
+		return retrieveErr.Response.StatusCode
+	}
+
+	return 0
+}
+
+// isErrorProcessing returns a boolean indicating whether the Cert API responded that the certificate
+// is still being processed, with a Not Found or Accepted response.
+func isErrorProcessing(err error) bool {
+	switch statusCode(err) {
+	case http.StatusNotFound, http.StatusAccepted:
+		return true
+	default:
+		return false
+	}
+}
+
+// isErrorTerminal returns a boolean indicating whether the Cert API rejected the request with a
+// Bad Request, Unauthorized or Forbidden response. Other errors, including Too Many Requests and
+// 5xx responses, are retried.
+func isErrorTerminal(err error) bool {
+	switch statusCode(err) {
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+		return true
+	default:
+		return false
+	}
 }
@@ -0,0 +1,1091 @@
+package signer
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmpkgutil "github.com/cert-manager/cert-manager/pkg/util/pki"
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	"github.com/dana-team/cert-external-issuer/internal/fakecertapi"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testToken        = "dummy-token"
+	testDownloadPath = "/download/"
+	testDelay        = 200 * time.Millisecond
+
+	testClientCertificateSecret = "client-tls"
+	testProxySecret             = "proxy-credentials"
+	testCertAPIHost             = "cert-api.invalid"
+)
+
+func TestCertSignerSignWithFakeCertAPI(t *testing.T) {
+	server, err := fakecertapi.NewServer(
+		fakecertapi.WithToken(testToken),
+		fakecertapi.WithDownloadPath(testDownloadPath),
+		fakecertapi.WithDelay(testDelay),
+	)
+	assert.NoError(t, err)
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	issuerSpec := &certv1alpha1.IssuerSpec{
+		APIEndpoint:      httpServer.URL + "/",
+		DownloadEndpoint: testDownloadPath,
+		Form:             fakecertapi.FormChain,
+		HTTPConfig: certv1alpha1.HTTPConfig{
+			RetryBackoff: certv1alpha1.RetryBackoff{Duration: metav1.Duration{Duration: testDelay}},
+		},
+		CertificateRestrictions: certv1alpha1.Restrictions{
+			SubjectAltNamesRestrictions: certv1alpha1.SubjectAltNamesRestrictions{AllowDNSNames: true},
+		},
+	}
+
+	signer, err := CertSignerFromIssuerAndSecretData(issuerSpec, map[string][]byte{authorizationHeaderSecretKey: []byte(testToken)}, fake.NewClientBuilder().Build())
+	assert.NoError(t, err)
+
+	csrBytes := generateTestCSR(t)
+
+	submitted, err := signer.Sign(context.Background(), logr.Discard(), SignRequest{CSR: csrBytes}, Task{})
+	assert.NoError(t, err)
+	assert.True(t, submitted.Pending())
+	assert.NotEmpty(t, submitted.Task.ID)
+
+	pending, err := signer.Sign(context.Background(), logr.Discard(), SignRequest{CSR: csrBytes}, submitted.Task)
+	assert.NoError(t, err)
+	assert.True(t, pending.Pending())
+	assert.Equal(t, submitted.Task, pending.Task)
+
+	time.Sleep(testDelay)
+
+	issued, err := signer.Sign(context.Background(), logr.Discard(), SignRequest{CSR: csrBytes}, submitted.Task)
+	assert.NoError(t, err)
+	assert.False(t, issued.Pending())
+
+	certificate, err := cmpkgutil.DecodeX509CertificateBytes(issued.Certificate)
+	assert.NoError(t, err)
+	assert.Equal(t, testCommonName, certificate.Subject.CommonName)
+
+	caPEM, err := server.CACertificatePEM()
+	assert.NoError(t, err)
+	assert.Equal(t, caPEM, issued.CA)
+}
+
+func TestCertSignerSignForRequestedDuration(t *testing.T) {
+	const testDurationParameter = "validitySeconds"
+
+	server, err := fakecertapi.NewServer(
+		fakecertapi.WithToken(testToken),
+		fakecertapi.WithDownloadPath(testDownloadPath),
+		fakecertapi.WithDurationParameter(testDurationParameter),
+	)
+	assert.NoError(t, err)
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	type args struct {
+		durationParameter    string
+		validityRestrictions certv1alpha1.ValidityRestrictions
+		duration             time.Duration
+	}
+	type want struct {
+		err      error
+		duration time.Duration
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldForwardRequestedDuration": {
+			args: args{
+				durationParameter: testDurationParameter,
+				duration:          2 * time.Hour,
+			},
+			want: want{duration: 2 * time.Hour},
+		},
+		"ShouldNotForwardDurationWithoutDurationParameter": {
+			args: args{
+				duration: 2 * time.Hour,
+			},
+			want: want{duration: cmapi.DefaultCertificateDuration},
+		},
+		"ShouldNotForwardDurationWhichWasNotRequested": {
+			args: args{
+				durationParameter: testDurationParameter,
+			},
+			want: want{duration: cmapi.DefaultCertificateDuration},
+		},
+		"ShouldFailOnDurationShorterThanMinDuration": {
+			args: args{
+				durationParameter:    testDurationParameter,
+				validityRestrictions: certv1alpha1.ValidityRestrictions{MinDuration: &metav1.Duration{Duration: 24 * time.Hour}},
+				duration:             2 * time.Hour,
+			},
+			want: want{err: ErrPolicyViolation},
+		},
+		"ShouldFailOnDurationLongerThanMaxDuration": {
+			args: args{
+				durationParameter:    testDurationParameter,
+				validityRestrictions: certv1alpha1.ValidityRestrictions{MaxDuration: &metav1.Duration{Duration: time.Hour}},
+				duration:             2 * time.Hour,
+			},
+			want: want{err: ErrPolicyViolation},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			issuerSpec := &certv1alpha1.IssuerSpec{
+				APIEndpoint:      httpServer.URL + "/",
+				DownloadEndpoint: testDownloadPath,
+				Form:             fakecertapi.FormPublic,
+				RequestProfile:   certv1alpha1.RequestProfile{DurationParameter: tc.args.durationParameter},
+				CertificateRestrictions: certv1alpha1.Restrictions{
+					SubjectAltNamesRestrictions: certv1alpha1.SubjectAltNamesRestrictions{AllowDNSNames: true},
+					ValidityRestrictions:        tc.args.validityRestrictions,
+				},
+			}
+
+			signer, err := CertSignerFromIssuerAndSecretData(issuerSpec, map[string][]byte{authorizationHeaderSecretKey: []byte(testToken)}, fake.NewClientBuilder().Build())
+			assert.NoError(t, err)
+
+			request := SignRequest{CSR: generateTestCSR(t), Duration: tc.args.duration}
+			submitted, err := signer.Sign(context.Background(), logr.Discard(), request, Task{})
+			if tc.want.err != nil {
+				assert.True(t, errors.Is(err, tc.want.err), "unexpected error: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+
+			issued, err := signer.Sign(context.Background(), logr.Discard(), request, submitted.Task)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.args.durationParameter != "", issued.DurationRequested)
+
+			certificate, err := cmpkgutil.DecodeX509CertificateBytes(issued.Certificate)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.duration, certificate.NotAfter.Sub(certificate.NotBefore))
+		})
+	}
+}
+
+func TestDecodeCertificateData(t *testing.T) {
+	certificatePEM := generateTestCA(t, time.Now().Add(time.Hour))[corev1.TLSCertKey]
+	certificate, err := cmpkgutil.DecodeX509CertificateBytes(certificatePEM)
+	assert.NoError(t, err)
+
+	type args struct {
+		data     string
+		encoding string
+	}
+	type want struct {
+		err bool
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldDecodeBase64PEMByDefault": {
+			args: args{
+				data: base64.StdEncoding.EncodeToString(certificatePEM),
+			},
+		},
+		"ShouldDecodeBase64PEM": {
+			args: args{
+				data:     base64.StdEncoding.EncodeToString(certificatePEM),
+				encoding: certv1alpha1.CertificateEncodingBase64PEM,
+			},
+		},
+		"ShouldDecodePEM": {
+			args: args{
+				data:     string(certificatePEM),
+				encoding: certv1alpha1.CertificateEncodingPEM,
+			},
+		},
+		"ShouldDecodeBase64DER": {
+			args: args{
+				data:     base64.StdEncoding.EncodeToString(certificate.Raw),
+				encoding: certv1alpha1.CertificateEncodingBase64DER,
+			},
+		},
+		"ShouldFailOnInvalidDER": {
+			args: args{
+				data:     base64.StdEncoding.EncodeToString([]byte("invalid")),
+				encoding: certv1alpha1.CertificateEncodingBase64DER,
+			},
+			want: want{
+				err: true,
+			},
+		},
+		"ShouldFailOnUnsupportedEncoding": {
+			args: args{
+				data:     string(certificatePEM),
+				encoding: "unsupported",
+			},
+			want: want{
+				err: true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			decoded, err := decodeCertificateData(tc.args.data, tc.args.encoding)
+			if tc.want.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, certificatePEM, decoded)
+		})
+	}
+}
+
+func TestBuildHTTPClientWithClientCertificate(t *testing.T) {
+	caData := generateTestCA(t, time.Now().Add(time.Hour))
+	otherCAData := generateTestCA(t, time.Now().Add(time.Hour))
+
+	type args struct {
+		clientCertificateSecretName string
+		secretData                  map[string][]byte
+	}
+	type want struct {
+		buildErr   error
+		requestErr bool
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldPresentClientCertificate": {
+			args: args{
+				clientCertificateSecretName: testClientCertificateSecret,
+				secretData: map[string][]byte{
+					ClientCertificateSecretKey: caData[corev1.TLSCertKey],
+					ClientKeySecretKey:         caData[corev1.TLSPrivateKeyKey],
+				},
+			},
+		},
+		"ShouldFailHandshakeWithoutClientCertificate": {
+			want: want{
+				requestErr: true,
+			},
+		},
+		"ShouldFailWithMissingClientCertificateData": {
+			args: args{
+				clientCertificateSecretName: testClientCertificateSecret,
+				secretData:                  map[string][]byte{ClientCertificateSecretKey: caData[corev1.TLSCertKey]},
+			},
+			want: want{
+				buildErr: errMissingClientCertificate,
+			},
+		},
+		"ShouldFailWithMismatchedClientKey": {
+			args: args{
+				clientCertificateSecretName: testClientCertificateSecret,
+				secretData: map[string][]byte{
+					ClientCertificateSecretKey: caData[corev1.TLSCertKey],
+					ClientKeySecretKey:         otherCAData[corev1.TLSPrivateKeyKey],
+				},
+			},
+			want: want{
+				buildErr: errFailedLoadingClientCert,
+			},
+		},
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			issuerSpec := &certv1alpha1.IssuerSpec{
+				HTTPConfig: certv1alpha1.HTTPConfig{
+					SkipVerifyTLS:               true,
+					ClientCertificateSecretName: tc.args.clientCertificateSecretName,
+				},
+			}
+
+			hClient, err := buildHTTPClient(issuerSpec, tc.args.secretData)
+			if tc.want.buildErr != nil {
+				assert.True(t, errors.Is(err, tc.want.buildErr), "unexpected error: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+
+			response, err := hClient.Get(server.URL)
+			if tc.want.requestErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			_ = response.Body.Close()
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+		})
+	}
+}
+
+func TestBuildHTTPClientWithCABundle(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	serverCABundle, err := cmpkgutil.EncodeX509(server.Certificate())
+	assert.NoError(t, err)
+	otherCABundle := generateTestCA(t, time.Now().Add(time.Hour))[corev1.TLSCertKey]
+
+	type args struct {
+		httpConfig certv1alpha1.HTTPConfig
+		secretData map[string][]byte
+	}
+	type want struct {
+		buildErr   error
+		requestErr bool
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldTrustInlineCABundle": {
+			args: args{
+				httpConfig: certv1alpha1.HTTPConfig{CABundle: serverCABundle},
+			},
+		},
+		"ShouldTrustReferencedCABundle": {
+			args: args{
+				httpConfig: certv1alpha1.HTTPConfig{CABundleConfigMapRef: &certv1alpha1.KeySelector{Name: "ca"}},
+				secretData: map[string][]byte{CABundleSecretKey: serverCABundle},
+			},
+		},
+		"ShouldCombineInlineAndReferencedCABundles": {
+			args: args{
+				httpConfig: certv1alpha1.HTTPConfig{CABundle: otherCABundle, CABundleSecretRef: &certv1alpha1.KeySelector{Name: "ca"}},
+				secretData: map[string][]byte{CABundleSecretKey: serverCABundle},
+			},
+		},
+		"ShouldVerifyServerName": {
+			args: args{
+				httpConfig: certv1alpha1.HTTPConfig{CABundle: serverCABundle, ServerName: "example.com"},
+			},
+		},
+		"ShouldFailVerificationWithoutCABundle": {
+			want: want{
+				requestErr: true,
+			},
+		},
+		"ShouldFailVerificationWithOtherCABundle": {
+			args: args{
+				httpConfig: certv1alpha1.HTTPConfig{CABundle: otherCABundle},
+			},
+			want: want{
+				requestErr: true,
+			},
+		},
+		"ShouldFailVerificationWithMismatchedServerName": {
+			args: args{
+				httpConfig: certv1alpha1.HTTPConfig{CABundle: serverCABundle, ServerName: "cert-api.internal"},
+			},
+			want: want{
+				requestErr: true,
+			},
+		},
+		"ShouldFailHandshakeBelowMinTLSVersion": {
+			args: args{
+				httpConfig: certv1alpha1.HTTPConfig{CABundle: serverCABundle, MinTLSVersion: certv1alpha1.TLSVersion13},
+			},
+			want: want{
+				requestErr: true,
+			},
+		},
+		"ShouldFailWithInvalidCABundle": {
+			args: args{
+				httpConfig: certv1alpha1.HTTPConfig{CABundle: []byte("invalid")},
+			},
+			want: want{
+				buildErr: errInvalidCABundle,
+			},
+		},
+		"ShouldFailWithMissingReferencedCABundle": {
+			args: args{
+				httpConfig: certv1alpha1.HTTPConfig{CABundleSecretRef: &certv1alpha1.KeySelector{Name: "ca"}},
+			},
+			want: want{
+				buildErr: errInvalidCABundle,
+			},
+		},
+		"ShouldFailWithUnsupportedTLSVersion": {
+			args: args{
+				httpConfig: certv1alpha1.HTTPConfig{MinTLSVersion: "1.1"},
+			},
+			want: want{
+				buildErr: errUnsupportedTLSVersion,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			hClient, err := buildHTTPClient(&certv1alpha1.IssuerSpec{HTTPConfig: tc.args.httpConfig}, tc.args.secretData)
+			if tc.want.buildErr != nil {
+				assert.True(t, errors.Is(err, tc.want.buildErr), "unexpected error: %v", err)
+				assert.True(t, errors.Is(err, ErrInvalidTLSConfig), "unexpected error: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+
+			response, err := hClient.Get(server.URL)
+			if tc.want.requestErr {
+				assert.True(t, isTLSError(err), "unexpected error: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			_ = response.Body.Close()
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+		})
+	}
+}
+
+func TestCertSignerCheckWithUntrustedCertAPI(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	issuerSpec := &certv1alpha1.IssuerSpec{
+		APIEndpoint:      server.URL + "/",
+		DownloadEndpoint: testDownloadPath,
+		Form:             fakecertapi.FormChain,
+	}
+
+	checker, err := CertSignerFromIssuerAndSecretData(issuerSpec, map[string][]byte{authorizationHeaderSecretKey: []byte(testToken)}, fake.NewClientBuilder().Build())
+	assert.NoError(t, err)
+
+	err = checker.(HealthChecker).Check(context.Background())
+
+	var healthCheckErr *HealthCheckError
+	assert.True(t, errors.As(err, &healthCheckErr), "unexpected error: %v", err)
+	assert.Equal(t, HealthCheckReasonTLSError, healthCheckErr.Reason)
+	assert.True(t, errors.Is(err, errTLSVerificationFailed), "unexpected error: %v", err)
+}
+
+func TestCertSignerCheckResponses(t *testing.T) {
+	type args struct {
+		healthCheckEndpoint string
+		statusCode          int
+	}
+	type want struct {
+		reason string
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldBeHealthyWithOKResponse": {
+			args: args{statusCode: http.StatusOK},
+			want: want{},
+		},
+		"ShouldBeHealthyWithNotFoundResponse": {
+			args: args{statusCode: http.StatusNotFound},
+			want: want{},
+		},
+		"ShouldBeHealthyWithMethodNotAllowedResponse": {
+			args: args{statusCode: http.StatusMethodNotAllowed},
+			want: want{},
+		},
+		"ShouldBeHealthyWithBadRequestResponse": {
+			args: args{statusCode: http.StatusBadRequest},
+			want: want{},
+		},
+		"ShouldBeUnauthorizedWithUnauthorizedResponse": {
+			args: args{statusCode: http.StatusUnauthorized},
+			want: want{reason: HealthCheckReasonUnauthorized},
+		},
+		"ShouldBeUnauthorizedWithForbiddenResponse": {
+			args: args{statusCode: http.StatusForbidden},
+			want: want{reason: HealthCheckReasonUnauthorized},
+		},
+		"ShouldBeUnhealthyWithServerErrorResponse": {
+			args: args{statusCode: http.StatusServiceUnavailable},
+			want: want{reason: HealthCheckReasonBadResponse},
+		},
+		"ShouldBeHealthyWithOKResponseOfHealthCheckEndpoint": {
+			args: args{healthCheckEndpoint: "health", statusCode: http.StatusOK},
+			want: want{},
+		},
+		"ShouldBeUnhealthyWithMethodNotAllowedResponseOfHealthCheckEndpoint": {
+			args: args{healthCheckEndpoint: "health", statusCode: http.StatusMethodNotAllowed},
+			want: want{reason: HealthCheckReasonBadResponse},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var probedPath string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				probedPath = r.URL.Path
+				w.WriteHeader(tc.args.statusCode)
+			}))
+			defer server.Close()
+
+			issuerSpec := &certv1alpha1.IssuerSpec{
+				APIEndpoint:         server.URL + "/",
+				DownloadEndpoint:    testDownloadPath,
+				HealthCheckEndpoint: tc.args.healthCheckEndpoint,
+				Form:                fakecertapi.FormChain,
+			}
+
+			checker, err := CertSignerHealthCheckerFromIssuerAndSecretData(issuerSpec, map[string][]byte{authorizationHeaderSecretKey: []byte(testToken)})
+			assert.NoError(t, err)
+
+			err = checker.Check(context.Background())
+			assert.Equal(t, "/"+tc.args.healthCheckEndpoint, probedPath)
+			if tc.want.reason == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var healthCheckErr *HealthCheckError
+			assert.True(t, errors.As(err, &healthCheckErr), "unexpected error: %v", err)
+			assert.Equal(t, tc.want.reason, healthCheckErr.Reason)
+		})
+	}
+}
+
+func TestCertSignerCheckThroughProxy(t *testing.T) {
+	const (
+		proxyUsername = "proxy-user"
+		proxyPassword = "proxy-password"
+	)
+
+	var proxiedHosts []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := parseProxyAuthorization(r.Header.Get("Proxy-Authorization"))
+		if !ok || username != proxyUsername || password != proxyPassword {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		proxiedHosts = append(proxiedHosts, r.URL.Host)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	type args struct {
+		proxyURL        string
+		proxySecretName string
+		secretData      map[string][]byte
+	}
+	type want struct {
+		buildErr error
+		reason   string
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldReachCertAPIThroughProxy": {
+			args: args{
+				proxyURL:        proxy.URL,
+				proxySecretName: testProxySecret,
+				secretData: map[string][]byte{
+					ProxyUsernameSecretKey: []byte(proxyUsername),
+					ProxyPasswordSecretKey: []byte(proxyPassword),
+				},
+			},
+		},
+		"ShouldReportRejectedProxyCredentials": {
+			args: args{
+				proxyURL:        proxy.URL,
+				proxySecretName: testProxySecret,
+				secretData: map[string][]byte{
+					ProxyUsernameSecretKey: []byte(proxyUsername),
+					ProxyPasswordSecretKey: []byte("invalid"),
+				},
+			},
+			want: want{
+				reason: HealthCheckReasonProxyError,
+			},
+		},
+		"ShouldReportMissingProxyCredentials": {
+			args: args{
+				proxyURL: proxy.URL,
+			},
+			want: want{
+				reason: HealthCheckReasonProxyError,
+			},
+		},
+		"ShouldReportUnreachableProxy": {
+			args: args{
+				proxyURL: "http://127.0.0.1:1",
+			},
+			want: want{
+				reason: HealthCheckReasonProxyError,
+			},
+		},
+		"ShouldFailWithInvalidProxyURL": {
+			args: args{
+				proxyURL: "proxy.example.com:3128",
+			},
+			want: want{
+				buildErr: errInvalidProxyURL,
+			},
+		},
+		"ShouldFailWithMissingProxySecretData": {
+			args: args{
+				proxyURL:        proxy.URL,
+				proxySecretName: testProxySecret,
+				secretData:      map[string][]byte{ProxyUsernameSecretKey: []byte(proxyUsername)},
+			},
+			want: want{
+				buildErr: errMissingProxyCredentials,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			proxiedHosts = nil
+			issuerSpec := &certv1alpha1.IssuerSpec{
+				APIEndpoint:      "http://" + testCertAPIHost + "/",
+				DownloadEndpoint: testDownloadPath,
+				Form:             fakecertapi.FormChain,
+				HTTPConfig: certv1alpha1.HTTPConfig{
+					ProxyURL:        tc.args.proxyURL,
+					ProxySecretName: tc.args.proxySecretName,
+				},
+			}
+
+			secretData := map[string][]byte{authorizationHeaderSecretKey: []byte(testToken)}
+			for key, value := range tc.args.secretData {
+				secretData[key] = value
+			}
+
+			signer, err := CertSignerFromIssuerAndSecretData(issuerSpec, secretData, fake.NewClientBuilder().Build())
+			if tc.want.buildErr != nil {
+				assert.True(t, errors.Is(err, tc.want.buildErr), "unexpected error: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+
+			err = signer.(HealthChecker).Check(context.Background())
+			if tc.want.reason != "" {
+				var healthCheckErr *HealthCheckError
+				assert.True(t, errors.As(err, &healthCheckErr), "unexpected error: %v", err)
+				assert.Equal(t, tc.want.reason, healthCheckErr.Reason)
+				assert.True(t, errors.Is(err, errProxyConnectionFailed), "unexpected error: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, []string{testCertAPIHost}, proxiedHosts)
+		})
+	}
+}
+
+// parseProxyAuthorization returns the credentials of a basic Proxy-Authorization header.
+func parseProxyAuthorization(header string) (string, string, bool) {
+	request := &http.Request{Header: http.Header{"Authorization": {header}}}
+	return request.BasicAuth()
+}
+
+func TestBuildRateLimiter(t *testing.T) {
+	const testAPIEndpoint = "https://cert-api.example.com/api/"
+	secretData := map[string][]byte{authorizationHeaderSecretKey: []byte(testToken)}
+
+	type args struct {
+		rateLimit      *certv1alpha1.RateLimit
+		secretData     map[string][]byte
+		otherRateLimit *certv1alpha1.RateLimit
+	}
+	type want struct {
+		err    error
+		shared bool
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldNotLimitWithoutRateLimit": {},
+		"ShouldShareRateLimiterOfSameEndpointAndCredentials": {
+			args: args{
+				rateLimit:  &certv1alpha1.RateLimit{QPS: "0.5", Burst: 2},
+				secretData: secretData,
+			},
+			want: want{
+				shared: true,
+			},
+		},
+		"ShouldNotShareRateLimiterOfOtherCredentials": {
+			args: args{
+				rateLimit:  &certv1alpha1.RateLimit{QPS: "0.5", Burst: 2},
+				secretData: map[string][]byte{authorizationHeaderSecretKey: []byte("other-token")},
+			},
+		},
+		"ShouldNotShareRateLimiterOfOtherRateLimit": {
+			args: args{
+				rateLimit:      &certv1alpha1.RateLimit{QPS: "0.5", Burst: 2},
+				secretData:     secretData,
+				otherRateLimit: &certv1alpha1.RateLimit{QPS: "5", Burst: 2},
+			},
+		},
+		"ShouldFailWithInvalidQPS": {
+			args: args{
+				rateLimit: &certv1alpha1.RateLimit{QPS: "fast"},
+			},
+			want: want{
+				err: errInvalidRateLimit,
+			},
+		},
+		"ShouldFailWithZeroQPS": {
+			args: args{
+				rateLimit: &certv1alpha1.RateLimit{QPS: "0"},
+			},
+			want: want{
+				err: errInvalidRateLimit,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			issuerSpec := &certv1alpha1.IssuerSpec{
+				APIEndpoint: testAPIEndpoint,
+				HTTPConfig:  certv1alpha1.HTTPConfig{RateLimit: tc.args.rateLimit},
+			}
+
+			rateLimiter, release, err := buildRateLimiter(issuerSpec, testAPIEndpoint, tc.args.secretData)
+			if tc.want.err != nil {
+				assert.True(t, errors.Is(err, tc.want.err), "unexpected error: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+
+			if tc.args.rateLimit == nil {
+				assert.Nil(t, rateLimiter)
+				return
+			}
+
+			otherIssuerSpec := issuerSpec.DeepCopy()
+			if tc.args.otherRateLimit != nil {
+				otherIssuerSpec.HTTPConfig.RateLimit = tc.args.otherRateLimit
+			}
+
+			sharedRateLimiter, releaseShared, err := buildRateLimiter(otherIssuerSpec, testAPIEndpoint, secretData)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.shared, rateLimiter == sharedRateLimiter)
+
+			release()
+			releaseShared()
+			rebuiltRateLimiter, releaseRebuilt, err := buildRateLimiter(issuerSpec, testAPIEndpoint, tc.args.secretData)
+			assert.NoError(t, err)
+			defer releaseRebuilt()
+			assert.NotSame(t, rateLimiter, rebuiltRateLimiter, "expected the rate limiter to be dropped once released by every signer")
+		})
+	}
+}
+
+func TestCertSignerSignClassifiesCertAPIErrors(t *testing.T) {
+	const errorMessage = "certificate template is disabled"
+
+	type args struct {
+		statusCode int
+	}
+	type want struct {
+		pending  bool
+		rejected bool
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldKeepPollingWhenNotFound": {
+			args: args{statusCode: http.StatusNotFound},
+			want: want{pending: true},
+		},
+		"ShouldKeepPollingWhenAccepted": {
+			args: args{statusCode: http.StatusAccepted},
+			want: want{pending: true},
+		},
+		"ShouldRetryWhenTooManyRequests": {
+			args: args{statusCode: http.StatusTooManyRequests},
+		},
+		"ShouldRetryWhenServiceUnavailable": {
+			args: args{statusCode: http.StatusServiceUnavailable},
+		},
+		"ShouldRejectWhenBadRequest": {
+			args: args{statusCode: http.StatusBadRequest},
+			want: want{rejected: true},
+		},
+		"ShouldRejectWhenUnauthorized": {
+			args: args{statusCode: http.StatusUnauthorized},
+			want: want{rejected: true},
+		},
+		"ShouldRejectWhenForbidden": {
+			args: args{statusCode: http.StatusForbidden},
+			want: want{rejected: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.args.statusCode)
+				_, _ = w.Write([]byte(`{"message": "` + errorMessage + `"}`))
+			}))
+			defer server.Close()
+
+			issuerSpec := &certv1alpha1.IssuerSpec{
+				APIEndpoint:      server.URL + "/",
+				DownloadEndpoint: testDownloadPath,
+				Form:             fakecertapi.FormChain,
+			}
+
+			signer, err := CertSignerFromIssuerAndSecretData(issuerSpec, map[string][]byte{authorizationHeaderSecretKey: []byte(testToken)}, fake.NewClientBuilder().Build())
+			assert.NoError(t, err)
+
+			task := Task{ID: "task-1", SubmittedAt: time.Now()}
+			result, err := signer.Sign(context.Background(), logr.Discard(), SignRequest{}, task)
+			if tc.want.pending {
+				assert.NoError(t, err)
+				assert.True(t, result.Pending())
+				assert.Equal(t, task, result.Task)
+				return
+			}
+
+			assert.True(t, errors.Is(err, errFailedDownloadCertificate), "unexpected error: %v", err)
+			assert.Equal(t, tc.want.rejected, errors.Is(err, ErrRequestRejected), "unexpected error: %v", err)
+			assert.ErrorContains(t, err, errorMessage)
+		})
+	}
+}
+
+func TestCertSignerSignExpiresTasks(t *testing.T) {
+	type args struct {
+		maxTaskAge *metav1.Duration
+		task       Task
+	}
+	type want struct {
+		buildErr        error
+		expired         bool
+		maxRequeueAfter time.Duration
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldKeepPollingTaskWithinMaxTaskAge": {
+			args: args{
+				maxTaskAge: &metav1.Duration{Duration: time.Hour},
+				task:       Task{ID: "task-1", SubmittedAt: time.Now().Add(-10 * time.Minute), PollingInterval: time.Minute},
+			},
+			want: want{maxRequeueAfter: time.Minute},
+		},
+		"ShouldPollTaskAgainWhenItExpires": {
+			args: args{
+				maxTaskAge: &metav1.Duration{Duration: time.Hour},
+				task:       Task{ID: "task-1", SubmittedAt: time.Now().Add(-55 * time.Minute), PollingInterval: 10 * time.Minute},
+			},
+			want: want{maxRequeueAfter: 5 * time.Minute},
+		},
+		"ShouldExpireTaskOlderThanMaxTaskAge": {
+			args: args{
+				maxTaskAge: &metav1.Duration{Duration: time.Hour},
+				task:       Task{ID: "task-1", SubmittedAt: time.Now().Add(-2 * time.Hour)},
+			},
+			want: want{expired: true},
+		},
+		"ShouldExpireTaskOlderThanDefaultMaxTaskAge": {
+			args: args{
+				task: Task{ID: "task-1", SubmittedAt: time.Now().Add(-defaultMaxTaskAge - time.Minute)},
+			},
+			want: want{expired: true},
+		},
+		"ShouldNotExpireTaskWithoutSubmissionTime": {
+			args: args{
+				maxTaskAge: &metav1.Duration{Duration: time.Hour},
+				task:       Task{ID: "task-1", PollingInterval: time.Minute},
+			},
+			want: want{maxRequeueAfter: time.Minute},
+		},
+		"ShouldFailWithInvalidMaxTaskAge": {
+			args: args{
+				maxTaskAge: &metav1.Duration{Duration: -time.Hour},
+			},
+			want: want{buildErr: errInvalidMaxTaskAge},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer server.Close()
+
+			issuerSpec := &certv1alpha1.IssuerSpec{
+				APIEndpoint:      server.URL + "/",
+				DownloadEndpoint: testDownloadPath,
+				Form:             fakecertapi.FormChain,
+				MaxTaskAge:       tc.args.maxTaskAge,
+			}
+
+			signer, err := CertSignerFromIssuerAndSecretData(issuerSpec, map[string][]byte{authorizationHeaderSecretKey: []byte(testToken)}, fake.NewClientBuilder().Build())
+			if tc.want.buildErr != nil {
+				assert.True(t, errors.Is(err, tc.want.buildErr), "unexpected error: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+
+			result, err := signer.Sign(context.Background(), logr.Discard(), SignRequest{}, tc.args.task)
+			if tc.want.expired {
+				assert.True(t, errors.Is(err, ErrTaskExpired), "unexpected error: %v", err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.True(t, result.Pending())
+			assert.LessOrEqual(t, result.RequeueAfter, tc.want.maxRequeueAfter)
+			assert.Greater(t, result.RequeueAfter, tc.want.maxRequeueAfter-time.Minute)
+		})
+	}
+}
+
+func TestCertSignerSignHonorsServerDelays(t *testing.T) {
+	type args struct {
+		task       Task
+		statusCode int
+		body       string
+		retryAfter string
+	}
+	type want struct {
+		requeueAfter    time.Duration
+		pollingInterval time.Duration
+		retryAfterErr   bool
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldPollAtIntervalOfPostResponse": {
+			args: args{
+				statusCode: http.StatusOK,
+				body:       `{"taskId": "task-1", "pollingInterval": 12}`,
+			},
+			want: want{requeueAfter: 12 * time.Second, pollingInterval: 12 * time.Second},
+		},
+		"ShouldPollAtRetryAfterOfPostResponse": {
+			args: args{
+				statusCode: http.StatusOK,
+				body:       `{"taskId": "task-1"}`,
+				retryAfter: "8",
+			},
+			want: want{requeueAfter: 8 * time.Second, pollingInterval: 8 * time.Second},
+		},
+		"ShouldPollAtRetryAfterOfAcceptedResponse": {
+			args: args{
+				task:       Task{ID: "task-1", SubmittedAt: time.Now(), PollingInterval: time.Minute},
+				statusCode: http.StatusAccepted,
+				retryAfter: "7",
+			},
+			want: want{requeueAfter: 7 * time.Second, pollingInterval: time.Minute},
+		},
+		"ShouldPollAtIntervalOfTask": {
+			args: args{
+				task:       Task{ID: "task-1", SubmittedAt: time.Now(), PollingInterval: time.Minute},
+				statusCode: http.StatusAccepted,
+			},
+			want: want{requeueAfter: time.Minute, pollingInterval: time.Minute},
+		},
+		"ShouldBoundRetryAfter": {
+			args: args{
+				task:       Task{ID: "task-1", SubmittedAt: time.Now()},
+				statusCode: http.StatusNotFound,
+				retryAfter: "86400",
+			},
+			want: want{requeueAfter: maxServerDelay},
+		},
+		"ShouldRetryAfterTooManyRequests": {
+			args: args{
+				statusCode: http.StatusTooManyRequests,
+				retryAfter: "30",
+			},
+			want: want{requeueAfter: 30 * time.Second, retryAfterErr: true},
+		},
+		"ShouldRetryAfterServiceUnavailable": {
+			args: args{
+				task:       Task{ID: "task-1", SubmittedAt: time.Now()},
+				statusCode: http.StatusServiceUnavailable,
+				retryAfter: "45",
+			},
+			want: want{requeueAfter: 45 * time.Second, retryAfterErr: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if tc.args.retryAfter != "" {
+					w.Header().Set("Retry-After", tc.args.retryAfter)
+				}
+				w.WriteHeader(tc.args.statusCode)
+				_, _ = w.Write([]byte(tc.args.body))
+			}))
+			defer server.Close()
+
+			issuerSpec := &certv1alpha1.IssuerSpec{
+				APIEndpoint:      server.URL + "/",
+				DownloadEndpoint: testDownloadPath,
+				Form:             fakecertapi.FormChain,
+				CertificateRestrictions: certv1alpha1.Restrictions{
+					SubjectAltNamesRestrictions: certv1alpha1.SubjectAltNamesRestrictions{AllowDNSNames: true},
+				},
+			}
+
+			signer, err := CertSignerFromIssuerAndSecretData(issuerSpec, map[string][]byte{authorizationHeaderSecretKey: []byte(testToken)}, fake.NewClientBuilder().Build())
+			assert.NoError(t, err)
+
+			result, err := signer.Sign(context.Background(), logr.Discard(), SignRequest{CSR: generateTestCSR(t)}, tc.args.task)
+			if tc.want.retryAfterErr {
+				var retryAfterErr *RetryAfterError
+				if assert.True(t, errors.As(err, &retryAfterErr), "unexpected error: %v", err) {
+					assert.Equal(t, tc.want.requeueAfter, retryAfterErr.RetryAfter)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.True(t, result.Pending())
+			assert.Equal(t, tc.want.requeueAfter, result.RequeueAfter)
+			assert.Equal(t, tc.want.pollingInterval, result.Task.PollingInterval)
+		})
+	}
+}
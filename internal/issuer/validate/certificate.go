@@ -0,0 +1,204 @@
+package validate
+
+import (
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	cmpki "github.com/cert-manager/cert-manager/pkg/util/pki"
+)
+
+const (
+	errMismatchMsg = "%s of the Certificate %q do not match the CSR %q"
+
+	// certificateClockSkew is the time by which the Certificate may start being valid after the
+	// current time, to allow for clocks of the signer backend running ahead.
+	certificateClockSkew = 5 * time.Minute
+)
+
+var (
+	errEmptyCertificateChain = errors.New("no certificate found in the Certificate chain")
+	errPublicKeyMismatch     = errors.New("public key of the Certificate does not match the CSR")
+	errUnsupportedPublicKey  = errors.New("unsupported public key type of the Certificate")
+	errNotYetValid           = errors.New("certificate is not yet valid")
+	errExpired               = errors.New("certificate is expired")
+)
+
+// EnsureCertificate makes sure that the Certificate issued for the CSR is the one which was requested:
+// its public key, subject and subject alternative names must match the CSR, it must be valid at the
+// given time, and the chain must verify up to the CA, if the signer backend returned one.
+func EnsureCertificate(csr *x509.CertificateRequest, chainPEM, caPEM []byte, now time.Time) error {
+	chain, err := cmpki.DecodeX509CertificateChainBytes(chainPEM)
+	if err != nil {
+		return fmt.Errorf(errValidationFailedMsg, "chain", err)
+	}
+	if len(chain) == 0 {
+		return fmt.Errorf(errValidationFailedMsg, "chain", errEmptyCertificateChain)
+	}
+	leaf := chain[0]
+
+	if err := validatePublicKey(leaf, csr); err != nil {
+		return fmt.Errorf(errValidationFailedMsg, "key", err)
+	}
+
+	if err := validateIssuedSubjectAltNames(leaf, csr); err != nil {
+		return fmt.Errorf(errValidationFailedMsg, "subjectAltName", err)
+	}
+
+	if err := validateIssuedSubject(leaf.Subject, csr.Subject); err != nil {
+		return fmt.Errorf(errValidationFailedMsg, "subject", err)
+	}
+
+	if err := validateValidity(leaf, now); err != nil {
+		return fmt.Errorf(errValidationFailedMsg, "validity", err)
+	}
+
+	if err := validateChain(chain, caPEM, now); err != nil {
+		return fmt.Errorf(errValidationFailedMsg, "chain", err)
+	}
+
+	return nil
+}
+
+// validatePublicKey validates that the public key of the Certificate is the public key of the CSR.
+func validatePublicKey(certificate *x509.Certificate, csr *x509.CertificateRequest) error {
+	publicKey, ok := certificate.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	if !ok {
+		return fmt.Errorf("%w: %T", errUnsupportedPublicKey, certificate.PublicKey)
+	}
+
+	if !publicKey.Equal(csr.PublicKey) {
+		return errPublicKeyMismatch
+	}
+
+	return nil
+}
+
+// validateIssuedSubjectAltNames validates that the subject alternative names of the Certificate are the ones
+// of the CSR. The common name of the CSR may be added as a DNS name, as signer backends commonly do.
+func validateIssuedSubjectAltNames(certificate *x509.Certificate, csr *x509.CertificateRequest) error {
+	requestedDNSNames := csr.DNSNames
+	if csr.Subject.CommonName != "" && !containsFold(csr.Subject.CommonName, csr.DNSNames) && containsFold(csr.Subject.CommonName, certificate.DNSNames) {
+		requestedDNSNames = append(slices.Clone(csr.DNSNames), csr.Subject.CommonName)
+	}
+	if !equalFold(certificate.DNSNames, requestedDNSNames) {
+		return fmt.Errorf(errMismatchMsg, "dnsNames", certificate.DNSNames, csr.DNSNames)
+	}
+
+	issuedIPAddresses := make([]string, 0, len(certificate.IPAddresses))
+	for _, ip := range certificate.IPAddresses {
+		issuedIPAddresses = append(issuedIPAddresses, ip.String())
+	}
+	requestedIPAddresses := make([]string, 0, len(csr.IPAddresses))
+	for _, ip := range csr.IPAddresses {
+		requestedIPAddresses = append(requestedIPAddresses, ip.String())
+	}
+	if !equalFold(issuedIPAddresses, requestedIPAddresses) {
+		return fmt.Errorf(errMismatchMsg, "ipAddresses", issuedIPAddresses, requestedIPAddresses)
+	}
+
+	issuedURIs := make([]string, 0, len(certificate.URIs))
+	for _, uri := range certificate.URIs {
+		issuedURIs = append(issuedURIs, uri.String())
+	}
+	requestedURIs := make([]string, 0, len(csr.URIs))
+	for _, uri := range csr.URIs {
+		requestedURIs = append(requestedURIs, uri.String())
+	}
+	if !slices.Equal(sorted(issuedURIs), sorted(requestedURIs)) {
+		return fmt.Errorf(errMismatchMsg, "uris", issuedURIs, requestedURIs)
+	}
+
+	if !equalFold(certificate.EmailAddresses, csr.EmailAddresses) {
+		return fmt.Errorf(errMismatchMsg, "emailAddresses", certificate.EmailAddresses, csr.EmailAddresses)
+	}
+
+	return nil
+}
+
+// validateIssuedSubject validates that the subject of the Certificate is the subject of the CSR.
+func validateIssuedSubject(issued, requested pkix.Name) error {
+	if issued.CommonName != requested.CommonName {
+		return fmt.Errorf("commonName of the Certificate %q does not match the CSR %q", issued.CommonName, requested.CommonName)
+	}
+
+	if issued.SerialNumber != requested.SerialNumber {
+		return fmt.Errorf("serialNumber of the Certificate %q does not match the CSR %q", issued.SerialNumber, requested.SerialNumber)
+	}
+
+	attributes := []struct {
+		name              string
+		issued, requested []string
+	}{
+		{"organizations", issued.Organization, requested.Organization},
+		{"organizationalUnits", issued.OrganizationalUnit, requested.OrganizationalUnit},
+		{"countries", issued.Country, requested.Country},
+		{"provinces", issued.Province, requested.Province},
+		{"localities", issued.Locality, requested.Locality},
+		{"streetAddresses", issued.StreetAddress, requested.StreetAddress},
+		{"postalCodes", issued.PostalCode, requested.PostalCode},
+	}
+
+	for _, attribute := range attributes {
+		if !slices.Equal(sorted(attribute.issued), sorted(attribute.requested)) {
+			return fmt.Errorf(errMismatchMsg, attribute.name, attribute.issued, attribute.requested)
+		}
+	}
+
+	return nil
+}
+
+// validateValidity validates that the Certificate is valid at the given time, allowing for clock skew.
+func validateValidity(certificate *x509.Certificate, now time.Time) error {
+	if now.Add(certificateClockSkew).Before(certificate.NotBefore) {
+		return fmt.Errorf("%w: valid from %s", errNotYetValid, certificate.NotBefore.UTC().Format(time.RFC3339))
+	}
+
+	if now.After(certificate.NotAfter) {
+		return fmt.Errorf("%w: valid until %s", errExpired, certificate.NotAfter.UTC().Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// validateChain validates that the chain builds from the Certificate up to the CA. A chain without
+// a CA, which consists of a single Certificate, has nothing to be verified against.
+func validateChain(chain []*x509.Certificate, caPEM []byte, now time.Time) error {
+	if len(caPEM) == 0 {
+		return nil
+	}
+
+	cas, err := cmpki.DecodeX509CertificateChainBytes(caPEM)
+	if err != nil {
+		return fmt.Errorf("failed to decode the CA: %w", err)
+	}
+
+	roots := x509.NewCertPool()
+	for _, ca := range cas {
+		roots.AddCert(ca)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, intermediate := range chain[1:] {
+		intermediates.AddCert(intermediate)
+	}
+
+	leaf := chain[0]
+	verifyTime := now
+	if verifyTime.Before(leaf.NotBefore) {
+		verifyTime = leaf.NotBefore
+	}
+
+	_, err = leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   verifyTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+
+	return err
+}
@@ -0,0 +1,186 @@
+package validate
+
+import (
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	cmpki "github.com/cert-manager/cert-manager/pkg/util/pki"
+	"github.com/stretchr/testify/assert"
+)
+
+const testCommonName = "test.example.com"
+
+var testNow = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func TestEnsureCertificate(t *testing.T) {
+	ca, caKey := generateTestCA(t)
+	otherCA, _ := generateTestCA(t)
+
+	key, err := cmpki.GenerateECPrivateKey(cmpki.ECCurve256)
+	assert.NoError(t, err)
+	otherKey, err := cmpki.GenerateECPrivateKey(cmpki.ECCurve256)
+	assert.NoError(t, err)
+
+	csr := &x509.CertificateRequest{
+		Subject:   pkix.Name{CommonName: testCommonName, Organization: []string{testName}},
+		DNSNames:  []string{testCommonName},
+		PublicKey: key.Public(),
+	}
+
+	type params struct {
+		csr       *x509.CertificateRequest
+		publicKey crypto.PublicKey
+		mutate    func(*x509.Certificate)
+		ca        []byte
+		now       time.Time
+	}
+
+	type want struct {
+		errMsg string
+	}
+
+	cases := map[string]struct {
+		params params
+		want   want
+	}{
+		"ShouldSucceedWithMatchingCertificate": {
+			params: params{},
+		},
+		"ShouldSucceedWithoutCA": {
+			params: params{ca: []byte{}},
+		},
+		"ShouldSucceedWithCommonNameAddedAsDNSName": {
+			params: params{
+				csr: &x509.CertificateRequest{
+					Subject:   pkix.Name{CommonName: testCommonName, Organization: []string{testName}},
+					PublicKey: key.Public(),
+				},
+			},
+		},
+		"ShouldSucceedWithinClockSkew": {
+			params: params{now: testNow.Add(-time.Minute)},
+		},
+		"ShouldFailWithOtherPublicKey": {
+			params: params{publicKey: otherKey.Public()},
+			want: want{
+				errMsg: "key validation failed: public key of the Certificate does not match the CSR",
+			},
+		},
+		"ShouldFailWithOtherDNSNames": {
+			params: params{
+				mutate: func(certificate *x509.Certificate) {
+					certificate.DNSNames = []string{testCommonName, "other.example.com"}
+				},
+			},
+			want: want{
+				errMsg: `subjectAltName validation failed: dnsNames of the Certificate ["test.example.com" "other.example.com"] do not match the CSR ["test.example.com"]`,
+			},
+		},
+		"ShouldFailWithOtherCommonName": {
+			params: params{
+				mutate: func(certificate *x509.Certificate) {
+					certificate.Subject.CommonName = "other.example.com"
+				},
+			},
+			want: want{
+				errMsg: `subject validation failed: commonName of the Certificate "other.example.com" does not match the CSR "test.example.com"`,
+			},
+		},
+		"ShouldFailWithOtherOrganizations": {
+			params: params{
+				mutate: func(certificate *x509.Certificate) {
+					certificate.Subject.Organization = []string{allowed}
+				},
+			},
+			want: want{
+				errMsg: `subject validation failed: organizations of the Certificate ["Allowed"] do not match the CSR ["test"]`,
+			},
+		},
+		"ShouldFailWithExpiredCertificate": {
+			params: params{now: testNow.Add(2 * time.Hour)},
+			want: want{
+				errMsg: "validity validation failed: certificate is expired: valid until 2024-01-01T01:00:00Z",
+			},
+		},
+		"ShouldFailWithCertificateNotYetValid": {
+			params: params{now: testNow.Add(-time.Hour)},
+			want: want{
+				errMsg: "validity validation failed: certificate is not yet valid: valid from 2024-01-01T00:00:00Z",
+			},
+		},
+		"ShouldFailWithChainOfOtherCA": {
+			params: params{ca: otherCA},
+			want: want{
+				errMsg: "chain validation failed: x509: certificate signed by unknown authority",
+			},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			params := test.params
+			if params.csr == nil {
+				params.csr = csr
+			}
+			if params.publicKey == nil {
+				params.publicKey = key.Public()
+			}
+			if params.ca == nil {
+				params.ca = ca
+			}
+			if params.now.IsZero() {
+				params.now = testNow
+			}
+
+			template := &x509.Certificate{
+				SerialNumber: big.NewInt(2),
+				Subject:      pkix.Name{CommonName: testCommonName, Organization: []string{testName}},
+				DNSNames:     []string{testCommonName},
+				NotBefore:    testNow,
+				NotAfter:     testNow.Add(time.Hour),
+			}
+			if params.mutate != nil {
+				params.mutate(template)
+			}
+
+			caCert, err := cmpki.DecodeX509CertificateBytes(ca)
+			assert.NoError(t, err)
+			certificatePEM, _, err := cmpki.SignCertificate(template, caCert, params.publicKey, caKey)
+			assert.NoError(t, err)
+
+			err = EnsureCertificate(params.csr, certificatePEM, params.ca, params.now)
+			if test.want.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, test.want.errMsg)
+			}
+		})
+	}
+}
+
+// generateTestCA returns a PEM encoded self-signed CA certificate, valid at testNow, and its key.
+func generateTestCA(t *testing.T) ([]byte, crypto.Signer) {
+	t.Helper()
+
+	caKey, err := cmpki.GenerateECPrivateKey(cmpki.ECCurve256)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             testNow.Add(-time.Hour),
+		NotAfter:              testNow.Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	caPEM, _, err := cmpki.SignCertificate(template, template, caKey.Public(), caKey)
+	assert.NoError(t, err)
+
+	return caPEM, caKey
+}
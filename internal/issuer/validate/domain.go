@@ -2,23 +2,79 @@ package validate
 
 import (
 	"fmt"
+	"strings"
+
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
 )
 
-// validateNameDomain checks if the given names match the allowed domains and allowed subdomains.
-func validateNameDomain(names []string, allowedDomains, allowedSubDomains []string) error {
-	for _, name := range names {
-		for _, domain := range allowedDomains {
-			if !hasSuffix(name, domain) {
-				return fmt.Errorf(errAllowedValuesStringMsg, ".spec.commonName domain", allowedDomains)
-			}
-		}
+const (
+	errNameNotAllowedMsg = "%s %q does not match any of the allowed %s %q"
 
-		for _, subdomain := range allowedSubDomains {
-			if !hasSuffix(name, subdomain) {
-				return fmt.Errorf(errAllowedValuesStringMsg, ".spec.commonName subdomain", allowedSubDomains)
-			}
-		}
+	// wildcardPrefix is the prefix of a domain which matches the names one label below it.
+	wildcardPrefix = "*."
+)
+
+// validateNameDomain checks that the name, given in the field of the Certificate, matches one of the allowed
+// domains and, if any are set, one of the allowed subdomains.
+func validateNameDomain(field, name string, domainRestrictions certv1alpha1.DomainRestrictions) error {
+	matchMode := domainRestrictions.MatchMode
+	if matchMode == "" {
+		matchMode = certv1alpha1.DomainMatchApexAndSubdomains
+	}
+
+	if len(domainRestrictions.AllowedDomains) > 0 && !matchesAnyDomain(name, domainRestrictions.AllowedDomains, matchMode) {
+		return fmt.Errorf(errNameNotAllowedMsg, field, name, "domains", domainRestrictions.AllowedDomains)
+	}
+
+	if len(domainRestrictions.AllowedSubdomains) > 0 && !matchesAnyDomain(name, domainRestrictions.AllowedSubdomains, matchMode) {
+		return fmt.Errorf(errNameNotAllowedMsg, field, name, "subdomains", domainRestrictions.AllowedSubdomains)
 	}
 
 	return nil
 }
+
+// matchesAnyDomain checks if the name matches any of the domains.
+func matchesAnyDomain(name string, domains []string, matchMode string) bool {
+	name = normalizeDomain(name)
+	for _, domain := range domains {
+		if matchesDomain(name, normalizeDomain(domain), matchMode) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesDomain checks if the normalized name matches the normalized domain. A wildcard domain matches the
+// names one label below it, while other domains match themselves, their subdomains, or both, according to
+// the match mode. Subdomains are matched on label boundaries, so that "example.com" does not match "badexample.com".
+func matchesDomain(name, domain, matchMode string) bool {
+	if domain == "" {
+		return false
+	}
+
+	if base, ok := strings.CutPrefix(domain, wildcardPrefix); ok {
+		label, ok := strings.CutSuffix(name, "."+base)
+		return ok && label != "" && !strings.Contains(label, ".")
+	}
+
+	switch matchMode {
+	case certv1alpha1.DomainMatchApexOnly:
+		return name == domain
+	case certv1alpha1.DomainMatchSubdomainsOnly:
+		return isSubdomain(name, domain)
+	default:
+		return name == domain || isSubdomain(name, domain)
+	}
+}
+
+// isSubdomain checks if the name is a subdomain of the domain, at any depth.
+func isSubdomain(name, domain string) bool {
+	label, ok := strings.CutSuffix(name, "."+domain)
+	return ok && label != ""
+}
+
+// normalizeDomain lowercases a domain and removes its trailing dot, so that equal domains compare as equal.
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
@@ -4,14 +4,16 @@ import (
 	"fmt"
 	"testing"
 
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
 	"github.com/stretchr/testify/assert"
 )
 
+const testNameField = ".spec.commonName"
+
 func TestValidateNameDomain(t *testing.T) {
 	type params struct {
-		names             []string
-		allowedDomains    []string
-		allowedSubDomains []string
+		name         string
+		restrictions certv1alpha1.DomainRestrictions
 	}
 
 	type want struct {
@@ -24,67 +26,154 @@ func TestValidateNameDomain(t *testing.T) {
 	}{
 		"ShouldSucceedWithValidDomain": {
 			params: params{
-				names:          []string{"example.com"},
-				allowedDomains: []string{"example.com"},
+				name:         "example.com",
+				restrictions: certv1alpha1.DomainRestrictions{AllowedDomains: []string{"example.com"}},
 			},
-			want: want{
-				errMsg: "",
+		},
+		"ShouldSucceedWithSubdomainOfValidDomain": {
+			params: params{
+				name:         "app.team.example.com",
+				restrictions: certv1alpha1.DomainRestrictions{AllowedDomains: []string{"example.com"}},
+			},
+		},
+		"ShouldSucceedWithAnyOfSeveralDomains": {
+			params: params{
+				name:         "app.example.org",
+				restrictions: certv1alpha1.DomainRestrictions{AllowedDomains: []string{"example.com", "example.org"}},
+			},
+		},
+		"ShouldSucceedIgnoringCaseAndTrailingDots": {
+			params: params{
+				name:         "App.Example.COM.",
+				restrictions: certv1alpha1.DomainRestrictions{AllowedDomains: []string{"example.com."}},
 			},
 		},
 		"ShouldFailWithInvalidDomain": {
 			params: params{
-				names:          []string{"invalid.com"},
-				allowedDomains: []string{"example.com"},
+				name:         "invalid.com",
+				restrictions: certv1alpha1.DomainRestrictions{AllowedDomains: []string{"example.com"}},
 			},
 			want: want{
-				errMsg: fmt.Sprintf(errAllowedValuesStringMsg, ".spec.commonName domain", []string{"example.com"}),
+				errMsg: fmt.Sprintf(errNameNotAllowedMsg, testNameField, "invalid.com", "domains", []string{"example.com"}),
 			},
 		},
-		"ShouldSucceedWithValidSubdomain": {
+		"ShouldFailWithDomainMatchingWithinLabel": {
 			params: params{
-				names:             []string{"sub.example.com"},
-				allowedSubDomains: []string{"sub.example.com"},
+				name:         "evilexample.com",
+				restrictions: certv1alpha1.DomainRestrictions{AllowedDomains: []string{"example.com"}},
 			},
 			want: want{
-				errMsg: "",
+				errMsg: fmt.Sprintf(errNameNotAllowedMsg, testNameField, "evilexample.com", "domains", []string{"example.com"}),
 			},
 		},
-		"ShouldFailWithInvalidSubdomain": {
+		"ShouldSucceedWithWildcardDomain": {
+			params: params{
+				name:         "app.apps.example.com",
+				restrictions: certv1alpha1.DomainRestrictions{AllowedDomains: []string{"*.apps.example.com"}},
+			},
+		},
+		"ShouldSucceedWithWildcardNameOfWildcardDomain": {
+			params: params{
+				name:         "*.apps.example.com",
+				restrictions: certv1alpha1.DomainRestrictions{AllowedDomains: []string{"*.apps.example.com"}},
+			},
+		},
+		"ShouldFailWithApexOfWildcardDomain": {
+			params: params{
+				name:         "apps.example.com",
+				restrictions: certv1alpha1.DomainRestrictions{AllowedDomains: []string{"*.apps.example.com"}},
+			},
+			want: want{
+				errMsg: fmt.Sprintf(errNameNotAllowedMsg, testNameField, "apps.example.com", "domains", []string{"*.apps.example.com"}),
+			},
+		},
+		"ShouldFailWithNameTwoLabelsBelowWildcardDomain": {
+			params: params{
+				name:         "a.b.apps.example.com",
+				restrictions: certv1alpha1.DomainRestrictions{AllowedDomains: []string{"*.apps.example.com"}},
+			},
+			want: want{
+				errMsg: fmt.Sprintf(errNameNotAllowedMsg, testNameField, "a.b.apps.example.com", "domains", []string{"*.apps.example.com"}),
+			},
+		},
+		"ShouldSucceedWithSubdomainInSubdomainsOnlyMode": {
+			params: params{
+				name: "app.example.com",
+				restrictions: certv1alpha1.DomainRestrictions{
+					AllowedDomains: []string{"example.com"},
+					MatchMode:      certv1alpha1.DomainMatchSubdomainsOnly,
+				},
+			},
+		},
+		"ShouldFailWithApexInSubdomainsOnlyMode": {
+			params: params{
+				name: "example.com",
+				restrictions: certv1alpha1.DomainRestrictions{
+					AllowedDomains: []string{"example.com"},
+					MatchMode:      certv1alpha1.DomainMatchSubdomainsOnly,
+				},
+			},
+			want: want{
+				errMsg: fmt.Sprintf(errNameNotAllowedMsg, testNameField, "example.com", "domains", []string{"example.com"}),
+			},
+		},
+		"ShouldSucceedWithApexInApexOnlyMode": {
+			params: params{
+				name: "example.com",
+				restrictions: certv1alpha1.DomainRestrictions{
+					AllowedDomains: []string{"example.com"},
+					MatchMode:      certv1alpha1.DomainMatchApexOnly,
+				},
+			},
+		},
+		"ShouldFailWithSubdomainInApexOnlyMode": {
 			params: params{
-				names:             []string{"sub.invalid.com"},
-				allowedSubDomains: []string{"sub.example.com"},
+				name: "app.example.com",
+				restrictions: certv1alpha1.DomainRestrictions{
+					AllowedDomains: []string{"example.com"},
+					MatchMode:      certv1alpha1.DomainMatchApexOnly,
+				},
 			},
 			want: want{
-				errMsg: fmt.Sprintf(errAllowedValuesStringMsg, ".spec.commonName subdomain", []string{"sub.example.com"}),
+				errMsg: fmt.Sprintf(errNameNotAllowedMsg, testNameField, "app.example.com", "domains", []string{"example.com"}),
 			},
 		},
-		"ShouldFailWithInvalidDomainAndValidSubdomain": {
+		"ShouldSucceedWithValidSubdomain": {
+			params: params{
+				name:         "app.sub.example.com",
+				restrictions: certv1alpha1.DomainRestrictions{AllowedSubdomains: []string{"sub.example.com"}},
+			},
+		},
+		"ShouldFailWithInvalidSubdomain": {
 			params: params{
-				names:             []string{"invalid.com", "sub.example.com"},
-				allowedDomains:    []string{"example.com"},
-				allowedSubDomains: []string{"sub.example.com"},
+				name:         "sub.invalid.com",
+				restrictions: certv1alpha1.DomainRestrictions{AllowedSubdomains: []string{"sub.example.com"}},
 			},
 			want: want{
-				errMsg: fmt.Sprintf(errAllowedValuesStringMsg, ".spec.commonName domain", []string{"example.com"}),
+				errMsg: fmt.Sprintf(errNameNotAllowedMsg, testNameField, "sub.invalid.com", "subdomains", []string{"sub.example.com"}),
 			},
 		},
 		"ShouldFailWithValidDomainAndInvalidSubdomain": {
 			params: params{
-				names:             []string{"example.com", "sub.invalid.com"},
-				allowedDomains:    []string{"example.com"},
-				allowedSubDomains: []string{"sub.example.com"},
+				name: "other.example.com",
+				restrictions: certv1alpha1.DomainRestrictions{
+					AllowedDomains:    []string{"example.com"},
+					AllowedSubdomains: []string{"sub.example.com"},
+				},
 			},
 			want: want{
-				errMsg: fmt.Sprintf(errAllowedValuesStringMsg, ".spec.commonName subdomain", []string{"sub.example.com"}),
+				errMsg: fmt.Sprintf(errNameNotAllowedMsg, testNameField, "other.example.com", "subdomains", []string{"sub.example.com"}),
 			},
 		},
 	}
 
 	for name, test := range cases {
 		t.Run(name, func(t *testing.T) {
-			err := validateNameDomain(test.params.names, test.params.allowedDomains, test.params.allowedSubDomains)
-			if err != nil {
-				assert.Equal(t, test.want.errMsg, err.Error())
+			err := validateNameDomain(testNameField, test.params.name, test.params.restrictions)
+			if test.want.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.want.errMsg)
 			}
 		})
 	}
@@ -0,0 +1,127 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+)
+
+var errInvalidSubjectPattern = errors.New("invalid subject pattern")
+
+// SubjectPatterns are the compiled allowed patterns of the subject restrictions of an Issuer. They are
+// compiled once, when the signer of the Issuer is built, rather than for every CSR.
+type SubjectPatterns struct {
+	organizations       patternSet
+	countries           patternSet
+	organizationalUnits patternSet
+	localities          patternSet
+	provinces           patternSet
+	streetAddresses     patternSet
+	postalCodes         patternSet
+	serialNumbers       patternSet
+}
+
+// patternSet is a set of compiled patterns of a subject field, along with the name and the source of the
+// patterns, which tell in validation errors which patterns rejected a value.
+type patternSet struct {
+	name     string
+	syntax   string
+	sources  []string
+	patterns []*regexp.Regexp
+}
+
+// CompileSubjectPatterns compiles the allowed patterns of the subject restrictions. Regex patterns are
+// anchored, so that they must match the whole value, as glob patterns do.
+func CompileSubjectPatterns(subjectRestrictions certv1alpha1.SubjectRestrictions) (*SubjectPatterns, error) {
+	allowedPatterns := subjectRestrictions.AllowedPatterns
+	syntax := allowedPatterns.Syntax
+	if syntax == "" {
+		syntax = certv1alpha1.PatternSyntaxGlob
+	}
+
+	subjectPatterns := &SubjectPatterns{}
+	fields := []struct {
+		set     *patternSet
+		name    string
+		sources []string
+	}{
+		{&subjectPatterns.organizations, "organizations", allowedPatterns.Organizations},
+		{&subjectPatterns.countries, "countries", allowedPatterns.Countries},
+		{&subjectPatterns.organizationalUnits, "organizationalUnits", allowedPatterns.OrganizationalUnits},
+		{&subjectPatterns.localities, "localities", allowedPatterns.Localities},
+		{&subjectPatterns.provinces, "provinces", allowedPatterns.Provinces},
+		{&subjectPatterns.streetAddresses, "streetAddresses", allowedPatterns.StreetAddresses},
+		{&subjectPatterns.postalCodes, "postalCodes", allowedPatterns.PostalCodes},
+		{&subjectPatterns.serialNumbers, "serialNumbers", allowedPatterns.SerialNumbers},
+	}
+
+	for _, field := range fields {
+		set, err := compilePatternSet(".spec.certificateRestrictions.subjectRestrictions.allowedPatterns."+field.name, syntax, field.sources)
+		if err != nil {
+			return nil, err
+		}
+		*field.set = set
+	}
+
+	return subjectPatterns, nil
+}
+
+// compilePatternSet compiles the patterns of a subject field in the given syntax.
+func compilePatternSet(name, syntax string, sources []string) (patternSet, error) {
+	set := patternSet{name: name, syntax: syntax, sources: sources}
+	for _, source := range sources {
+		expression := source
+		switch syntax {
+		case certv1alpha1.PatternSyntaxGlob:
+			expression = globToRegexp(source)
+		case certv1alpha1.PatternSyntaxRegex:
+		default:
+			return patternSet{}, fmt.Errorf("%w %q in %s: unsupported syntax %q", errInvalidSubjectPattern, source, name, syntax)
+		}
+
+		pattern, err := regexp.Compile("^(?:" + expression + ")$")
+		if err != nil {
+			return patternSet{}, fmt.Errorf("%w %q in %s: %v", errInvalidSubjectPattern, source, name, err)
+		}
+		set.patterns = append(set.patterns, pattern)
+	}
+
+	return set, nil
+}
+
+// globToRegexp returns the regular expression of a glob pattern, in which "*" matches any sequence of
+// characters and "?" matches any single character, while all other characters match themselves.
+func globToRegexp(glob string) string {
+	var expression strings.Builder
+	for _, r := range glob {
+		switch r {
+		case '*':
+			expression.WriteString(".*")
+		case '?':
+			expression.WriteString(".")
+		default:
+			expression.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	return expression.String()
+}
+
+// isSet checks if there are any patterns in the set.
+func (s patternSet) isSet() bool {
+	return len(s.patterns) > 0
+}
+
+// matches checks if the value matches any of the patterns in the set.
+func (s patternSet) matches(value string) bool {
+	for _, pattern := range s.patterns {
+		if pattern.MatchString(value) {
+			return true
+		}
+	}
+
+	return false
+}
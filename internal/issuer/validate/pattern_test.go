@@ -0,0 +1,106 @@
+package validate
+
+import (
+	"errors"
+	"testing"
+
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompileSubjectPatterns(t *testing.T) {
+	type params struct {
+		allowedPatterns certv1alpha1.SubjectPatterns
+		value           string
+	}
+
+	type want struct {
+		err     error
+		matches bool
+	}
+
+	cases := map[string]struct {
+		params params
+		want   want
+	}{
+		"ShouldMatchGlobPattern": {
+			params: params{
+				allowedPatterns: certv1alpha1.SubjectPatterns{OrganizationalUnits: []string{"team-*"}},
+				value:           "team-payments",
+			},
+			want: want{matches: true},
+		},
+		"ShouldMatchGlobPatternWithSingleCharacter": {
+			params: params{
+				allowedPatterns: certv1alpha1.SubjectPatterns{OrganizationalUnits: []string{"team-?"}},
+				value:           "team-a",
+			},
+			want: want{matches: true},
+		},
+		"ShouldMatchGlobPatternCharactersLiterally": {
+			params: params{
+				allowedPatterns: certv1alpha1.SubjectPatterns{OrganizationalUnits: []string{"team.(a)"}},
+				value:           "teamx(a)",
+			},
+			want: want{matches: false},
+		},
+		"ShouldNotMatchGlobPatternPartially": {
+			params: params{
+				allowedPatterns: certv1alpha1.SubjectPatterns{OrganizationalUnits: []string{"team-*"}},
+				value:           "dev-team-payments",
+			},
+			want: want{matches: false},
+		},
+		"ShouldMatchRegexPattern": {
+			params: params{
+				allowedPatterns: certv1alpha1.SubjectPatterns{
+					Syntax:              certv1alpha1.PatternSyntaxRegex,
+					OrganizationalUnits: []string{"team-[a-z]+"},
+				},
+				value: "team-payments",
+			},
+			want: want{matches: true},
+		},
+		"ShouldAnchorRegexPattern": {
+			params: params{
+				allowedPatterns: certv1alpha1.SubjectPatterns{
+					Syntax:              certv1alpha1.PatternSyntaxRegex,
+					OrganizationalUnits: []string{"team|ops"},
+				},
+				value: "teamwork",
+			},
+			want: want{matches: false},
+		},
+		"ShouldFailWithInvalidRegexPattern": {
+			params: params{
+				allowedPatterns: certv1alpha1.SubjectPatterns{
+					Syntax:              certv1alpha1.PatternSyntaxRegex,
+					OrganizationalUnits: []string{"team-[a-z"},
+				},
+			},
+			want: want{err: errInvalidSubjectPattern},
+		},
+		"ShouldFailWithUnsupportedSyntax": {
+			params: params{
+				allowedPatterns: certv1alpha1.SubjectPatterns{
+					Syntax:              "Wildcard",
+					OrganizationalUnits: []string{"team-*"},
+				},
+			},
+			want: want{err: errInvalidSubjectPattern},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			subjectPatterns, err := CompileSubjectPatterns(certv1alpha1.SubjectRestrictions{AllowedPatterns: test.params.allowedPatterns})
+			if test.want.err != nil {
+				assert.True(t, errors.Is(err, test.want.err), "unexpected error: %v", err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.want.matches, subjectPatterns.organizationalUnits.matches(test.params.value))
+		})
+	}
+}
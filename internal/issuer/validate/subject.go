@@ -5,79 +5,93 @@ import (
 )
 
 // validateOrganizations validates that only supported organizations are specified in the CSR.
-func validateOrganizations(organizations []string, allowedOrganizations []string) error {
+func validateOrganizations(organizations []string, allowedOrganizations []string, patterns patternSet) error {
 	for _, organization := range organizations {
-		if !containsString(organization, allowedOrganizations) {
-			return fmt.Errorf(errAllowedValuesStringMsg, ".spec.subject.organizations", allowedOrganizations)
+		if err := validateSubjectValue(".spec.subject.organizations", organization, allowedOrganizations, patterns); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
 // validateCountries validates that only supported countries are specified in the CSR.
-func validateCountries(countries []string, allowedCountries []string) error {
+func validateCountries(countries []string, allowedCountries []string, patterns patternSet) error {
 	for _, country := range countries {
-		if !containsString(country, allowedCountries) {
-			return fmt.Errorf(errAllowedValuesStringMsg, ".spec.subject.countries", allowedCountries)
+		if err := validateSubjectValue(".spec.subject.countries", country, allowedCountries, patterns); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
 // validateOrganizationalUnits validates that only supported organizational units are specified in the CSR.
-func validateOrganizationalUnits(units []string, allowedUnits []string) error {
+func validateOrganizationalUnits(units []string, allowedUnits []string, patterns patternSet) error {
 	for _, unit := range units {
-		if !containsString(unit, allowedUnits) {
-			return fmt.Errorf(errAllowedValuesStringMsg, ".spec.subject.organizationalUnits", allowedUnits)
+		if err := validateSubjectValue(".spec.subject.organizationalUnits", unit, allowedUnits, patterns); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
 // validateLocalities validates that only supported localities are specified in the CSR.
-func validateLocalities(localities []string, allowedLocalities []string) error {
+func validateLocalities(localities []string, allowedLocalities []string, patterns patternSet) error {
 	for _, locality := range localities {
-		if !containsString(locality, allowedLocalities) {
-			return fmt.Errorf(errAllowedValuesStringMsg, ".spec.subject.localities", allowedLocalities)
+		if err := validateSubjectValue(".spec.subject.localities", locality, allowedLocalities, patterns); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
 // validateProvinces validates that only supported provinces are specified in the CSR.
-func validateProvinces(provinces []string, allowedProvinces []string) error {
+func validateProvinces(provinces []string, allowedProvinces []string, patterns patternSet) error {
 	for _, province := range provinces {
-		if !containsString(province, allowedProvinces) {
-			return fmt.Errorf(errAllowedValuesStringMsg, ".spec.subject.provinces", allowedProvinces)
+		if err := validateSubjectValue(".spec.subject.provinces", province, allowedProvinces, patterns); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
 // validateStreetAddresses validates that only supported street addresses are specified in the CSR.
-func validateStreetAddresses(addresses []string, allowedAddresses []string) error {
+func validateStreetAddresses(addresses []string, allowedAddresses []string, patterns patternSet) error {
 	for _, address := range addresses {
-		if !containsString(address, allowedAddresses) {
-			return fmt.Errorf(errAllowedValuesStringMsg, ".spec.subject.streetAddresses", allowedAddresses)
+		if err := validateSubjectValue(".spec.subject.streetAddresses", address, allowedAddresses, patterns); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
 // validatePostalCodes validates that only supported postal codes are specified in the CSR.
-func validatePostalCodes(codes []string, allowedCodes []string) error {
+func validatePostalCodes(codes []string, allowedCodes []string, patterns patternSet) error {
 	for _, code := range codes {
-		if !containsString(code, allowedCodes) {
-			return fmt.Errorf(errAllowedValuesStringMsg, ".spec.subject.postalCodes", allowedCodes)
+		if err := validateSubjectValue(".spec.subject.postalCodes", code, allowedCodes, patterns); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
 // validateSerialNumbers validates that only supported serial numbers are specified in the CSR.
-func validateSerialNumbers(serialNumber string, allowedSerialNumbers []string) error {
-	if serialNumber != "" && !containsString(serialNumber, allowedSerialNumbers) {
-		return fmt.Errorf(errAllowedValuesStringMsg, "allowedSerialNumbers", allowedSerialNumbers)
+func validateSerialNumbers(serialNumber string, allowedSerialNumbers []string, patterns patternSet) error {
+	if serialNumber == "" {
+		return nil
 	}
-	return nil
+	return validateSubjectValue("allowedSerialNumbers", serialNumber, allowedSerialNumbers, patterns)
+}
+
+// validateSubjectValue validates that a value of the field is either one of the allowed values, or matches one
+// of the allowed patterns. The error names the pattern set which rejected the value, if there is one.
+func validateSubjectValue(field, value string, allowedValues []string, patterns patternSet) error {
+	if containsString(value, allowedValues) || patterns.matches(value) {
+		return nil
+	}
+
+	if !patterns.isSet() {
+		return fmt.Errorf(errAllowedValuesStringMsg, field, allowedValues)
+	}
+
+	return fmt.Errorf(errPatternsNotMatchedMsg, value, field, allowedValues, patterns.syntax, patterns.name, patterns.sources)
 }
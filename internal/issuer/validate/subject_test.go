@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"testing"
 
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
 	"github.com/stretchr/testify/assert"
 )
 
+const testOrganizationalUnitPatterns = ".spec.certificateRestrictions.subjectRestrictions.allowedPatterns.organizationalUnits"
+
 func TestValidateOrganizations(t *testing.T) {
 	type params struct {
 		organizations        []string
@@ -43,7 +46,7 @@ func TestValidateOrganizations(t *testing.T) {
 
 	for name, test := range cases {
 		t.Run(name, func(t *testing.T) {
-			err := validateOrganizations(test.params.organizations, test.params.allowedOrganizations)
+			err := validateOrganizations(test.params.organizations, test.params.allowedOrganizations, patternSet{})
 			if err != nil {
 				assert.Equal(t, test.want.errMsg, err.Error())
 			} else {
@@ -89,7 +92,7 @@ func TestValidateCountries(t *testing.T) {
 
 	for name, test := range cases {
 		t.Run(name, func(t *testing.T) {
-			err := validateCountries(test.params.countries, test.params.allowedCountries)
+			err := validateCountries(test.params.countries, test.params.allowedCountries, patternSet{})
 			if err != nil {
 				assert.Equal(t, test.want.errMsg, err.Error())
 			} else {
@@ -103,6 +106,7 @@ func TestValidateOrganizationalUnits(t *testing.T) {
 	type params struct {
 		units        []string
 		allowedUnits []string
+		patterns     []string
 	}
 
 	type want struct {
@@ -131,11 +135,35 @@ func TestValidateOrganizationalUnits(t *testing.T) {
 				errMsg: fmt.Sprintf(errAllowedValuesStringMsg, ".spec.subject.organizationalUnits", []string{"ValidUnit"}),
 			},
 		},
+		"ShouldSucceedWithOrganizationalUnitMatchingPattern": {
+			params: params{
+				units:        []string{"ValidUnit", "team-payments"},
+				allowedUnits: []string{"ValidUnit"},
+				patterns:     []string{"team-*"},
+			},
+			want: want{
+				errMsg: "",
+			},
+		},
+		"ShouldFailWithOrganizationalUnitNotMatchingPattern": {
+			params: params{
+				units:        []string{"ops-payments"},
+				allowedUnits: []string{"ValidUnit"},
+				patterns:     []string{"team-*"},
+			},
+			want: want{
+				errMsg: fmt.Sprintf(errPatternsNotMatchedMsg, "ops-payments", ".spec.subject.organizationalUnits", []string{"ValidUnit"},
+					certv1alpha1.PatternSyntaxGlob, testOrganizationalUnitPatterns, []string{"team-*"}),
+			},
+		},
 	}
 
 	for name, test := range cases {
 		t.Run(name, func(t *testing.T) {
-			err := validateOrganizationalUnits(test.params.units, test.params.allowedUnits)
+			patterns, err := compilePatternSet(testOrganizationalUnitPatterns, certv1alpha1.PatternSyntaxGlob, test.params.patterns)
+			assert.NoError(t, err)
+
+			err = validateOrganizationalUnits(test.params.units, test.params.allowedUnits, patterns)
 			if err != nil {
 				assert.Equal(t, test.want.errMsg, err.Error())
 			} else {
@@ -181,7 +209,7 @@ func TestValidateLocalities(t *testing.T) {
 
 	for name, test := range cases {
 		t.Run(name, func(t *testing.T) {
-			err := validateLocalities(test.params.localities, test.params.allowedLocalities)
+			err := validateLocalities(test.params.localities, test.params.allowedLocalities, patternSet{})
 			if err != nil {
 				assert.Equal(t, test.want.errMsg, err.Error())
 			} else {
@@ -227,7 +255,7 @@ func TestValidateProvinces(t *testing.T) {
 
 	for name, test := range cases {
 		t.Run(name, func(t *testing.T) {
-			err := validateProvinces(test.params.provinces, test.params.allowedProvinces)
+			err := validateProvinces(test.params.provinces, test.params.allowedProvinces, patternSet{})
 			if err != nil {
 				assert.Equal(t, test.want.errMsg, err.Error())
 			} else {
@@ -273,7 +301,7 @@ func TestValidateStreetAddresses(t *testing.T) {
 
 	for name, test := range cases {
 		t.Run(name, func(t *testing.T) {
-			err := validateStreetAddresses(test.params.addresses, test.params.allowedAddresses)
+			err := validateStreetAddresses(test.params.addresses, test.params.allowedAddresses, patternSet{})
 			if err != nil {
 				assert.Equal(t, test.want.errMsg, err.Error())
 			} else {
@@ -319,7 +347,7 @@ func TestValidatePostalCodes(t *testing.T) {
 
 	for name, test := range cases {
 		t.Run(name, func(t *testing.T) {
-			err := validatePostalCodes(test.params.codes, test.params.allowedCodes)
+			err := validatePostalCodes(test.params.codes, test.params.allowedCodes, patternSet{})
 			if err != nil {
 				assert.Equal(t, test.want.errMsg, err.Error())
 			} else {
@@ -24,7 +24,29 @@ func containsInt(d int, slice []int) bool {
 	return false
 }
 
-// hasSuffix checks if the given string s ends with the specified suffix.
-func hasSuffix(s, suffix string) bool {
-	return strings.HasSuffix(s, suffix)
+// containsFold checks if a string is present in a slice of strings, ignoring case.
+func containsFold(s string, slice []string) bool {
+	return slices.ContainsFunc(slice, func(str string) bool {
+		return strings.EqualFold(str, s)
+	})
+}
+
+// equalFold checks if two slices of strings hold the same values in any order, ignoring case.
+func equalFold(a, b []string) bool {
+	lower := func(values []string) []string {
+		lowered := make([]string, 0, len(values))
+		for _, value := range values {
+			lowered = append(lowered, strings.ToLower(value))
+		}
+		return sorted(lowered)
+	}
+
+	return slices.Equal(slices.Compact(lower(a)), slices.Compact(lower(b)))
+}
+
+// sorted returns a sorted copy of a slice of strings.
+func sorted(values []string) []string {
+	sortedValues := slices.Clone(values)
+	slices.Sort(sortedValues)
+	return sortedValues
 }
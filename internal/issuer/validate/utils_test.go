@@ -89,45 +89,3 @@ func TestContainsInt(t *testing.T) {
 		})
 	}
 }
-
-func TestHasSuffix(t *testing.T) {
-	type params struct {
-		s      string
-		suffix string
-	}
-
-	type want struct {
-		result bool
-	}
-
-	cases := map[string]struct {
-		params params
-		want   want
-	}{
-		"ShouldHaveSuffix": {
-			params: params{
-				s:      "example.txt",
-				suffix: ".txt",
-			},
-			want: want{
-				result: true,
-			},
-		},
-		"ShouldNotHaveSuffix": {
-			params: params{
-				s:      "example.txt",
-				suffix: ".jpg",
-			},
-			want: want{
-				result: false,
-			},
-		},
-	}
-
-	for name, test := range cases {
-		t.Run(name, func(t *testing.T) {
-			match := hasSuffix(test.params.s, test.params.suffix)
-			assert.Equal(t, test.want.result, match)
-		})
-	}
-}
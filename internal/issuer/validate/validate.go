@@ -13,6 +13,7 @@ const (
 	errAllowedValuesStringMsg = "the only allowed values for %q in the Certificate are %q"
 	errAllowedValuesIntMsg    = "the only allowed values for %q in the Certificate are %d"
 	errNotAllowedMsg          = "%s is not allowed to be set in the Certificate"
+	errPatternsNotMatchedMsg  = "the value %q of %q in the Certificate is not one of the allowed values %q and does not match any of the %s patterns in %s %q"
 )
 
 var (
@@ -20,8 +21,9 @@ var (
 	extUsageOID = asn1.ObjectIdentifier{2, 5, 29, 37}
 )
 
-// EnsureCSR makes sures that the CSR complies with the restrictions of the Cert API.
-func EnsureCSR(csr *x509.CertificateRequest, restrictions certv1alpha1.Restrictions) error {
+// EnsureCSR makes sures that the CSR complies with the restrictions of the Cert API. The subject patterns
+// are the compiled allowed patterns of the subject restrictions; nil allows no patterns.
+func EnsureCSR(csr *x509.CertificateRequest, restrictions certv1alpha1.Restrictions, subjectPatterns *SubjectPatterns) error {
 	if err := validateKey(csr, restrictions.PrivateKeyRestrictions); err != nil {
 		return fmt.Errorf(errValidationFailedMsg, "key", err)
 	}
@@ -30,7 +32,7 @@ func EnsureCSR(csr *x509.CertificateRequest, restrictions certv1alpha1.Restricti
 		return fmt.Errorf(errValidationFailedMsg, "subjectAltName", err)
 	}
 
-	if err := validateSubject(csr, restrictions.SubjectRestrictions); err != nil {
+	if err := validateSubject(csr, restrictions.SubjectRestrictions, subjectPatterns); err != nil {
 		return fmt.Errorf(errValidationFailedMsg, "subject", err)
 	}
 
@@ -85,52 +87,57 @@ func validateSubjectAltName(csr *x509.CertificateRequest, subjectAltNamesRestric
 	return nil
 }
 
-// validateSubject validates the subject of the given certificate request against the subject restrictions.
-func validateSubject(csr *x509.CertificateRequest, subjectRestrictions certv1alpha1.SubjectRestrictions) error {
-	if len(subjectRestrictions.AllowedOrganizations) > 0 {
-		if err := validateOrganizations(csr.Subject.Organization, subjectRestrictions.AllowedOrganizations); err != nil {
+// validateSubject validates the subject of the given certificate request against the subject restrictions
+// and their compiled patterns.
+func validateSubject(csr *x509.CertificateRequest, subjectRestrictions certv1alpha1.SubjectRestrictions, subjectPatterns *SubjectPatterns) error {
+	if subjectPatterns == nil {
+		subjectPatterns = &SubjectPatterns{}
+	}
+
+	if len(subjectRestrictions.AllowedOrganizations) > 0 || subjectPatterns.organizations.isSet() {
+		if err := validateOrganizations(csr.Subject.Organization, subjectRestrictions.AllowedOrganizations, subjectPatterns.organizations); err != nil {
 			return fmt.Errorf(errValidationFailedMsg, "organization", err)
 		}
 	}
 
-	if len(subjectRestrictions.AllowedCountries) > 0 {
-		if err := validateCountries(csr.Subject.Country, subjectRestrictions.AllowedCountries); err != nil {
+	if len(subjectRestrictions.AllowedCountries) > 0 || subjectPatterns.countries.isSet() {
+		if err := validateCountries(csr.Subject.Country, subjectRestrictions.AllowedCountries, subjectPatterns.countries); err != nil {
 			return fmt.Errorf(errValidationFailedMsg, "country", err)
 		}
 	}
 
-	if len(subjectRestrictions.AllowedOrganizationalUnits) > 0 {
-		if err := validateOrganizationalUnits(csr.Subject.OrganizationalUnit, subjectRestrictions.AllowedOrganizationalUnits); err != nil {
+	if len(subjectRestrictions.AllowedOrganizationalUnits) > 0 || subjectPatterns.organizationalUnits.isSet() {
+		if err := validateOrganizationalUnits(csr.Subject.OrganizationalUnit, subjectRestrictions.AllowedOrganizationalUnits, subjectPatterns.organizationalUnits); err != nil {
 			return fmt.Errorf(errValidationFailedMsg, "organizational unit", err)
 		}
 	}
 
-	if len(subjectRestrictions.AllowedLocalities) > 0 {
-		if err := validateLocalities(csr.Subject.Locality, subjectRestrictions.AllowedLocalities); err != nil {
+	if len(subjectRestrictions.AllowedLocalities) > 0 || subjectPatterns.localities.isSet() {
+		if err := validateLocalities(csr.Subject.Locality, subjectRestrictions.AllowedLocalities, subjectPatterns.localities); err != nil {
 			return fmt.Errorf(errValidationFailedMsg, "locality", err)
 		}
 	}
 
-	if len(subjectRestrictions.AllowedProvinces) > 0 {
-		if err := validateProvinces(csr.Subject.Province, subjectRestrictions.AllowedProvinces); err != nil {
+	if len(subjectRestrictions.AllowedProvinces) > 0 || subjectPatterns.provinces.isSet() {
+		if err := validateProvinces(csr.Subject.Province, subjectRestrictions.AllowedProvinces, subjectPatterns.provinces); err != nil {
 			return fmt.Errorf(errValidationFailedMsg, "province", err)
 		}
 	}
 
-	if len(subjectRestrictions.AllowedStreetAddresses) > 0 {
-		if err := validateStreetAddresses(csr.Subject.StreetAddress, subjectRestrictions.AllowedStreetAddresses); err != nil {
+	if len(subjectRestrictions.AllowedStreetAddresses) > 0 || subjectPatterns.streetAddresses.isSet() {
+		if err := validateStreetAddresses(csr.Subject.StreetAddress, subjectRestrictions.AllowedStreetAddresses, subjectPatterns.streetAddresses); err != nil {
 			return fmt.Errorf(errValidationFailedMsg, "street address", err)
 		}
 	}
 
-	if len(subjectRestrictions.AllowedPostalCodes) > 0 {
-		if err := validatePostalCodes(csr.Subject.PostalCode, subjectRestrictions.AllowedPostalCodes); err != nil {
+	if len(subjectRestrictions.AllowedPostalCodes) > 0 || subjectPatterns.postalCodes.isSet() {
+		if err := validatePostalCodes(csr.Subject.PostalCode, subjectRestrictions.AllowedPostalCodes, subjectPatterns.postalCodes); err != nil {
 			return fmt.Errorf(errValidationFailedMsg, "postal code", err)
 		}
 	}
 
-	if len(subjectRestrictions.AllowedSerialNumbers) > 0 {
-		if err := validateSerialNumbers(csr.Subject.SerialNumber, subjectRestrictions.AllowedSerialNumbers); err != nil {
+	if len(subjectRestrictions.AllowedSerialNumbers) > 0 || subjectPatterns.serialNumbers.isSet() {
+		if err := validateSerialNumbers(csr.Subject.SerialNumber, subjectRestrictions.AllowedSerialNumbers, subjectPatterns.serialNumbers); err != nil {
 			return fmt.Errorf(errValidationFailedMsg, "serial number", err)
 		}
 	}
@@ -161,14 +168,18 @@ func validateUsages(csr *x509.CertificateRequest, usageRestrictions certv1alpha1
 	return nil
 }
 
-// validateSubject validates the domain of the given certificate request against the domain restrictions.
+// validateDomain validates the common name and the DNS names of the given certificate request against the domain restrictions.
 func validateDomain(csr *x509.CertificateRequest, domainRestrictions certv1alpha1.DomainRestrictions) error {
-	if err := validateNameDomain([]string{csr.Subject.CommonName}, domainRestrictions.AllowedDomains, domainRestrictions.AllowedSubdomains); err != nil {
-		return fmt.Errorf(errValidationFailedMsg, "commonName", err)
+	if csr.Subject.CommonName != "" {
+		if err := validateNameDomain(".spec.commonName", csr.Subject.CommonName, domainRestrictions); err != nil {
+			return fmt.Errorf(errValidationFailedMsg, "commonName", err)
+		}
 	}
 
-	if err := validateNameDomain(csr.DNSNames, domainRestrictions.AllowedDomains, domainRestrictions.AllowedSubdomains); err != nil {
-		return fmt.Errorf(errValidationFailedMsg, "dnsNames", err)
+	for i, dnsName := range csr.DNSNames {
+		if err := validateNameDomain(fmt.Sprintf(".spec.dnsNames[%d]", i), dnsName, domainRestrictions); err != nil {
+			return fmt.Errorf(errValidationFailedMsg, "dnsNames", err)
+		}
 	}
 
 	return nil
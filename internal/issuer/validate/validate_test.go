@@ -65,7 +65,7 @@ func TestEnsureCSR(t *testing.T) {
 			csr.DNSNames = tc.params.altNames
 			csr.Subject = tc.params.subject
 			assert.NoError(t, err)
-			err = EnsureCSR(csr, tc.params.restrictions)
+			err = EnsureCSR(csr, tc.params.restrictions, nil)
 			if err != nil || tc.want.result != "" {
 				assert.EqualError(t, err, tc.want.result)
 			}
@@ -287,7 +287,7 @@ func TestValidateSubject(t *testing.T) {
 					SerialNumber:       tc.params.serialNumber,
 				},
 			}
-			err := validateSubject(csr, tc.params.restrictions)
+			err := validateSubject(csr, tc.params.restrictions, nil)
 			if err != nil || tc.want.errorMsg != "" {
 				assert.EqualError(t, err, tc.want.errorMsg)
 			}
@@ -398,7 +398,7 @@ func TestValidateDomain(t *testing.T) {
 				},
 			},
 			want: want{
-				errorMsg: fmt.Sprintf(errValidationFailedMsg, "commonName", fmt.Sprintf(errAllowedValuesStringMsg, ".spec.commonName domain", []string{allowed})),
+				errorMsg: fmt.Sprintf(errValidationFailedMsg, "commonName", fmt.Sprintf(errNameNotAllowedMsg, ".spec.commonName", testName+"."+notAllowed, "domains", []string{allowed})),
 			},
 		},
 		"ShouldPassWithValidDomain": {
@@ -422,7 +422,19 @@ func TestValidateDomain(t *testing.T) {
 				},
 			},
 			want: want{
-				errorMsg: fmt.Sprintf(errValidationFailedMsg, "dnsNames", fmt.Sprintf(errAllowedValuesStringMsg, ".spec.commonName domain", []string{allowed})),
+				errorMsg: fmt.Sprintf(errValidationFailedMsg, "dnsNames", fmt.Sprintf(errNameNotAllowedMsg, ".spec.dnsNames[0]", testName+"."+notAllowed, "domains", []string{allowed})),
+			},
+		},
+		"ShouldFailWithInvalidDomainInSecondDnsName": {
+			params: params{
+				dnsNames:   []string{testName + "." + allowed, testName + "." + notAllowed},
+				commonName: testName + "." + allowed,
+				restrictions: certv1alpha1.DomainRestrictions{
+					AllowedDomains: []string{allowed},
+				},
+			},
+			want: want{
+				errorMsg: fmt.Sprintf(errValidationFailedMsg, "dnsNames", fmt.Sprintf(errNameNotAllowedMsg, ".spec.dnsNames[1]", testName+"."+notAllowed, "domains", []string{allowed})),
 			},
 		},
 		"ShouldPassWithValidDomainInDnsNames": {
@@ -0,0 +1,73 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	cmpki "github.com/cert-manager/cert-manager/pkg/util/pki"
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+)
+
+var (
+	errDurationTooShort    = errors.New("the requested duration is shorter than the minimum duration allowed by the Issuer")
+	errDurationTooLong     = errors.New("the requested duration is longer than the maximum duration allowed by the Issuer")
+	errNonPositiveDuration = errors.New("the requested duration must be positive")
+	errNotAfterMismatch    = errors.New("notAfter of the Certificate does not match the requested duration")
+)
+
+// EnsureDuration makes sure that the duration requested for the Certificate complies with the validity
+// restrictions. A zero duration, which leaves the duration to the signer backend, is not restricted.
+func EnsureDuration(duration time.Duration, validityRestrictions certv1alpha1.ValidityRestrictions) error {
+	if err := validateDuration(duration, validityRestrictions); err != nil {
+		return fmt.Errorf(errValidationFailedMsg, "validity", err)
+	}
+
+	return nil
+}
+
+// validateDuration validates the requested duration against the minimum and maximum durations.
+func validateDuration(duration time.Duration, validityRestrictions certv1alpha1.ValidityRestrictions) error {
+	if duration == 0 {
+		return nil
+	}
+
+	if duration < 0 {
+		return fmt.Errorf("%w: %s", errNonPositiveDuration, duration)
+	}
+
+	if minDuration := validityRestrictions.MinDuration; minDuration != nil && duration < minDuration.Duration {
+		return fmt.Errorf("%w: %s < %s", errDurationTooShort, duration, minDuration.Duration)
+	}
+
+	if maxDuration := validityRestrictions.MaxDuration; maxDuration != nil && duration > maxDuration.Duration {
+		return fmt.Errorf("%w: %s > %s", errDurationTooLong, duration, maxDuration.Duration)
+	}
+
+	return nil
+}
+
+// EnsureNotAfter makes sure that the Certificate expires once the requested duration has passed since it was
+// issued. The Certificate is issued between requestedAt, when it was requested, and now, when it was received,
+// so that its NotAfter must fall in that window shifted by the duration, allowing for clock skew.
+func EnsureNotAfter(chainPEM []byte, duration time.Duration, requestedAt, now time.Time) error {
+	chain, err := cmpki.DecodeX509CertificateChainBytes(chainPEM)
+	if err != nil {
+		return fmt.Errorf(errValidationFailedMsg, "chain", err)
+	}
+	if len(chain) == 0 {
+		return fmt.Errorf(errValidationFailedMsg, "chain", errEmptyCertificateChain)
+	}
+
+	earliest := requestedAt.Add(duration - certificateClockSkew)
+	latest := now.Add(duration + certificateClockSkew)
+
+	notAfter := chain[0].NotAfter
+	if notAfter.Before(earliest) || notAfter.After(latest) {
+		err := fmt.Errorf("%w %s: expected between %s and %s, got %s", errNotAfterMismatch, duration,
+			earliest.UTC().Format(time.RFC3339), latest.UTC().Format(time.RFC3339), notAfter.UTC().Format(time.RFC3339))
+		return fmt.Errorf(errValidationFailedMsg, "validity", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package validate
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	cmpki "github.com/cert-manager/cert-manager/pkg/util/pki"
+	certv1alpha1 "github.com/dana-team/cert-external-issuer/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEnsureDuration(t *testing.T) {
+	validityRestrictions := certv1alpha1.ValidityRestrictions{
+		MinDuration: &metav1.Duration{Duration: time.Hour},
+		MaxDuration: &metav1.Duration{Duration: 24 * time.Hour},
+	}
+
+	type params struct {
+		duration             time.Duration
+		validityRestrictions certv1alpha1.ValidityRestrictions
+	}
+
+	type want struct {
+		errMsg string
+	}
+
+	cases := map[string]struct {
+		params params
+		want   want
+	}{
+		"ShouldSucceedWithDurationWithinRestrictions": {
+			params: params{duration: 2 * time.Hour, validityRestrictions: validityRestrictions},
+		},
+		"ShouldSucceedWithoutRequestedDuration": {
+			params: params{validityRestrictions: validityRestrictions},
+		},
+		"ShouldSucceedWithoutRestrictions": {
+			params: params{duration: 365 * 24 * time.Hour},
+		},
+		"ShouldFailWithDurationShorterThanMinDuration": {
+			params: params{duration: time.Minute, validityRestrictions: validityRestrictions},
+			want: want{
+				errMsg: "validity validation failed: the requested duration is shorter than the minimum duration allowed by the Issuer: 1m0s < 1h0m0s",
+			},
+		},
+		"ShouldFailWithDurationLongerThanMaxDuration": {
+			params: params{duration: 48 * time.Hour, validityRestrictions: validityRestrictions},
+			want: want{
+				errMsg: "validity validation failed: the requested duration is longer than the maximum duration allowed by the Issuer: 48h0m0s > 24h0m0s",
+			},
+		},
+		"ShouldFailWithNegativeDuration": {
+			params: params{duration: -time.Hour},
+			want: want{
+				errMsg: "validity validation failed: the requested duration must be positive: -1h0m0s",
+			},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := EnsureDuration(test.params.duration, test.params.validityRestrictions)
+			if test.want.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.want.errMsg)
+			}
+		})
+	}
+}
+
+func TestEnsureNotAfter(t *testing.T) {
+	ca, caKey := generateTestCA(t)
+	caCert, err := cmpki.DecodeX509CertificateBytes(ca)
+	assert.NoError(t, err)
+
+	key, err := cmpki.GenerateECPrivateKey(cmpki.ECCurve256)
+	assert.NoError(t, err)
+
+	type params struct {
+		notBefore   time.Time
+		notAfter    time.Time
+		requestedAt time.Time
+		now         time.Time
+	}
+
+	type want struct {
+		errMsg string
+	}
+
+	cases := map[string]struct {
+		params params
+		want   want
+	}{
+		"ShouldSucceedWithRequestedDuration": {
+			params: params{notBefore: testNow, notAfter: testNow.Add(time.Hour), requestedAt: testNow, now: testNow},
+		},
+		"ShouldSucceedWhenIssuedWhileRequestWasPending": {
+			params: params{notBefore: testNow, notAfter: testNow.Add(time.Hour), requestedAt: testNow.Add(-time.Hour), now: testNow.Add(time.Hour)},
+		},
+		"ShouldSucceedWithBackdatedCertificate": {
+			params: params{notBefore: testNow.Add(-time.Hour), notAfter: testNow.Add(time.Hour), requestedAt: testNow, now: testNow},
+		},
+		"ShouldSucceedWithinClockSkew": {
+			params: params{notBefore: testNow, notAfter: testNow.Add(time.Hour + time.Minute), requestedAt: testNow, now: testNow},
+		},
+		"ShouldFailWithShorterDuration": {
+			params: params{notBefore: testNow, notAfter: testNow.Add(30 * time.Minute), requestedAt: testNow, now: testNow},
+			want: want{
+				errMsg: "validity validation failed: notAfter of the Certificate does not match the requested duration 1h0m0s: expected between 2024-01-01T00:55:00Z and 2024-01-01T01:05:00Z, got 2024-01-01T00:30:00Z",
+			},
+		},
+		"ShouldFailWithLongerDuration": {
+			params: params{notBefore: testNow, notAfter: testNow.Add(24 * time.Hour), requestedAt: testNow, now: testNow},
+			want: want{
+				errMsg: "validity validation failed: notAfter of the Certificate does not match the requested duration 1h0m0s: expected between 2024-01-01T00:55:00Z and 2024-01-01T01:05:00Z, got 2024-01-02T00:00:00Z",
+			},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			template := &x509.Certificate{
+				SerialNumber: big.NewInt(2),
+				Subject:      pkix.Name{CommonName: testCommonName},
+				NotBefore:    test.params.notBefore,
+				NotAfter:     test.params.notAfter,
+			}
+
+			certificatePEM, _, err := cmpki.SignCertificate(template, caCert, key.Public(), caKey)
+			assert.NoError(t, err)
+
+			err = EnsureNotAfter(certificatePEM, time.Hour, test.params.requestedAt, test.params.now)
+			if test.want.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.want.errMsg)
+			}
+		})
+	}
+}
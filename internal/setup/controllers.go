@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/dana-team/cert-external-issuer/internal/certificate"
 	"github.com/dana-team/cert-external-issuer/internal/certificaterequest"
 	"github.com/dana-team/cert-external-issuer/internal/issuer"
 	"k8s.io/utils/clock"
@@ -18,18 +19,23 @@ const inClusterNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/na
 var errNotInCluster = errors.New("not running in-cluster")
 
 // Controllers sets up the different controllers with the manager.
-func Controllers(mgr manager.Manager, clusterResourceNamespace string, disableApprovedCheck bool) error {
+// The controllers build Signers and HealthCheckers using the backends of the given registry,
+// and share a cache of them so that they are reused until their issuer changes.
+func Controllers(mgr manager.Manager, clusterResourceNamespace, clusterID string, disableApprovedCheck bool, registry *signer.Registry) error {
 	namespace, err := setClusterResourceNamespace(clusterResourceNamespace)
 	if err != nil {
 		return fmt.Errorf("failed to set cluster resource namespace: %v", err)
 	}
 
+	signerCache := signer.NewCache()
+
 	if err := (&issuer.IssuerReconciler{
 		Kind:                     "Issuer",
 		Client:                   mgr.GetClient(),
 		Scheme:                   mgr.GetScheme(),
 		ClusterResourceNamespace: namespace,
-		HealthCheckerBuilder:     signer.CertSignerHealthCheckerFromIssuerAndSecretData,
+		HealthCheckerBuilder:     registry.BuildHealthChecker,
+		SignerCache:              signerCache,
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to create Issuer controller")
 	}
@@ -39,7 +45,8 @@ func Controllers(mgr manager.Manager, clusterResourceNamespace string, disableAp
 		Client:                   mgr.GetClient(),
 		Scheme:                   mgr.GetScheme(),
 		ClusterResourceNamespace: namespace,
-		HealthCheckerBuilder:     signer.CertSignerHealthCheckerFromIssuerAndSecretData,
+		HealthCheckerBuilder:     registry.BuildHealthChecker,
+		SignerCache:              signerCache,
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to create ClusterIssuer controller")
 	}
@@ -48,13 +55,25 @@ func Controllers(mgr manager.Manager, clusterResourceNamespace string, disableAp
 		Client:                   mgr.GetClient(),
 		Scheme:                   mgr.GetScheme(),
 		ClusterResourceNamespace: namespace,
-		SignerBuilder:            signer.CertSignerFromIssuerAndSecretData,
+		SignerBuilder:            registry.BuildSigner,
+		SignerCache:              signerCache,
 		CheckApprovedCondition:   !disableApprovedCheck,
+		ClusterID:                clusterID,
 		Clock:                    clock.RealClock{},
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to create CertificateRequest controller")
 	}
 
+	if err := (&certificate.CertificateReconciler{
+		Client:                   mgr.GetClient(),
+		Scheme:                   mgr.GetScheme(),
+		ClusterResourceNamespace: namespace,
+		SignerBuilder:            registry.BuildSigner,
+		SignerCache:              signerCache,
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create Certificate controller")
+	}
+
 	return nil
 }
 